@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Actor is the authenticated user performing a request.
+type Actor struct {
+	UUID  uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+}
+
+type actorKey struct{}
+
+// NewActorContext returns a copy of ctx carrying actor.
+func NewActorContext(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, if any.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the album revisions.
+const (
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+	RevisionReverted = "reverted"
+)
+
+// AlbumRevision is a snapshot of an album taken by a write. The
+// revision number is the version of the album after the write.
+// Changes holds the fields changed since the previous revision.
+type AlbumRevision struct {
+	AlbumUUID  uuid.UUID     `json:"album_id"`
+	Revision   int           `json:"revision"`
+	Action     string        `json:"action"`
+	Snapshot   Albums        `json:"snapshot"`
+	ActorUUID  *uuid.UUID    `json:"actor_id,omitempty"`
+	ActorEmail string        `json:"actor_email,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+// FieldChange is the change of a field between two revisions.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Diff returns the changes of the user editable fields and of the
+// deletion time from a to b, in the order of the fields.
+func (a *Albums) Diff(b *Albums) []FieldChange {
+	changes := []FieldChange{}
+
+	for _, f := range []struct {
+		field    string
+		from, to interface{}
+		equal    bool
+	}{
+		{"name", a.Name, b.Name, a.Name == b.Name},
+		{"length", a.Length, b.Length, a.Length == b.Length},
+		{"barcode", a.Barcode, b.Barcode, a.Barcode == b.Barcode},
+		{"deleted_at", a.DeletedAt, b.DeletedAt, sameTime(a.DeletedAt, b.DeletedAt)},
+	} {
+		if !f.equal {
+			changes = append(changes, FieldChange{Field: f.field, From: f.from, To: f.to})
+		}
+	}
+
+	return changes
+}
+
+// sameTime reports whether both times are unset or are the same instant.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
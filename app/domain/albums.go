@@ -7,26 +7,122 @@ import (
 	"github.com/google/uuid"
 )
 
+// Bounds of the album length, in minutes.
+const (
+	AlbumMinLength = 1
+	AlbumMaxLength = 600
+)
+
+// Albums is a catalogue album. Version is increased by every write,
+// the updates and deletes are conditioned on it unless it is zero.
+// The deleted albums stay in the trash, with DeletedAt set, until
+// they are restored or purged.
 type Albums struct {
-	UUID      uuid.UUID `db:"uuid" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Length    int       `db:"length" json:"length"`
-	CreatedAt time.Time `db:"created_at" json:"created_at" `
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at" `
+	UUID      uuid.UUID  `db:"uuid" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	Length    int        `db:"length" json:"length"`
+	Barcode   string     `db:"barcode" json:"barcode,omitempty"`
+	Version   int        `db:"version" json:"version"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at" `
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at" `
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// AlbumsPatch is a partial update of an album,
+// only the fields that are not nil are changed.
+type AlbumsPatch struct {
+	Name      *string
+	Length    *int
+	Barcode   *string
+	UpdatedAt time.Time
+	Version   int
+}
+
+// Empty reports whether the patch changes no field.
+func (p *AlbumsPatch) Empty() bool {
+	return p.Name == nil && p.Length == nil && p.Barcode == nil
+}
+
+// Operations of a bulk write.
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// AlbumsBulkOperation is an operation of a bulk write. Album holds the
+// UUID, the fields and the expected version, which is set to the new
+// one once written. Err is the failure of the operation, if any.
+type AlbumsBulkOperation struct {
+	Op    string
+	Album Albums
+	Err   error
+}
+
+// Outcomes of an imported row.
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+)
+
+// AlbumsImportRow is a row of an import, Line being its position in
+// the file. The album is matched by its UUID when set, otherwise by
+// its barcode, and created when there is no match. The version of the
+// album, when set, conditions the update of the matched album, which
+// is refused without it when RequireVersion is set. Outcome and Err
+// are set by the import.
+type AlbumsImportRow struct {
+	Line           int
+	Album          Albums
+	RequireVersion bool
+	Outcome        string
+	Err            error
 }
 
+// AlbumsRepository stores the albums. Update and Patch take the expected
+// version from the album or the patch and set it to the new one, a
+// version mismatch is reported as ErrPreconditionFailed. Every write
+// records a revision of the album in the same transaction, and
+// RestoreRevision writes back the fields of a revision, returning the
+// new version. FindByID locks the album until the end of the
+// transaction when it is called in one. Bulk writes the operations in one transaction, an
+// atomic bulk is rolled back with ErrAlbumsBulkAborted as soon as an
+// operation fails, otherwise only the failed operations are undone.
+// Export calls fn for every album, streaming them from the database.
+// Import upserts the rows in one transaction, rolled back on a dry
+// run, setting the outcome of each row.
 type AlbumsRepository interface {
 	FindAll(context.Context) ([]*Albums, error)
 	FindByID(context.Context, uuid.UUID) (*Albums, error)
+	FindByIDs(context.Context, []uuid.UUID) ([]*Albums, error)
 	Add(context.Context, *Albums) error
 	Update(context.Context, uuid.UUID, *Albums) error
-	Delete(context.Context, uuid.UUID) error
+	Patch(context.Context, uuid.UUID, *AlbumsPatch) error
+	Delete(context.Context, uuid.UUID, int) error
+	FindTrash(context.Context) ([]*Albums, error)
+	Restore(context.Context, uuid.UUID) error
+	Purge(context.Context, time.Time) (int64, error)
+	FindRevisions(context.Context, uuid.UUID) ([]*AlbumRevision, error)
+	RestoreRevision(context.Context, uuid.UUID, int, int) (int, error)
+	Bulk(context.Context, []*AlbumsBulkOperation, bool) error
+	Export(context.Context, func(*Albums) error) error
+	Import(context.Context, []*AlbumsImportRow, bool) error
 }
 
 type AlbumsUseCase interface {
 	FindAll(ctx context.Context) ([]*Albums, error)
 	FindByID(ctx context.Context, uuid uuid.UUID) (*Albums, error)
+	FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]*Albums, error)
 	Add(ctx context.Context, album *Albums) error
 	Update(ctx context.Context, uuid uuid.UUID, album *Albums) error
-	Delete(ctx context.Context, uuid uuid.UUID) error
+	Patch(ctx context.Context, uuid uuid.UUID, patch *AlbumsPatch) error
+	Delete(ctx context.Context, uuid uuid.UUID, version int) error
+	FindTrash(ctx context.Context) ([]*Albums, error)
+	Restore(ctx context.Context, uuid uuid.UUID) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	FindRevisions(ctx context.Context, uuid uuid.UUID) ([]*AlbumRevision, error)
+	RestoreRevision(ctx context.Context, uuid uuid.UUID, revision int, version int) (int, error)
+	Bulk(ctx context.Context, operations []*AlbumsBulkOperation, atomic bool) error
+	Export(ctx context.Context, fn func(*Albums) error) error
+	Import(ctx context.Context, rows []*AlbumsImportRow, dryRun bool) error
 }
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditCreate          = "create"
+	AuditUpdate          = "update"
+	AuditPatch           = "patch"
+	AuditDelete          = "delete"
+	AuditRestore         = "restore"
+	AuditRestoreRevision = "restore_revision"
+	AuditPurge           = "purge"
+	AuditLogin           = "login"
+)
+
+// Outcomes recorded in the audit log.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	AuditDenied  = "denied"
+)
+
+// AuditEntry is a record of the audit log. The entries are chained:
+// PrevHash is the hash of the previous entry and Hash covers the
+// entry along with PrevHash, so that a changed, inserted or removed
+// entry breaks the chain.
+type AuditEntry struct {
+	ID           int64         `json:"id"`
+	ActorUUID    *uuid.UUID    `json:"actor_id,omitempty"`
+	ActorEmail   string        `json:"actor_email,omitempty"`
+	Action       string        `json:"action"`
+	Outcome      string        `json:"outcome"`
+	ResourceType string        `json:"resource_type"`
+	ResourceID   string        `json:"resource_id,omitempty"`
+	IP           string        `json:"ip,omitempty"`
+	RequestID    string        `json:"request_id,omitempty"`
+	Changes      []FieldChange `json:"changes,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	PrevHash     string        `json:"prev_hash"`
+	Hash         string        `json:"hash"`
+}
+
+// ComputeHash returns the SHA-256 of PrevHash and of the recorded
+// fields, hex encoded. The ID is left out, it is assigned on insert.
+func (e *AuditEntry) ComputeHash() string {
+	payload, _ := json.Marshal(struct {
+		ActorUUID    *uuid.UUID    `json:"actor_id"`
+		ActorEmail   string        `json:"actor_email"`
+		Action       string        `json:"action"`
+		Outcome      string        `json:"outcome"`
+		ResourceType string        `json:"resource_type"`
+		ResourceID   string        `json:"resource_id"`
+		IP           string        `json:"ip"`
+		RequestID    string        `json:"request_id"`
+		Changes      []FieldChange `json:"changes"`
+		CreatedAt    string        `json:"created_at"`
+	}{
+		e.ActorUUID,
+		e.ActorEmail,
+		e.Action,
+		e.Outcome,
+		e.ResourceType,
+		e.ResourceID,
+		e.IP,
+		e.RequestID,
+		e.Changes,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(append([]byte(e.PrevHash+"\n"), payload...))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter selects the audit entries, the zero values match any.
+// The entries are listed newest first, Before being the ID the page
+// starts after.
+type AuditFilter struct {
+	ActorUUID    *uuid.UUID
+	ActorEmail   string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+	Before       int64
+	Limit        int
+}
+
+// AuditVerification is the result of a check of the audit log chain.
+// BrokenAt is the ID of the first entry that does not match.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
+
+// AuditLog is the append-only store of the audit entries. Record
+// chains the entries in their order and sets their ID and hashes, in
+// the transaction carried by the context if any.
+type AuditLog interface {
+	Record(context.Context, ...*AuditEntry) error
+	Find(context.Context, AuditFilter) ([]*AuditEntry, error)
+	Verify(context.Context) (*AuditVerification, error)
+}
+
+type AuditUseCase interface {
+	Find(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+	Verify(ctx context.Context) (*AuditVerification, error)
+}
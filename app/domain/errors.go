@@ -1,35 +1,178 @@
 package domain
 
-import "errors"
+// Kind classifies the domain errors. The delivery layers
+// use it to choose the status code they respond with.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindUnsupported
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindRateLimited
+	KindTooLarge
+)
+
+// Error is a domain error with a stable, machine-readable code.
+// Clients should rely on Code, Message may change over time.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+// NewError creates a domain error.
+func NewError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	ErrAuth             = NewError(KindInternal, "auth_failed", "authentication failed")
+	ErrAuthEmptyClaim   = NewError(KindInternal, "auth_empty_claim", "claim is empty")
+	ErrAuthSign         = NewError(KindInternal, "auth_sign_failed", "failed to sign the key")
+	ErrAuthUserNotFound = NewError(KindUnauthorized, "auth_user_not_found", "user not found")
+	ErrAuthPassword     = NewError(KindUnauthorized, "auth_wrong_password", "wrong password")
+)
+
+var (
+	ErrTokenEmpty      = NewError(KindUnauthorized, "token_empty", "empty token")
+	ErrTokenMalformed  = NewError(KindUnauthorized, "token_malformed", "malformed token")
+	ErrTokenInvalid    = NewError(KindUnauthorized, "token_invalid", "invalid token")
+	ErrTokenUnexpected = NewError(KindUnauthorized, "token_unexpected_error", "unexpected error")
+	ErrAdminRequired   = NewError(KindForbidden, "admin_required", "the user is not an administrator")
+)
+
+var (
+	ErrAlbumsFindAll         = NewError(KindInternal, "albums_find_all_failed", "failed to list the albums")
+	ErrAlbumsFindByID        = NewError(KindInternal, "albums_find_failed", "failed to get the album")
+	ErrAlbumsAdd             = NewError(KindInternal, "albums_add_failed", "failed to insert the album")
+	ErrAlbumsUpdate          = NewError(KindInternal, "albums_update_failed", "failed to update the album")
+	ErrAlbumsDelete          = NewError(KindInternal, "albums_delete_failed", "failed to delete the album")
+	ErrAlbumsFindTrash       = NewError(KindInternal, "albums_find_trash_failed", "failed to list the deleted albums")
+	ErrAlbumsRestore         = NewError(KindInternal, "albums_restore_failed", "failed to restore the album")
+	ErrAlbumsPurge           = NewError(KindInternal, "albums_purge_failed", "failed to purge the deleted albums")
+	ErrAlbumsFindRevisions   = NewError(KindInternal, "albums_find_revisions_failed", "failed to list the album revisions")
+	ErrAlbumsRestoreRevision = NewError(KindInternal, "albums_restore_revision_failed", "failed to restore the album revision")
+	ErrAlbumsBulk            = NewError(KindInternal, "albums_bulk_failed", "failed to write the albums")
+	ErrAlbumsBulkAborted     = NewError(KindUnprocessable, "albums_bulk_aborted", "the bulk was rolled back because an operation failed")
+	ErrAlbumsExport          = NewError(KindInternal, "albums_export_failed", "failed to export the albums")
+	ErrAlbumsImport          = NewError(KindInternal, "albums_import_failed", "failed to import the albums")
+	ErrAlbumsImportTrashed   = NewError(KindConflict, "albums_import_trashed", "the album is in the trash")
+	ErrAlbumsImportAmbiguous = NewError(KindConflict, "albums_import_ambiguous", "several albums have this barcode")
+	ErrAlbumsImportVersion   = NewError(KindPreconditionRequired, "albums_import_version_required", "the row updates an album and must be conditioned with its version")
+	ErrAlbumsUUIDParse       = NewError(KindInvalid, "albums_invalid_uuid", "failed to parse the UUID")
+)
+
+var (
+	ErrUsersFindAll        = NewError(KindInternal, "users_find_all_failed", "failed to list the users")
+	ErrUsersFindByID       = NewError(KindInternal, "users_find_failed", "failed to get the user")
+	ErrUsersAdd            = NewError(KindInternal, "users_add_failed", "failed to insert the user")
+	ErrUsersUpdate         = NewError(KindInternal, "users_update_failed", "failed to update the user")
+	ErrUsersDelete         = NewError(KindInternal, "users_delete_failed", "failed to delete the user")
+	ErrUsersFindTrash      = NewError(KindInternal, "users_find_trash_failed", "failed to list the deleted users")
+	ErrUsersRestore        = NewError(KindInternal, "users_restore_failed", "failed to restore the user")
+	ErrUsersPurge          = NewError(KindInternal, "users_purge_failed", "failed to purge the deleted users")
+	ErrUsersUUIDParse      = NewError(KindInvalid, "users_invalid_uuid", "failed to parse the UUID")
+	ErrUsersHashPassword   = NewError(KindUnprocessable, "users_hash_password_failed", "failed to hash the password")
+	ErrUsersDuplicateEmail = NewError(KindConflict, "users_duplicate_email", "this email already exists")
+)
+
+var (
+	ErrWebhooksFindAll        = NewError(KindInternal, "webhooks_find_all_failed", "failed to list the webhooks")
+	ErrWebhooksFindByID       = NewError(KindInternal, "webhooks_find_failed", "failed to get the webhook")
+	ErrWebhooksAdd            = NewError(KindInternal, "webhooks_add_failed", "failed to insert the webhook")
+	ErrWebhooksUpdate         = NewError(KindInternal, "webhooks_update_failed", "failed to update the webhook")
+	ErrWebhooksDelete         = NewError(KindInternal, "webhooks_delete_failed", "failed to delete the webhook")
+	ErrWebhooksSecret         = NewError(KindInternal, "webhooks_secret_failed", "failed to generate the webhook secret")
+	ErrWebhooksEnqueue        = NewError(KindInternal, "webhooks_enqueue_failed", "failed to enqueue the webhook deliveries")
+	ErrWebhooksDeliver        = NewError(KindInternal, "webhooks_deliver_failed", "failed to deliver the webhooks")
+	ErrWebhooksFindDeliveries = NewError(KindInternal, "webhooks_find_deliveries_failed", "failed to list the webhook deliveries")
+	ErrWebhooksRedeliver      = NewError(KindInternal, "webhooks_redeliver_failed", "failed to redeliver the webhook")
+)
+
+var (
+	ErrAuditRecord = NewError(KindInternal, "audit_record_failed", "failed to record the audit entry")
+	ErrAuditFind   = NewError(KindInternal, "audit_find_failed", "failed to list the audit entries")
+	ErrAuditVerify = NewError(KindInternal, "audit_verify_failed", "failed to verify the audit log")
+)
+
+var (
+	ErrJobsFindAll   = NewError(KindInternal, "jobs_find_all_failed", "failed to list the jobs")
+	ErrJobsFindByID  = NewError(KindInternal, "jobs_find_failed", "failed to get the job")
+	ErrJobsEnqueue   = NewError(KindInternal, "jobs_enqueue_failed", "failed to enqueue the job")
+	ErrJobsRun       = NewError(KindInternal, "jobs_run_failed", "failed to run the jobs")
+	ErrJobsRetry     = NewError(KindInternal, "jobs_retry_failed", "failed to retry the job")
+	ErrJobsCancel    = NewError(KindInternal, "jobs_cancel_failed", "failed to cancel the job")
+	ErrJobsPurge     = NewError(KindInternal, "jobs_purge_failed", "failed to purge the finished jobs")
+	ErrJobsDuplicate = NewError(KindConflict, "job_duplicate", "a job of the same unique key is pending or running")
+	ErrJobsState     = NewError(KindConflict, "job_state_conflict", "the job cannot change from its current status")
+)
+
+var (
+	ErrStreamUnsupported = NewError(KindInternal, "stream_unsupported", "the connection does not support streaming")
+)
+
+var (
+	ErrPresenceMessage = NewError(KindInvalid, "presence_message_invalid", "the message type is unknown")
+	ErrPresenceState   = NewError(KindInvalid, "presence_state_invalid", "the state must be viewing or editing")
+	ErrPresenceField   = NewError(KindInvalid, "presence_field_invalid", "the field cannot be locked")
+)
+
+var (
+	ErrInstanceLock     = NewError(KindInternal, "instance_lock_failed", "failed to take the instance lock")
+	ErrInstanceLocked   = NewError(KindConflict, "instance_locked", "the lock is held by another instance")
+	ErrInstanceLockLost = NewError(KindInternal, "instance_lock_lost", "the instance lock has been lost")
+)
+
+var (
+	ErrResourceNotFound = NewError(KindNotFound, "resource_not_found", "the resource you requested could not be found")
+	ErrInvalidPayload   = NewError(KindInvalid, "invalid_payload", "the request payload is invalid")
+	ErrValidation       = NewError(KindInvalid, "validation_failed", "the request parameters did not validate")
+	ErrUnauthorized     = NewError(KindUnauthorized, "unauthorized", "the request is not authenticated")
+)
+
+var (
+	ErrRateLimited = NewError(KindRateLimited, "rate_limited", "too many requests, retry later")
+)
+
+var (
+	ErrPreconditionFailed   = NewError(KindPreconditionFailed, "precondition_failed", "the resource has been modified since it was read")
+	ErrPreconditionRequired = NewError(KindPreconditionRequired, "precondition_required", "the request must be conditioned with If-Match")
+)
 
 var (
-	ErrAuth             = errors.New("authentication failed")
-	ErrAuthEmptyClaim   = errors.New("claim is empty")
-	ErrAuthSign         = errors.New("failed to sign the key")
-	ErrAuthUserNotFound = errors.New("user not found")
-	ErrAuthPassword     = errors.New("wrong password")
+	ErrOutboxAdd      = NewError(KindInternal, "outbox_add_failed", "failed to store the events")
+	ErrOutboxDispatch = NewError(KindInternal, "outbox_dispatch_failed", "failed to dispatch the events")
+	ErrOutboxPurge    = NewError(KindInternal, "outbox_purge_failed", "failed to purge the published events")
+	ErrOutboxListen   = NewError(KindInternal, "outbox_listen_failed", "failed to listen to the published events")
 )
 
 var (
-	ErrAlbumsFindAll   = errors.New("failed to list the albums")
-	ErrAlbumsFindByID  = errors.New("failed to get the album")
-	ErrAlbumsAdd       = errors.New("failed to insert the album")
-	ErrAlbumsUpdate    = errors.New("failed to update the album")
-	ErrAlbumsDelete    = errors.New("failed to delete the album")
-	ErrAlbumsUUIDParse = errors.New("failed to parse the UUID")
+	ErrIdempotencyStore      = NewError(KindInternal, "idempotency_store_failed", "failed to store the idempotency key")
+	ErrIdempotencyKeyInvalid = NewError(KindInvalid, "idempotency_key_invalid", "the Idempotency-Key must have between 1 and 255 characters")
+	ErrIdempotencyKeyReused  = NewError(KindUnprocessable, "idempotency_key_reused", "the Idempotency-Key was used for a different request")
+	ErrIdempotencyInProgress = NewError(KindConflict, "idempotency_in_progress", "a request with this Idempotency-Key is still in progress")
+	ErrIdempotencyTooLarge   = NewError(KindTooLarge, "idempotency_body_too_large", "the body of a request with an Idempotency-Key is too large")
 )
 
 var (
-	ErrUsersFindAll        = errors.New("failed to list the users")
-	ErrUsersFindByID       = errors.New("failed to get the user")
-	ErrUsersAdd            = errors.New("failed to insert the user")
-	ErrUsersUpdate         = errors.New("failed to update the user")
-	ErrUsersDelete         = errors.New("failed to delete the user")
-	ErrUsersUUIDParse      = errors.New("failed to parse the UUID")
-	ErrUsersHashPassword   = errors.New("failed to hash the password")
-	ErrUsersDuplicateEmail = errors.New("this email already exists")
+	ErrImportMediaType = NewError(KindUnsupported, "import_unsupported_media_type", "the import must be CSV or JSON Lines")
+	ErrImportMapping   = NewError(KindInvalid, "import_invalid_mapping", "the column mapping is invalid")
+	ErrImportMalformed = NewError(KindInvalid, "import_malformed_row", "the row could not be parsed")
 )
 
 var (
-	ErrResourceNotFound = errors.New("the resource you requested could not be found")
+	ErrPatchMediaType     = NewError(KindUnsupported, "patch_unsupported_media_type", "the patch must be a merge patch or a JSON patch document")
+	ErrPatchMalformed     = NewError(KindInvalid, "patch_malformed", "the patch document is malformed")
+	ErrPatchUnprocessable = NewError(KindUnprocessable, "patch_unprocessable", "the patch could not be applied to the resource")
 )
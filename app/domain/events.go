@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The types of the domain events, "<aggregate>.<change>".
+const (
+	EventAlbumCreated  = "album.created"
+	EventAlbumUpdated  = "album.updated"
+	EventAlbumDeleted  = "album.deleted"
+	EventAlbumRestored = "album.restored"
+	EventUserCreated   = "user.created"
+	EventUserUpdated   = "user.updated"
+	EventUserDeleted   = "user.deleted"
+	EventUserRestored  = "user.restored"
+)
+
+// Event is a change of an album or a user, raised by the use cases.
+// The payload is the state of the aggregate after the change, the
+// events of an aggregate are published in the order they were raised.
+// Sequence is the position of the event in the outbox, shared by
+// every replica, it is set once the event is stored.
+type Event struct {
+	ID            uuid.UUID       `json:"id"`
+	Sequence      int64           `json:"-"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	ActorUUID     *uuid.UUID      `json:"actor_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// NewEvent creates an event of the actor of ctx, if any.
+func NewEvent(ctx context.Context, eventType, aggregateType string, aggregateID uuid.UUID, payload interface{}) *Event {
+	event := &Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		OccurredAt:    time.Now().UTC(),
+	}
+
+	// the payloads are domain structs, their encoding does not fail
+	event.Payload, _ = json.Marshal(payload)
+
+	if actor, ok := ActorFromContext(ctx); ok && actor.UUID != uuid.Nil {
+		event.ActorUUID = &actor.UUID
+	}
+
+	return event
+}
+
+// NewAlbumEvent creates an event of album.
+func NewAlbumEvent(ctx context.Context, eventType string, album *Albums) *Event {
+	return NewEvent(ctx, eventType, "album", album.UUID, album)
+}
+
+// NewUserEvent creates an event of user.
+func NewUserEvent(ctx context.Context, eventType string, user *UsersList) *Event {
+	return NewEvent(ctx, eventType, "user", user.UUID, user)
+}
+
+// Outbox stores the events in the transaction of the change raising
+// them, for a relay to publish them once the change is committed.
+//
+// Within runs fn in a transaction that the repositories called with
+// its context join, then stores the events fn returns in the same
+// transaction. Nothing is written if fn fails.
+//
+// Dispatch hands to fn the oldest pending event of up to limit
+// aggregates and returns how many it handed. The events fn fails
+// are retried after backoff(attempts), the next events of their
+// aggregate wait for them. Purge removes the events published before
+// the given time.
+type Outbox interface {
+	Within(ctx context.Context, fn func(ctx context.Context) ([]*Event, error)) error
+	Dispatch(
+		ctx context.Context,
+		limit int,
+		backoff func(attempts int) time.Duration,
+		fn func(ctx context.Context, event *Event) error,
+	) (int, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OutboxListener hands the events published from the outbox, by any
+// replica, to every replica. Listen calls fn with each event in the
+// order they were published until ctx is done, or fails when it cannot
+// listen. The events published while the listener reconnects are read
+// back once it is connected again, fn may then see them twice.
+type OutboxListener interface {
+	Listen(ctx context.Context, fn func(ctx context.Context, event *Event)) error
+}
+
+// Publisher delivers the events to the other systems. The delivery
+// is at least once, an event may be published more than once.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// EventSubscription is a subscription to a feed. Replay holds the
+// events missed since the last event ID given to Subscribe, Resumed
+// is false when that event is no longer known. Events is closed when
+// the subscriber falls behind or the feed is closed, Cancel ends the
+// subscription.
+type EventSubscription struct {
+	Replay  []*Event
+	Events  <-chan *Event
+	Resumed bool
+	Cancel  func()
+}
+
+// EventFeed streams the recent events to the clients.
+type EventFeed interface {
+	Subscribe(lastEventID string) *EventSubscription
+}
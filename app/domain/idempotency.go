@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord is a request made with an Idempotency-Key and,
+// once it has completed, the response to replay for its repeats.
+// The keys are scoped, e.g. by user, so clients cannot collide.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	Status      int
+	Header      map[string][]string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response of the request is stored.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
+
+// IdempotencyStore keeps the idempotency records until they expire.
+//
+// Reserve stores the record of a new request, without a response, and
+// returns nil. When the key is already used and not expired it stores
+// nothing and returns the existing record instead. Complete stores the
+// response of a reserved record and Release forgets the record, so the
+// request can be retried.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string) error
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a job. A pending job runs once RunAt is due, it is
+// pending again after a failed attempt until it runs out of attempts,
+// it is then failed until it is retried.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// Job is a unit of deferred work run by the handler of its Kind with
+// Payload. Only one job of a UniqueKey is pending or running at once.
+// LockID identifies the claim of a running job, its attempt is only
+// recorded by the runner holding it.
+type Job struct {
+	UUID        uuid.UUID       `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	UniqueKey   string          `json:"unique_key,omitempty"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	LockID      uuid.UUID       `json:"-"`
+}
+
+// SetDefaults fills the fields a new job may leave out, it is
+// due at now and has maxAttempts unless told otherwise.
+func (j *Job) SetDefaults(now time.Time, maxAttempts int) {
+	if j.UUID == uuid.Nil {
+		j.UUID = uuid.New()
+	}
+	if len(j.Payload) == 0 {
+		j.Payload = json.RawMessage("{}")
+	}
+	if j.MaxAttempts <= 0 {
+		j.MaxAttempts = maxAttempts
+	}
+	if j.RunAt.IsZero() {
+		j.RunAt = now
+	}
+
+	j.Status = JobPending
+	j.CreatedAt = now
+	j.UpdatedAt = now
+}
+
+// JobHandler runs a job, the job is retried when it returns an error.
+type JobHandler func(ctx context.Context, job *Job) error
+
+// JobFilter selects the jobs, newest first. Status and Kind match any
+// when empty, Before is the time of creation the page starts after.
+type JobFilter struct {
+	Status string
+	Kind   string
+	Before *time.Time
+	Limit  int
+}
+
+// JobsRepository stores the jobs.
+//
+// Enqueue reports false when a job of the same unique key is pending
+// or running. Claim hands out up to limit due jobs, including the
+// running ones whose claim has expired, and holds them until so that
+// they are handed out again only if their attempt is not recorded by
+// then. Record stores the outcome of an attempt, unless the claim was
+// lost. Schedule enqueues job if the schedule name is due at due,
+// once for all the runners, and moves it to next.
+type JobsRepository interface {
+	FindAll(ctx context.Context, filter JobFilter) ([]*Job, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (*Job, error)
+	Enqueue(ctx context.Context, job *Job) (bool, error)
+	Claim(ctx context.Context, limit int, until time.Time) ([]*Job, error)
+	Record(ctx context.Context, job *Job) error
+	Schedule(ctx context.Context, name string, due, next time.Time, job *Job) (bool, error)
+	Retry(ctx context.Context, uuid uuid.UUID) error
+	Cancel(ctx context.Context, uuid uuid.UUID) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// JobsUseCase enqueues and manages the jobs. Enqueue fills the
+// missing fields of job, only the failed and canceled jobs are
+// retried and only the pending and running ones are canceled.
+type JobsUseCase interface {
+	FindAll(ctx context.Context, filter JobFilter) ([]*Job, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (*Job, error)
+	Enqueue(ctx context.Context, job *Job) error
+	Retry(ctx context.Context, uuid uuid.UUID) error
+	Cancel(ctx context.Context, uuid uuid.UUID) error
+}
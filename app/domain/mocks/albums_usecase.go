@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"hexagony/app/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -59,6 +60,30 @@ func (m *AlbumUseCase) FindByID(ctx context.Context, id uuid.UUID) (*domain.Albu
 	return album, err
 }
 
+func (m *AlbumUseCase) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Albums, error) {
+	args := m.Called(ctx, ids)
+
+	var albums []*domain.Albums
+
+	if rf, ok := args.Get(0).(func(context.Context, []uuid.UUID) []*domain.Albums); ok {
+		albums = rf(ctx, ids)
+	} else {
+		if args.Get(0) != nil {
+			albums = args.Get(0).([]*domain.Albums)
+		}
+	}
+
+	var err error
+
+	if rf, ok := args.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		err = rf(ctx, ids)
+	} else {
+		err = args.Error(1)
+	}
+
+	return albums, err
+}
+
 func (m *AlbumUseCase) Add(ctx context.Context, album *domain.Albums) error {
 	args := m.Called(ctx, album)
 
@@ -87,7 +112,58 @@ func (m *AlbumUseCase) Update(ctx context.Context, id uuid.UUID, album *domain.A
 	return err
 }
 
-func (m *AlbumUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *AlbumUseCase) Patch(ctx context.Context, id uuid.UUID, patch *domain.AlbumsPatch) error {
+	args := m.Called(ctx, id, patch)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, uuid.UUID, *domain.AlbumsPatch) error); ok {
+		err = rf(ctx, id, patch)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
+
+func (m *AlbumUseCase) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	args := m.Called(ctx, id, version)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, uuid.UUID, int) error); ok {
+		err = rf(ctx, id, version)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
+
+func (m *AlbumUseCase) FindTrash(ctx context.Context) ([]*domain.Albums, error) {
+	args := m.Called(ctx)
+
+	var albums []*domain.Albums
+
+	if rf, ok := args.Get(0).(func(context.Context) []*domain.Albums); ok {
+		albums = rf(ctx)
+	} else {
+		if args.Get(0) != nil {
+			albums = args.Get(0).([]*domain.Albums)
+		}
+	}
+
+	var err error
+	if rf, ok := args.Get(1).(func(context.Context) error); ok {
+		err = rf(ctx)
+	} else {
+		err = args.Error(1)
+	}
+
+	return albums, err
+}
+
+func (m *AlbumUseCase) Restore(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 
 	var err error
@@ -100,3 +176,110 @@ func (m *AlbumUseCase) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return err
 }
+
+func (m *AlbumUseCase) Purge(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+
+	var purged int64
+
+	if rf, ok := args.Get(0).(func(context.Context, time.Time) int64); ok {
+		purged = rf(ctx, before)
+	} else {
+		purged = args.Get(0).(int64)
+	}
+
+	var err error
+	if rf, ok := args.Get(1).(func(context.Context, time.Time) error); ok {
+		err = rf(ctx, before)
+	} else {
+		err = args.Error(1)
+	}
+
+	return purged, err
+}
+
+func (m *AlbumUseCase) FindRevisions(ctx context.Context, id uuid.UUID) ([]*domain.AlbumRevision, error) {
+	args := m.Called(ctx, id)
+
+	var revisions []*domain.AlbumRevision
+
+	if rf, ok := args.Get(0).(func(context.Context, uuid.UUID) []*domain.AlbumRevision); ok {
+		revisions = rf(ctx, id)
+	} else {
+		if args.Get(0) != nil {
+			revisions = args.Get(0).([]*domain.AlbumRevision)
+		}
+	}
+
+	var err error
+	if rf, ok := args.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		err = rf(ctx, id)
+	} else {
+		err = args.Error(1)
+	}
+
+	return revisions, err
+}
+
+func (m *AlbumUseCase) RestoreRevision(ctx context.Context, id uuid.UUID, revision int, version int) (int, error) {
+	args := m.Called(ctx, id, revision, version)
+
+	var restored int
+
+	if rf, ok := args.Get(0).(func(context.Context, uuid.UUID, int, int) int); ok {
+		restored = rf(ctx, id, revision, version)
+	} else {
+		restored = args.Get(0).(int)
+	}
+
+	var err error
+	if rf, ok := args.Get(1).(func(context.Context, uuid.UUID, int, int) error); ok {
+		err = rf(ctx, id, revision, version)
+	} else {
+		err = args.Error(1)
+	}
+
+	return restored, err
+}
+
+func (m *AlbumUseCase) Bulk(ctx context.Context, operations []*domain.AlbumsBulkOperation, atomic bool) error {
+	args := m.Called(ctx, operations, atomic)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, []*domain.AlbumsBulkOperation, bool) error); ok {
+		err = rf(ctx, operations, atomic)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
+
+func (m *AlbumUseCase) Export(ctx context.Context, fn func(*domain.Albums) error) error {
+	args := m.Called(ctx, fn)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, func(*domain.Albums) error) error); ok {
+		err = rf(ctx, fn)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
+
+func (m *AlbumUseCase) Import(ctx context.Context, rows []*domain.AlbumsImportRow, dryRun bool) error {
+	args := m.Called(ctx, rows, dryRun)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, []*domain.AlbumsImportRow, bool) error); ok {
+		err = rf(ctx, rows, dryRun)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
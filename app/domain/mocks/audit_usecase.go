@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditUseCase struct {
+	mock.Mock
+}
+
+func (m *AuditUseCase) Find(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+
+	var entries []*domain.AuditEntry
+
+	if rf, ok := args.Get(0).(func(context.Context, domain.AuditFilter) []*domain.AuditEntry); ok {
+		entries = rf(ctx, filter)
+	} else {
+		if args.Get(0) != nil {
+			entries = args.Get(0).([]*domain.AuditEntry)
+		}
+	}
+
+	var err error
+	if rf, ok := args.Get(1).(func(context.Context, domain.AuditFilter) error); ok {
+		err = rf(ctx, filter)
+	} else {
+		err = args.Error(1)
+	}
+
+	return entries, err
+}
+
+func (m *AuditUseCase) Verify(ctx context.Context) (*domain.AuditVerification, error) {
+	args := m.Called(ctx)
+
+	var verification *domain.AuditVerification
+
+	if rf, ok := args.Get(0).(func(context.Context) *domain.AuditVerification); ok {
+		verification = rf(ctx)
+	} else {
+		if args.Get(0) != nil {
+			verification = args.Get(0).(*domain.AuditVerification)
+		}
+	}
+
+	var err error
+	if rf, ok := args.Get(1).(func(context.Context) error); ok {
+		err = rf(ctx)
+	} else {
+		err = args.Error(1)
+	}
+
+	return verification, err
+}
@@ -0,0 +1,89 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type JobRepository struct {
+	mock.Mock
+}
+
+func (m *JobRepository) FindAll(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	args := m.Called(ctx, filter)
+
+	var jobs []*domain.Job
+	if args.Get(0) != nil {
+		jobs = args.Get(0).([]*domain.Job)
+	}
+
+	return jobs, args.Error(1)
+}
+
+func (m *JobRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	args := m.Called(ctx, id)
+
+	var job *domain.Job
+	if args.Get(0) != nil {
+		job = args.Get(0).(*domain.Job)
+	}
+
+	return job, args.Error(1)
+}
+
+func (m *JobRepository) Enqueue(ctx context.Context, job *domain.Job) (bool, error) {
+	args := m.Called(ctx, job)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *JobRepository) Claim(ctx context.Context, limit int, until time.Time) ([]*domain.Job, error) {
+	args := m.Called(ctx, limit, until)
+
+	var jobs []*domain.Job
+	if args.Get(0) != nil {
+		jobs = args.Get(0).([]*domain.Job)
+	}
+
+	return jobs, args.Error(1)
+}
+
+func (m *JobRepository) Record(ctx context.Context, job *domain.Job) error {
+	args := m.Called(ctx, job)
+
+	return args.Error(0)
+}
+
+func (m *JobRepository) Schedule(
+	ctx context.Context,
+	name string,
+	due time.Time,
+	next time.Time,
+	job *domain.Job,
+) (bool, error) {
+	args := m.Called(ctx, name, due, next, job)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *JobRepository) Retry(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *JobRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *JobRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+
+	return args.Get(0).(int64), args.Error(1)
+}
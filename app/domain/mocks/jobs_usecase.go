@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type JobUseCase struct {
+	mock.Mock
+}
+
+func (m *JobUseCase) FindAll(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	args := m.Called(ctx, filter)
+
+	var jobs []*domain.Job
+	if args.Get(0) != nil {
+		jobs = args.Get(0).([]*domain.Job)
+	}
+
+	return jobs, args.Error(1)
+}
+
+func (m *JobUseCase) FindByID(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	args := m.Called(ctx, id)
+
+	var job *domain.Job
+	if args.Get(0) != nil {
+		job = args.Get(0).(*domain.Job)
+	}
+
+	return job, args.Error(1)
+}
+
+func (m *JobUseCase) Enqueue(ctx context.Context, job *domain.Job) error {
+	args := m.Called(ctx, job)
+
+	return args.Error(0)
+}
+
+func (m *JobUseCase) Retry(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *JobUseCase) Cancel(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Outbox struct {
+	mock.Mock
+}
+
+func (m *Outbox) Within(ctx context.Context, fn func(ctx context.Context) ([]*domain.Event, error)) error {
+	args := m.Called(ctx, fn)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, func(context.Context) ([]*domain.Event, error)) error); ok {
+		err = rf(ctx, fn)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
+
+func (m *Outbox) Dispatch(
+	ctx context.Context,
+	limit int,
+	backoff func(attempts int) time.Duration,
+	fn func(ctx context.Context, event *domain.Event) error,
+) (int, error) {
+	args := m.Called(ctx, limit, backoff, fn)
+
+	var dispatched int
+
+	if rf, ok := args.Get(0).(func(context.Context, int, func(int) time.Duration, func(context.Context, *domain.Event) error) int); ok {
+		dispatched = rf(ctx, limit, backoff, fn)
+	} else {
+		dispatched = args.Int(0)
+	}
+
+	return dispatched, args.Error(1)
+}
+
+func (m *Outbox) Purge(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+
+	return args.Get(0).(int64), args.Error(1)
+}
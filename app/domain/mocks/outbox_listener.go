@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OutboxListener struct {
+	mock.Mock
+}
+
+func (m *OutboxListener) Listen(ctx context.Context, fn func(ctx context.Context, event *domain.Event)) error {
+	args := m.Called(ctx, fn)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, func(context.Context, *domain.Event)) error); ok {
+		err = rf(ctx, fn)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
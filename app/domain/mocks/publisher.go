@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Publisher struct {
+	mock.Mock
+}
+
+func (m *Publisher) Publish(ctx context.Context, event *domain.Event) error {
+	args := m.Called(ctx, event)
+
+	var err error
+
+	if rf, ok := args.Get(0).(func(context.Context, *domain.Event) error); ok {
+		err = rf(ctx, event)
+	} else {
+		err = args.Error(0)
+	}
+
+	return err
+}
@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 	domain "hexagony/app/domain"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -30,13 +31,13 @@ func (_m *UserRepository) Add(_a0 context.Context, _a1 *domain.Users) error {
 	return r0
 }
 
-// Delete provides a mock function with given fields: _a0, _a1
-func (_m *UserRepository) Delete(_a0 context.Context, _a1 uuid.UUID) error {
-	ret := _m.Called(_a0, _a1)
+// Delete provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) Delete(_a0 context.Context, _a1 uuid.UUID, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,6 +91,101 @@ func (_m *UserRepository) FindByID(_a0 context.Context, _a1 uuid.UUID) (*domain.
 	return r0, r1
 }
 
+// FindByIDs provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) FindByIDs(_a0 context.Context, _a1 []uuid.UUID) ([]*domain.UsersList, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.UsersList
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []*domain.UsersList); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.UsersList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindTrash provides a mock function with given fields: _a0
+func (_m *UserRepository) FindTrash(_a0 context.Context) ([]*domain.UsersList, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*domain.UsersList
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.UsersList); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.UsersList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) Patch(_a0 context.Context, _a1 uuid.UUID, _a2 *domain.UsersPatch) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *domain.UsersPatch) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Purge provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) Purge(_a0 context.Context, _a1 time.Time) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) Restore(_a0 context.Context, _a1 uuid.UUID) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: _a0, _a1, _a2
 func (_m *UserRepository) Update(_a0 context.Context, _a1 uuid.UUID, _a2 *domain.Users) error {
 	ret := _m.Called(_a0, _a1, _a2)
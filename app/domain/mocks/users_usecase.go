@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 	domain "hexagony/app/domain"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -30,13 +31,13 @@ func (_m *UserUseCase) Add(ctx context.Context, user *domain.Users) error {
 	return r0
 }
 
-// Delete provides a mock function with given fields: ctx, _a1
-func (_m *UserUseCase) Delete(ctx context.Context, _a1 uuid.UUID) error {
-	ret := _m.Called(ctx, _a1)
+// Delete provides a mock function with given fields: ctx, _a1, version
+func (_m *UserUseCase) Delete(ctx context.Context, _a1 uuid.UUID, version int) error {
+	ret := _m.Called(ctx, _a1, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
-		r0 = rf(ctx, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) error); ok {
+		r0 = rf(ctx, _a1, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,6 +91,101 @@ func (_m *UserUseCase) FindByID(ctx context.Context, _a1 uuid.UUID) (*domain.Use
 	return r0, r1
 }
 
+// FindByIDs provides a mock function with given fields: ctx, _a1
+func (_m *UserUseCase) FindByIDs(ctx context.Context, _a1 []uuid.UUID) ([]*domain.UsersList, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 []*domain.UsersList
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []*domain.UsersList); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.UsersList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindTrash provides a mock function with given fields: ctx
+func (_m *UserUseCase) FindTrash(ctx context.Context) ([]*domain.UsersList, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*domain.UsersList
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.UsersList); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.UsersList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: ctx, _a1, patch
+func (_m *UserUseCase) Patch(ctx context.Context, _a1 uuid.UUID, patch *domain.UsersPatch) error {
+	ret := _m.Called(ctx, _a1, patch)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *domain.UsersPatch) error); ok {
+		r0 = rf(ctx, _a1, patch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *UserUseCase) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, _a1
+func (_m *UserUseCase) Restore(ctx context.Context, _a1 uuid.UUID) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, _a1, user
 func (_m *UserUseCase) Update(ctx context.Context, _a1 uuid.UUID, user *domain.Users) error {
 	ret := _m.Called(ctx, _a1, user)
@@ -0,0 +1,109 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookRepository struct {
+	mock.Mock
+}
+
+func (m *WebhookRepository) FindAll(ctx context.Context) ([]*domain.Webhook, error) {
+	args := m.Called(ctx)
+
+	var webhooks []*domain.Webhook
+	if args.Get(0) != nil {
+		webhooks = args.Get(0).([]*domain.Webhook)
+	}
+
+	return webhooks, args.Error(1)
+}
+
+func (m *WebhookRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	args := m.Called(ctx, id)
+
+	var webhook *domain.Webhook
+	if args.Get(0) != nil {
+		webhook = args.Get(0).(*domain.Webhook)
+	}
+
+	return webhook, args.Error(1)
+}
+
+func (m *WebhookRepository) Add(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) Update(ctx context.Context, id uuid.UUID, webhook *domain.Webhook) error {
+	args := m.Called(ctx, id, webhook)
+
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) Enqueue(ctx context.Context, event *domain.Event) (int64, error) {
+	args := m.Called(ctx, event)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *WebhookRepository) Claim(ctx context.Context, limit int, until time.Time) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, until)
+
+	var deliveries []*domain.WebhookDelivery
+	if args.Get(0) != nil {
+		deliveries = args.Get(0).([]*domain.WebhookDelivery)
+	}
+
+	return deliveries, args.Error(1)
+}
+
+func (m *WebhookRepository) Record(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error {
+	args := m.Called(ctx, delivery, attempt)
+
+	return args.Error(0)
+}
+
+func (m *WebhookRepository) FindDeliveries(
+	ctx context.Context,
+	webhook uuid.UUID,
+	filter domain.WebhookDeliveryFilter,
+) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhook, filter)
+
+	var deliveries []*domain.WebhookDelivery
+	if args.Get(0) != nil {
+		deliveries = args.Get(0).([]*domain.WebhookDelivery)
+	}
+
+	return deliveries, args.Error(1)
+}
+
+func (m *WebhookRepository) FindDelivery(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhook, delivery)
+
+	var found *domain.WebhookDelivery
+	if args.Get(0) != nil {
+		found = args.Get(0).(*domain.WebhookDelivery)
+	}
+
+	return found, args.Error(1)
+}
+
+func (m *WebhookRepository) Redeliver(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) error {
+	args := m.Called(ctx, webhook, delivery)
+
+	return args.Error(0)
+}
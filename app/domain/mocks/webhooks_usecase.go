@@ -0,0 +1,101 @@
+package mocks
+
+import (
+	"context"
+	"hexagony/app/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookUseCase struct {
+	mock.Mock
+}
+
+func (m *WebhookUseCase) FindAll(ctx context.Context) ([]*domain.Webhook, error) {
+	args := m.Called(ctx)
+
+	var webhooks []*domain.Webhook
+	if args.Get(0) != nil {
+		webhooks = args.Get(0).([]*domain.Webhook)
+	}
+
+	return webhooks, args.Error(1)
+}
+
+func (m *WebhookUseCase) FindByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	args := m.Called(ctx, id)
+
+	var webhook *domain.Webhook
+	if args.Get(0) != nil {
+		webhook = args.Get(0).(*domain.Webhook)
+	}
+
+	return webhook, args.Error(1)
+}
+
+func (m *WebhookUseCase) Add(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+
+	if rf, ok := args.Get(0).(func(context.Context, *domain.Webhook) error); ok {
+		return rf(ctx, webhook)
+	}
+
+	return args.Error(0)
+}
+
+func (m *WebhookUseCase) Update(ctx context.Context, id uuid.UUID, webhook *domain.Webhook) error {
+	args := m.Called(ctx, id, webhook)
+
+	return args.Error(0)
+}
+
+func (m *WebhookUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *WebhookUseCase) FindDeliveries(
+	ctx context.Context,
+	webhook uuid.UUID,
+	filter domain.WebhookDeliveryFilter,
+) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhook, filter)
+
+	var deliveries []*domain.WebhookDelivery
+	if args.Get(0) != nil {
+		deliveries = args.Get(0).([]*domain.WebhookDelivery)
+	}
+
+	return deliveries, args.Error(1)
+}
+
+func (m *WebhookUseCase) FindDelivery(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhook, delivery)
+
+	var found *domain.WebhookDelivery
+	if args.Get(0) != nil {
+		found = args.Get(0).(*domain.WebhookDelivery)
+	}
+
+	return found, args.Error(1)
+}
+
+func (m *WebhookUseCase) Redeliver(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) error {
+	args := m.Called(ctx, webhook, delivery)
+
+	return args.Error(0)
+}
+
+func (m *WebhookUseCase) Enqueue(ctx context.Context, event *domain.Event) error {
+	args := m.Called(ctx, event)
+
+	return args.Error(0)
+}
+
+func (m *WebhookUseCase) Deliver(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+
+	return args.Int(0), args.Error(1)
+}
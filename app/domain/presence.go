@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// States of an editor of an album.
+const (
+	PresenceViewing = "viewing"
+	PresenceEditing = "editing"
+)
+
+// Types of the presence messages. The clients send the state, lock
+// and unlock messages, the server sends the others along with the
+// album.updated, album.deleted and album.restored events.
+const (
+	PresenceState      = "state"
+	PresenceLock       = "lock"
+	PresenceUnlock     = "unlock"
+	PresenceEditors    = "presence"
+	PresenceLockDenied = "lock_denied"
+	PresenceError      = "error"
+)
+
+// PresenceFields are the fields of an album that can be locked.
+var PresenceFields = []string{"name", "length", "barcode"}
+
+// Editor is a connection to the presence of an album, a user may have
+// several. Fields are the fields it has locked.
+type Editor struct {
+	ID     uuid.UUID `json:"id"`
+	User   Actor     `json:"user"`
+	State  string    `json:"state"`
+	Fields []string  `json:"fields"`
+}
+
+// PresenceMessage is a message of the presence of an album, the
+// fields set depend on its type.
+type PresenceMessage struct {
+	Type    string    `json:"type"`
+	State   string    `json:"state,omitempty"`
+	Field   string    `json:"field,omitempty"`
+	Editors []*Editor `json:"editors,omitempty"`
+	Holder  *Editor   `json:"holder,omitempty"`
+	Event   *Event    `json:"event,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// PresenceSession is an editor joined to the presence of an album.
+// Messages is closed when the session is left, when it falls behind
+// or when the hub is closed.
+type PresenceSession struct {
+	ID       uuid.UUID
+	Album    uuid.UUID
+	Messages <-chan *PresenceMessage
+}
+
+// PresenceHub tracks the editors of the albums, their field locks,
+// and notifies them of the changes of the album.
+type PresenceHub interface {
+	Join(album uuid.UUID, actor Actor) *PresenceSession
+	Receive(session *PresenceSession, message *PresenceMessage)
+	Leave(session *PresenceSession)
+}
+
+// InstanceLock is held by a single instance at a time, e.g. the one
+// serving the presence, whose editors and locks are kept in memory.
+type InstanceLock interface {
+	// Acquire takes the lock, ErrInstanceLocked when another
+	// instance holds it.
+	Acquire(ctx context.Context) error
+
+	// Hold keeps the lock until ctx is done, then releases it. It
+	// returns ErrInstanceLockLost when the lock is lost before.
+	Hold(ctx context.Context) error
+}
@@ -7,35 +7,89 @@ import (
 	"github.com/google/uuid"
 )
 
+// Users is an account of the API. Version is increased by every write,
+// the updates and deletes are conditioned on it unless it is zero.
+// The deleted users stay in the trash, with DeletedAt set, until
+// they are restored or purged.
 type Users struct {
-	UUID      uuid.UUID `db:"uuid" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Email     string    `db:"email" json:"email"`
-	Password  string    `db:"password" json:"password"`
-	CreatedAt time.Time `db:"created_at" json:"created_at" `
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at" `
+	UUID      uuid.UUID  `db:"uuid" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	Email     string     `db:"email" json:"email"`
+	Password  string     `db:"password" json:"password"`
+	Version   int        `db:"version" json:"version"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at" `
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at" `
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
 type UsersList struct {
-	UUID      uuid.UUID `db:"uuid" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Email     string    `db:"email" json:"email"`
-	CreatedAt time.Time `db:"created_at" json:"created_at" `
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at" `
+	UUID      uuid.UUID  `db:"uuid" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	Email     string     `db:"email" json:"email"`
+	Version   int        `db:"version" json:"version"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at" `
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at" `
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
+// Diff returns the changes of the name, the email and the deletion
+// time from u to b. The password is never part of it.
+func (u *UsersList) Diff(b *UsersList) []FieldChange {
+	changes := []FieldChange{}
+
+	if u.Name != b.Name {
+		changes = append(changes, FieldChange{Field: "name", From: u.Name, To: b.Name})
+	}
+	if u.Email != b.Email {
+		changes = append(changes, FieldChange{Field: "email", From: u.Email, To: b.Email})
+	}
+	if !sameTime(u.DeletedAt, b.DeletedAt) {
+		changes = append(changes, FieldChange{Field: "deleted_at", From: u.DeletedAt, To: b.DeletedAt})
+	}
+
+	return changes
+}
+
+// UsersPatch is a partial update of an user,
+// only the fields that are not nil are changed.
+type UsersPatch struct {
+	Name      *string
+	Email     *string
+	UpdatedAt time.Time
+	Version   int
+}
+
+// Empty reports whether the patch changes no field.
+func (p *UsersPatch) Empty() bool {
+	return p.Name == nil && p.Email == nil
+}
+
+// UsersRepository stores the users. Update and Patch take the expected
+// version from the user or the patch and set it to the new one, a
+// version mismatch is reported as ErrPreconditionFailed. FindByID locks
+// the user until the end of the transaction when it is called in one.
 type UsersRepository interface {
 	FindAll(context.Context) ([]*UsersList, error)
 	FindByID(context.Context, uuid.UUID) (*UsersList, error)
+	FindByIDs(context.Context, []uuid.UUID) ([]*UsersList, error)
 	Add(context.Context, *Users) error
 	Update(context.Context, uuid.UUID, *Users) error
-	Delete(context.Context, uuid.UUID) error
+	Patch(context.Context, uuid.UUID, *UsersPatch) error
+	Delete(context.Context, uuid.UUID, int) error
+	FindTrash(context.Context) ([]*UsersList, error)
+	Restore(context.Context, uuid.UUID) error
+	Purge(context.Context, time.Time) (int64, error)
 }
 
 type UsersUseCase interface {
 	FindAll(ctx context.Context) ([]*UsersList, error)
 	FindByID(ctx context.Context, uuid uuid.UUID) (*UsersList, error)
+	FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]*UsersList, error)
 	Add(ctx context.Context, user *Users) error
 	Update(ctx context.Context, uuid uuid.UUID, user *Users) error
-	Delete(ctx context.Context, uuid uuid.UUID) error
+	Patch(ctx context.Context, uuid uuid.UUID, patch *UsersPatch) error
+	Delete(ctx context.Context, uuid uuid.UUID, version int) error
+	FindTrash(ctx context.Context) ([]*UsersList, error)
+	Restore(ctx context.Context, uuid uuid.UUID) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
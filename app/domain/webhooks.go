@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a webhook delivery. A pending delivery is attempted
+// until it succeeds or runs out of attempts, it is then dead until
+// it is redelivered.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// WebhookEventTypes are the types of the events a webhook can subscribe to.
+var WebhookEventTypes = []string{
+	EventAlbumCreated,
+	EventAlbumUpdated,
+	EventAlbumDeleted,
+	EventAlbumRestored,
+	EventUserCreated,
+	EventUserUpdated,
+	EventUserDeleted,
+	EventUserRestored,
+}
+
+// Webhook is a subscriber URL notified of the events of EventTypes.
+// The deliveries are signed with Secret, which is only shown when
+// the webhook is created.
+type Webhook struct {
+	UUID       uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"-"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is an event to deliver to a webhook, Payload being
+// the body posted to it. Webhook is only set on the claimed deliveries.
+type WebhookDelivery struct {
+	UUID           uuid.UUID         `json:"id"`
+	WebhookUUID    uuid.UUID         `json:"webhook_id"`
+	EventID        uuid.UUID         `json:"event_id"`
+	EventType      string            `json:"event_type"`
+	Payload        json.RawMessage   `json:"payload"`
+	Status         string            `json:"status"`
+	Attempts       int               `json:"attempts"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at,omitempty"`
+	ResponseStatus int               `json:"response_status,omitempty"`
+	LastError      string            `json:"last_error,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	DeliveredAt    *time.Time        `json:"delivered_at,omitempty"`
+	Log            []*WebhookAttempt `json:"log,omitempty"`
+	Webhook        *Webhook          `json:"-"`
+}
+
+// WebhookAttempt is an attempt to deliver an event, Status being the
+// status of the delivery once attempted and NextAttemptAt the time of
+// the retry of a failed attempt.
+type WebhookAttempt struct {
+	StatusCode    int        `db:"status_code" json:"status_code,omitempty"`
+	Error         string     `db:"error" json:"error,omitempty"`
+	Duration      int64      `db:"duration_ms" json:"duration_ms"`
+	AttemptedAt   time.Time  `db:"attempted_at" json:"attempted_at"`
+	Status        string     `db:"-" json:"-"`
+	NextAttemptAt *time.Time `db:"-" json:"-"`
+}
+
+// WebhookDeliveryFilter selects the deliveries of a webhook, newest
+// first. Status matches any when empty, Before is the time of creation
+// the page starts after.
+type WebhookDeliveryFilter struct {
+	Status string
+	Before *time.Time
+	Limit  int
+}
+
+// WebhooksRepository stores the webhooks and their deliveries.
+//
+// Enqueue creates a delivery of event for every active webhook
+// subscribed to it, once however often it is enqueued, and returns
+// how many were created. Claim hands out up to limit due deliveries,
+// along with their webhook, and postpones them to until so that they
+// are handed out again only if their attempt is not recorded by then.
+// Record stores the attempt and the status it leads to.
+type WebhooksRepository interface {
+	FindAll(ctx context.Context) ([]*Webhook, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (*Webhook, error)
+	Add(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, uuid uuid.UUID, webhook *Webhook) error
+	Delete(ctx context.Context, uuid uuid.UUID) error
+	Enqueue(ctx context.Context, event *Event) (int64, error)
+	Claim(ctx context.Context, limit int, until time.Time) ([]*WebhookDelivery, error)
+	Record(ctx context.Context, delivery *WebhookDelivery, attempt *WebhookAttempt) error
+	FindDeliveries(ctx context.Context, webhook uuid.UUID, filter WebhookDeliveryFilter) ([]*WebhookDelivery, error)
+	FindDelivery(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) (*WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) error
+}
+
+// WebhooksUseCase manages the webhooks. Enqueue is the handler of the
+// events, Deliver attempts the due deliveries and returns how many.
+type WebhooksUseCase interface {
+	FindAll(ctx context.Context) ([]*Webhook, error)
+	FindByID(ctx context.Context, uuid uuid.UUID) (*Webhook, error)
+	Add(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, uuid uuid.UUID, webhook *Webhook) error
+	Delete(ctx context.Context, uuid uuid.UUID) error
+	FindDeliveries(ctx context.Context, webhook uuid.UUID, filter WebhookDeliveryFilter) ([]*WebhookDelivery, error)
+	FindDelivery(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) (*WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) error
+	Enqueue(ctx context.Context, event *Event) error
+	Deliver(ctx context.Context) (int, error)
+}
@@ -0,0 +1,77 @@
+// Package loader holds the dataloaders of a GraphQL request, batching
+// and caching the loads of the albums, their revisions and the users.
+package loader
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/dataloader"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// wait is how long the keys are collected before a batch is fetched.
+	wait = 2 * time.Millisecond
+
+	// maxBatch bounds the keys of a batch.
+	maxBatch = 100
+
+	// parallelism bounds the use case calls of a batch of revisions
+	// running at once.
+	parallelism = 8
+)
+
+// Loaders are the dataloaders of a request. The albums and the users
+// of a batch are found at once, the revisions of an album being found
+// one album at a time, in parallel. Every key is loaded once per
+// request.
+type Loaders struct {
+	Albums    *dataloader.Loader[uuid.UUID, *domain.Albums]
+	Revisions *dataloader.Loader[uuid.UUID, []*domain.AlbumRevision]
+	Users     *dataloader.Loader[uuid.UUID, *domain.UsersList]
+}
+
+type loadersKey struct{}
+
+// New creates the loaders of the request of ctx.
+func New(ctx context.Context, albums domain.AlbumsUseCase, users domain.UsersUseCase) *Loaders {
+	return &Loaders{
+		Albums: dataloader.New(ctx,
+			dataloader.Batch(albums.FindByIDs, albumUUID, domain.ErrResourceNotFound), wait, maxBatch),
+		Revisions: dataloader.New(ctx,
+			dataloader.PerKey(albums.FindRevisions, parallelism), wait, maxBatch),
+		Users: dataloader.New(ctx,
+			dataloader.Batch(users.FindByIDs, userUUID, domain.ErrResourceNotFound), wait, maxBatch),
+	}
+}
+
+func albumUUID(album *domain.Albums) uuid.UUID {
+	return album.UUID
+}
+
+func userUUID(user *domain.UsersList) uuid.UUID {
+	return user.UUID
+}
+
+// Middleware adds new loaders to the context of every request.
+func Middleware(albums domain.AlbumsUseCase, users domain.UsersUseCase) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := NewContext(r.Context(), New(r.Context(), albums, users))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// NewContext returns a copy of ctx carrying loaders.
+func NewContext(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// FromContext returns the loaders carried by ctx.
+func FromContext(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
@@ -0,0 +1,20 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+type AlbumInput struct {
+	Name    string  `json:"name"`
+	Length  int     `json:"length"`
+	Barcode *string `json:"barcode,omitempty"`
+}
+
+type CreateUserInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type UpdateUserInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// MarshalUUID writes the IDs, which are UUIDs.
+func MarshalUUID(id uuid.UUID) graphql.Marshaler {
+	return graphql.MarshalUUID(id)
+}
+
+// UnmarshalUUID reads an ID, an invalid one being an error of the
+// query rather than of its resolution.
+func UnmarshalUUID(v interface{}) (uuid.UUID, error) {
+	id, err := graphql.UnmarshalUUID(v)
+	if err != nil {
+		return uuid.Nil, gqlerror.Errorf("%s is not a valid ID", fmt.Sprint(v))
+	}
+
+	return id, nil
+}
@@ -0,0 +1,137 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	loader "hexagony/app/graphql/loaders"
+	"hexagony/app/graphql/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type albumInput struct {
+	Name    string `json:"name" validate:"required"`
+	Length  int    `json:"length" validate:"required,album_length"`
+	Barcode string `json:"barcode,omitempty" validate:"omitempty,barcode"`
+}
+
+func newAlbumInput(input model.AlbumInput) albumInput {
+	payload := albumInput{Name: input.Name, Length: input.Length}
+	if input.Barcode != nil {
+		payload.Barcode = *input.Barcode
+	}
+
+	return payload
+}
+
+// Album is null when the album does not exist.
+func (r *queryResolver) Album(ctx context.Context, id uuid.UUID) (*domain.Albums, error) {
+	album, err := loader.FromContext(ctx).Albums.Load(ctx, id)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return album, nil
+}
+
+func (r *queryResolver) Albums(ctx context.Context) ([]*domain.Albums, error) {
+	return r.AlbumsUseCase.FindAll(ctx)
+}
+
+func (r *queryResolver) DeletedAlbums(ctx context.Context) ([]*domain.Albums, error) {
+	return r.AlbumsUseCase.FindTrash(ctx)
+}
+
+func (r *albumResolver) Revisions(ctx context.Context, album *domain.Albums) ([]*domain.AlbumRevision, error) {
+	return loader.FromContext(ctx).Revisions.Load(ctx, album.UUID)
+}
+
+// Author is null for the writes made without a user
+// and once the user has been purged.
+func (r *albumRevisionResolver) Author(ctx context.Context, revision *domain.AlbumRevision) (*domain.UsersList, error) {
+	if revision.ActorUUID == nil {
+		return nil, nil
+	}
+
+	user, err := loader.FromContext(ctx).Users.Load(ctx, *revision.ActorUUID)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *mutationResolver) CreateAlbum(ctx context.Context, input model.AlbumInput) (*domain.Albums, error) {
+	payload := newAlbumInput(input)
+
+	if err := r.bind(ctx, payload); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	album := domain.Albums{
+		UUID:      uuid.New(),
+		Name:      payload.Name,
+		Length:    payload.Length,
+		Barcode:   payload.Barcode,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := r.AlbumsUseCase.Add(ctx, &album); err != nil {
+		return nil, err
+	}
+
+	return &album, nil
+}
+
+// UpdateAlbum returns the album as written, read again
+// rather than through the loader of the request.
+func (r *mutationResolver) UpdateAlbum(ctx context.Context, id uuid.UUID, input model.AlbumInput, version *int) (*domain.Albums, error) {
+	payload := newAlbumInput(input)
+
+	if err := r.bind(ctx, payload); err != nil {
+		return nil, err
+	}
+
+	expected, err := r.version(version)
+	if err != nil {
+		return nil, err
+	}
+
+	album := domain.Albums{
+		Name:      payload.Name,
+		Length:    payload.Length,
+		Barcode:   payload.Barcode,
+		Version:   expected,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := r.AlbumsUseCase.Update(ctx, id, &album); err != nil {
+		return nil, err
+	}
+
+	return r.AlbumsUseCase.FindByID(ctx, id)
+}
+
+func (r *mutationResolver) DeleteAlbum(ctx context.Context, id uuid.UUID, version *int) (bool, error) {
+	expected, err := r.version(version)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.AlbumsUseCase.Delete(ctx, id, expected); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,96 @@
+// Package resolver resolves the GraphQL schema with the use cases, the
+// counterpart of the HTTP controllers. The resources referenced by
+// others are read through the dataloaders of the request.
+package resolver
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/graphql/generated"
+	"hexagony/config"
+	"hexagony/libs/problem"
+	"hexagony/libs/validation"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// errVersionRequired refuses the unconditioned writes, the
+// counterpart of a missing If-Match header.
+var errVersionRequired = domain.NewError(domain.KindPreconditionRequired, domain.ErrPreconditionRequired.Code,
+	"the mutation must be conditioned with the version")
+
+type Resolver struct {
+	AlbumsUseCase domain.AlbumsUseCase
+	UsersUseCase  domain.UsersUseCase
+	Validator     validation.Validator
+	Concurrency   config.Concurrency
+}
+
+type queryResolver struct{ *Resolver }
+
+type mutationResolver struct{ *Resolver }
+
+type albumResolver struct{ *Resolver }
+
+type albumRevisionResolver struct{ *Resolver }
+
+func (r *Resolver) Query() generated.QueryResolver {
+	return &queryResolver{r}
+}
+
+func (r *Resolver) Mutation() generated.MutationResolver {
+	return &mutationResolver{r}
+}
+
+func (r *Resolver) Album() generated.AlbumResolver {
+	return &albumResolver{r}
+}
+
+func (r *Resolver) AlbumRevision() generated.AlbumRevisionResolver {
+	return &albumRevisionResolver{r}
+}
+
+// ValidationError is the error of an invalid input, with its fields.
+type ValidationError struct {
+	Params []*problem.InvalidParam
+}
+
+func (e *ValidationError) Error() string {
+	return domain.ErrValidation.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return domain.ErrValidation
+}
+
+// bind validates the input, in the language asked by the client.
+func (r *Resolver) bind(ctx context.Context, input interface{}) error {
+	if err := r.Validator.BindStruct(ctx, input); err != nil {
+		return &ValidationError{Params: r.Validator.InvalidParams(err, acceptLanguage(ctx))}
+	}
+
+	return nil
+}
+
+// version returns the version a write is conditioned on, refusing
+// the unconditioned writes when the configuration requires it.
+func (r *Resolver) version(version *int) (int, error) {
+	if version == nil {
+		if r.Concurrency.RequireIfMatch {
+			return 0, errVersionRequired
+		}
+
+		return 0, nil
+	}
+
+	return *version, nil
+}
+
+// acceptLanguage returns the Accept-Language header of the request.
+func acceptLanguage(ctx context.Context) string {
+	if !graphql.HasOperationContext(ctx) {
+		return ""
+	}
+
+	return graphql.GetOperationContext(ctx).Headers.Get("Accept-Language")
+}
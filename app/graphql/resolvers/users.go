@@ -0,0 +1,125 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	loader "hexagony/app/graphql/loaders"
+	"hexagony/app/graphql/model"
+	"hexagony/libs/crypto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type createUserInput struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required,gte=8"`
+}
+
+type updateUserInput struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required"`
+}
+
+// User is null when the user does not exist.
+func (r *queryResolver) User(ctx context.Context, id uuid.UUID) (*domain.UsersList, error) {
+	user, err := loader.FromContext(ctx).Users.Load(ctx, id)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *queryResolver) Users(ctx context.Context) ([]*domain.UsersList, error) {
+	return r.UsersUseCase.FindAll(ctx)
+}
+
+func (r *queryResolver) DeletedUsers(ctx context.Context) ([]*domain.UsersList, error) {
+	return r.UsersUseCase.FindTrash(ctx)
+}
+
+// Me is the user of the token, null once deleted.
+func (r *queryResolver) Me(ctx context.Context) (*domain.UsersList, error) {
+	actor, ok := domain.ActorFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrTokenEmpty
+	}
+
+	return r.User(ctx, actor.UUID)
+}
+
+func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUserInput) (*domain.UsersList, error) {
+	payload := createUserInput{Name: input.Name, Email: input.Email, Password: input.Password}
+
+	if err := r.bind(ctx, payload); err != nil {
+		return nil, err
+	}
+
+	hashPass, err := crypto.New().HashPassword(payload.Password, 10)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUsersHashPassword, err)
+	}
+
+	now := time.Now()
+
+	user := domain.Users{
+		UUID:      uuid.New(),
+		Name:      payload.Name,
+		Email:     payload.Email,
+		Password:  hashPass,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := r.UsersUseCase.Add(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return r.UsersUseCase.FindByID(ctx, user.UUID)
+}
+
+func (r *mutationResolver) UpdateUser(ctx context.Context, id uuid.UUID, input model.UpdateUserInput, version *int) (*domain.UsersList, error) {
+	payload := updateUserInput{Name: input.Name, Email: input.Email}
+
+	if err := r.bind(ctx, payload); err != nil {
+		return nil, err
+	}
+
+	expected, err := r.version(version)
+	if err != nil {
+		return nil, err
+	}
+
+	user := domain.Users{
+		Name:      payload.Name,
+		Email:     payload.Email,
+		Version:   expected,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := r.UsersUseCase.Update(ctx, id, &user); err != nil {
+		return nil, err
+	}
+
+	return r.UsersUseCase.FindByID(ctx, id)
+}
+
+func (r *mutationResolver) DeleteUser(ctx context.Context, id uuid.UUID, version *int) (bool, error) {
+	expected, err := r.version(version)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.UsersUseCase.Delete(ctx, id, expected); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+// DepthLimitExtension refuses the operations whose fields are nested
+// deeper than Limit, the fragments counting as the fields they spread.
+// The introspection fields are not counted.
+type DepthLimitExtension struct {
+	Limit int
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = DepthLimitExtension{}
+
+// DepthLimit limits the depth of the operations to limit.
+func DepthLimit(limit int) DepthLimitExtension {
+	return DepthLimitExtension{Limit: limit}
+}
+
+func (DepthLimitExtension) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (DepthLimitExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d DepthLimitExtension) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return nil
+	}
+
+	if depth := depth(op.SelectionSet); depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+
+	return nil
+}
+
+// depth returns the depth of the deepest field of set, the
+// fragments having been checked for cycles by the validation.
+func depth(set ast.SelectionSet) int {
+	deepest := 0
+
+	for _, selection := range set {
+		var d int
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + depth(s.SelectionSet)
+		case *ast.InlineFragment:
+			d = depth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				d = depth(s.Definition.SelectionSet)
+			}
+		}
+
+		deepest = max(deepest, d)
+	}
+
+	return deepest
+}
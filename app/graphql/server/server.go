@@ -0,0 +1,109 @@
+// Package server serves the GraphQL schema over HTTP with the limits
+// of the operations and the mapping of the domain errors.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hexagony/app/graphql/generated"
+	resolver "hexagony/app/graphql/resolvers"
+	"hexagony/app/http/response"
+	"hexagony/config"
+	"hexagony/libs/clog"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// queryCacheSize is the number of parsed queries kept.
+const queryCacheSize = 1000
+
+// listSize is the number of items a list is counted with in the
+// complexity of an operation, the lists are not paginated.
+const listSize = 10
+
+// New returns the handler of the GraphQL operations, sent with POST
+// or, for the queries, GET. The operations deeper or more complex
+// than the limits of cfg are refused before they run.
+func New(r *resolver.Resolver, cfg config.GraphQL) http.Handler {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{
+		Resolvers:  r,
+		Complexity: complexity(),
+	}))
+
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New(queryCacheSize))
+
+	if cfg.Introspection {
+		srv.Use(extension.Introspection{})
+	}
+
+	srv.Use(DepthLimit(cfg.MaxDepth))
+	srv.Use(extension.FixedComplexityLimit(cfg.MaxComplexity))
+
+	srv.SetErrorPresenter(presentError)
+	srv.SetRecoverFunc(func(_ context.Context, p interface{}) error {
+		return fmt.Errorf("panic: %v", p)
+	})
+
+	return srv
+}
+
+// complexity counts the fields of the lists listSize times, a field
+// costing one along with the fields it selects.
+func complexity() generated.ComplexityRoot {
+	list := func(childComplexity int) int {
+		return 1 + listSize*childComplexity
+	}
+
+	var c generated.ComplexityRoot
+
+	c.Query.Albums = list
+	c.Query.DeletedAlbums = list
+	c.Query.Users = list
+	c.Query.DeletedUsers = list
+	c.Album.Revisions = list
+	c.AlbumRevision.Changes = list
+
+	return c
+}
+
+// presentError reports the domain errors with their message and, in
+// the extensions, their code and the HTTP status they are reported
+// with by the REST API. The errors of the GraphQL layer, such as an
+// invalid query, are kept. Any other error is an internal error.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) && gqlErr.Err == nil {
+		return gqlErr
+	}
+
+	p := response.FromError(err)
+
+	if p.Status >= http.StatusInternalServerError {
+		clog.ErrorContext(ctx, err, p.Detail)
+	} else {
+		clog.FromContext(ctx).Warn().Ctx(ctx).Err(err).Msg(p.Detail)
+	}
+
+	presented := gqlerror.WrapPath(graphql.GetPath(ctx), err)
+	presented.Message = p.Detail
+	presented.Extensions = map[string]interface{}{
+		"code":   p.Code,
+		"status": p.Status,
+	}
+
+	var validationErr *resolver.ValidationError
+	if errors.As(err, &validationErr) {
+		presented.Extensions["invalid_params"] = validationErr.Params
+	}
+
+	return presented
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	loader "hexagony/app/graphql/loaders"
+	resolver "hexagony/app/graphql/resolvers"
+	controller "hexagony/app/http/controllers"
+	"hexagony/config"
+	"hexagony/libs/validation"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+var testLimits = config.GraphQL{MaxDepth: 8, MaxComplexity: 500, Introspection: true}
+
+func newTestServer(t *testing.T, albums *mocks.AlbumUseCase, users *mocks.UserUseCase, cfg config.GraphQL) func(query string, variables map[string]interface{}) graphqlResponse {
+	v, err := validation.New(controller.ValidationRules...)
+	assert.NoError(t, err)
+
+	handler := New(
+		&resolver.Resolver{AlbumsUseCase: albums, UsersUseCase: users, Validator: v},
+		cfg,
+	)
+
+	return func(query string, variables map[string]interface{}) graphqlResponse {
+		payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		ctx := domain.NewActorContext(req.Context(), domain.Actor{UUID: uuid.New(), Email: "john@example.com"})
+		ctx = loader.NewContext(ctx, loader.New(ctx, albums, users))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(ctx))
+
+		var body graphqlResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+
+		return body
+	}
+}
+
+func TestAlbumWithRevisions(t *testing.T) {
+	now := time.Now()
+	author := domain.UsersList{UUID: uuid.New(), Name: "John", Email: "john@example.com", CreatedAt: now, UpdatedAt: now}
+	album := domain.Albums{UUID: uuid.New(), Name: "Load", Length: 79, Version: 2, CreatedAt: now, UpdatedAt: now}
+
+	revisions := []*domain.AlbumRevision{
+		{AlbumUUID: album.UUID, Revision: 1, Action: domain.RevisionCreated, ActorUUID: &author.UUID, CreatedAt: now},
+		{AlbumUUID: album.UUID, Revision: 2, Action: domain.RevisionUpdated, ActorUUID: &author.UUID, CreatedAt: now,
+			Changes: []domain.FieldChange{{Field: "name", From: "St. Anger", To: "Load"}}},
+	}
+
+	albums := new(mocks.AlbumUseCase)
+	albums.On("FindByIDs", mock.Anything, []uuid.UUID{album.UUID}).Return([]*domain.Albums{&album}, nil).Once()
+	albums.On("FindRevisions", mock.Anything, album.UUID).Return(revisions, nil).Once()
+
+	users := new(mocks.UserUseCase)
+	users.On("FindByIDs", mock.Anything, []uuid.UUID{author.UUID}).Return([]*domain.UsersList{&author}, nil).Once()
+
+	send := newTestServer(t, albums, users, testLimits)
+
+	body := send(`query ($id: ID!) {
+		album(id: $id) { name revisions { revision author { name } changes { field from to } } }
+		again: album(id: $id) { version }
+	}`, map[string]interface{}{"id": album.UUID})
+
+	assert.Empty(t, body.Errors)
+	assert.JSONEq(t, `{"name": "Load", "revisions": [
+		{"revision": 1, "author": {"name": "John"}, "changes": []},
+		{"revision": 2, "author": {"name": "John"}, "changes": [{"field": "name", "from": "St. Anger", "to": "Load"}]}
+	]}`, string(body.Data["album"]))
+	assert.JSONEq(t, `{"version": 2}`, string(body.Data["again"]))
+
+	// every album, revision list and user is found once
+	albums.AssertExpectations(t)
+	users.AssertExpectations(t)
+}
+
+func TestAlbumsBatched(t *testing.T) {
+	now := time.Now()
+	first := domain.Albums{UUID: uuid.New(), Name: "Load", Length: 79, Version: 1, CreatedAt: now, UpdatedAt: now}
+	second := domain.Albums{UUID: uuid.New(), Name: "Reload", Length: 76, Version: 1, CreatedAt: now, UpdatedAt: now}
+
+	albums := new(mocks.AlbumUseCase)
+	albums.On("FindByIDs", mock.Anything, mock.MatchedBy(func(ids []uuid.UUID) bool {
+		return assert.ElementsMatch(t, []uuid.UUID{first.UUID, second.UUID}, ids)
+	})).Return([]*domain.Albums{&second, &first}, nil).Once()
+
+	send := newTestServer(t, albums, new(mocks.UserUseCase), testLimits)
+
+	body := send(`query ($first: ID!, $second: ID!) {
+		first: album(id: $first) { name }
+		second: album(id: $second) { name }
+	}`, map[string]interface{}{"first": first.UUID, "second": second.UUID})
+
+	assert.Empty(t, body.Errors)
+	assert.JSONEq(t, `{"name": "Load"}`, string(body.Data["first"]))
+	assert.JSONEq(t, `{"name": "Reload"}`, string(body.Data["second"]))
+
+	// both albums are found by a single call
+	albums.AssertExpectations(t)
+}
+
+func TestAlbumNotFound(t *testing.T) {
+	albums := new(mocks.AlbumUseCase)
+	albums.On("FindByIDs", mock.Anything, mock.Anything).Return(nil, nil)
+
+	send := newTestServer(t, albums, new(mocks.UserUseCase), testLimits)
+
+	body := send(`{ album(id: "`+uuid.NewString()+`") { name } }`, nil)
+	assert.Empty(t, body.Errors)
+	assert.Equal(t, "null", string(body.Data["album"]))
+
+	body = send(`{ album(id: "nope") { name } }`, nil)
+	if assert.Len(t, body.Errors, 1) {
+		assert.Equal(t, "nope is not a valid ID", body.Errors[0].Message)
+	}
+}
+
+func TestMutationErrors(t *testing.T) {
+	t.Run("invalid input", func(t *testing.T) {
+		body := newTestServer(t, new(mocks.AlbumUseCase), new(mocks.UserUseCase), testLimits)(
+			`mutation { createAlbum(input: {name: "Load", length: 0}) { id } }`, nil)
+
+		if assert.Len(t, body.Errors, 1) {
+			assert.Equal(t, domain.ErrValidation.Message, body.Errors[0].Message)
+			assert.Equal(t, domain.ErrValidation.Code, body.Errors[0].Extensions["code"])
+			assert.Equal(t, float64(http.StatusBadRequest), body.Errors[0].Extensions["status"])
+			assert.Len(t, body.Errors[0].Extensions["invalid_params"], 1)
+		}
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		albums := new(mocks.AlbumUseCase)
+		albums.On("Delete", mock.Anything, mock.Anything, 3).Return(domain.ErrPreconditionFailed)
+
+		body := newTestServer(t, albums, new(mocks.UserUseCase), testLimits)(
+			`mutation { deleteAlbum(id: "`+uuid.NewString()+`", version: 3) }`, nil)
+
+		if assert.Len(t, body.Errors, 1) {
+			assert.Equal(t, []interface{}{"deleteAlbum"}, body.Errors[0].Path)
+			assert.Equal(t, domain.ErrPreconditionFailed.Code, body.Errors[0].Extensions["code"])
+			assert.Equal(t, float64(http.StatusPreconditionFailed), body.Errors[0].Extensions["status"])
+		}
+		albums.AssertExpectations(t)
+	})
+
+	t.Run("internal error is not exposed", func(t *testing.T) {
+		albums := new(mocks.AlbumUseCase)
+		albums.On("FindAll", mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		body := newTestServer(t, albums, new(mocks.UserUseCase), testLimits)(`{ albums { id } }`, nil)
+
+		if assert.Len(t, body.Errors, 1) {
+			assert.Equal(t, "Internal Server Error", body.Errors[0].Message)
+			assert.Equal(t, "internal_error", body.Errors[0].Extensions["code"])
+		}
+	})
+}
+
+func TestLimits(t *testing.T) {
+	albums := new(mocks.AlbumUseCase)
+	albums.On("FindByIDs", mock.Anything, mock.Anything).Return(nil, nil)
+
+	send := newTestServer(t, albums, new(mocks.UserUseCase),
+		config.GraphQL{MaxDepth: 3, MaxComplexity: 100, Introspection: true})
+
+	t.Run("depth", func(t *testing.T) {
+		body := send(`
+			query ($id: ID!) { album(id: $id) { ...revisions } }
+			fragment revisions on Album { revisions { revision } }
+		`, map[string]interface{}{"id": uuid.New()})
+		assert.Empty(t, body.Errors)
+
+		body = send(`{ album(id: "`+uuid.NewString()+`") { revisions { author { name } } } }`, nil)
+		if assert.Len(t, body.Errors, 1) {
+			assert.Equal(t, errDepthLimit, body.Errors[0].Extensions["code"])
+		}
+	})
+
+	t.Run("complexity", func(t *testing.T) {
+		body := send(`{ albums { id revisions { revision } } }`, nil)
+		if assert.Len(t, body.Errors, 1) {
+			assert.Equal(t, "COMPLEXITY_LIMIT_EXCEEDED", body.Errors[0].Extensions["code"])
+		}
+	})
+
+	t.Run("introspection is not counted", func(t *testing.T) {
+		body := send(`{ __schema { types { name fields { name type { name ofType { name ofType { name } } } } } } }`, nil)
+		assert.Empty(t, body.Errors)
+	})
+}
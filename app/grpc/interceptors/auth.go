@@ -0,0 +1,97 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/bearer"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryAuth checks that the calls carry a valid token in the
+// authorization metadata, "Bearer <token>", the same as the HTTP API.
+// The user of the token is added to the context as the actor. The
+// methods whose full name starts with one of public are not checked.
+func UnaryAuth(jwt config.JWT, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if matches(info.FullMethod, public) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, jwt.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth is UnaryAuth for the streams.
+func StreamAuth(jwt config.JWT, public ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if matches(info.FullMethod, public) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authenticate(ss.Context(), jwt.Secret)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream is a stream carrying another context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// matches reports whether the full name of method starts with one of prefixes.
+func matches(method string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authenticate returns ctx carrying the user of the token of the
+// authorization metadata as the actor.
+func authenticate(ctx context.Context, secret string) (context.Context, error) {
+	var authorization string
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+
+	token, err := bearer.Token(authorization)
+	if errors.Is(err, bearer.ErrEmpty) {
+		return nil, domain.ErrTokenEmpty
+	}
+	if err != nil {
+		return nil, domain.ErrTokenMalformed
+	}
+
+	claims, err := bearer.Verify(token, secret)
+	if errors.Is(err, bearer.ErrInvalid) {
+		return nil, domain.ErrTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrTokenUnexpected, err)
+	}
+
+	return domain.NewActorContext(ctx, domain.Actor{UUID: claims.UUID, Email: claims.Email}), nil
+}
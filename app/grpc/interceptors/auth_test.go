@@ -0,0 +1,45 @@
+package interceptors
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/bearer"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryAuth(t *testing.T) {
+	userUUID := uuid.New()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, bearer.Claims{UUID: userUUID, Email: "john@example.com"}).
+		SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	interceptor := UnaryAuth(config.JWT{Secret: "secret"}, "/hexagony.v1.AuthService/")
+
+	var actor domain.Actor
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		actor, _ = domain.ActorFromContext(ctx)
+		return nil, nil
+	}
+
+	call := func(method string, md ...string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(md...))
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	assert.ErrorIs(t, call("/hexagony.v1.AlbumsService/ListAlbums"), domain.ErrTokenEmpty)
+	assert.ErrorIs(t, call("/hexagony.v1.AlbumsService/ListAlbums", "authorization", token), domain.ErrTokenMalformed)
+	assert.ErrorIs(t, call("/hexagony.v1.AlbumsService/ListAlbums", "authorization", "Bearer nope"), domain.ErrTokenUnexpected)
+	assert.NoError(t, call("/hexagony.v1.AuthService/Authenticate"))
+
+	assert.NoError(t, call("/hexagony.v1.AlbumsService/ListAlbums", "authorization", "Bearer "+token))
+	assert.Equal(t, domain.Actor{UUID: userUUID, Email: "john@example.com"}, actor)
+}
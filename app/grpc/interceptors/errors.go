@@ -0,0 +1,112 @@
+// Package interceptors holds the gRPC interceptors, the counterpart
+// of the HTTP middlewares.
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/libs/clog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details, their reason
+// being the code of the domain error.
+const ErrorDomain = "hexagony"
+
+var codesByKind = map[domain.Kind]codes.Code{
+	domain.KindInternal:             codes.Internal,
+	domain.KindInvalid:              codes.InvalidArgument,
+	domain.KindUnauthorized:         codes.Unauthenticated,
+	domain.KindForbidden:            codes.PermissionDenied,
+	domain.KindNotFound:             codes.NotFound,
+	domain.KindConflict:             codes.AlreadyExists,
+	domain.KindUnprocessable:        codes.FailedPrecondition,
+	domain.KindUnsupported:          codes.InvalidArgument,
+	domain.KindPreconditionFailed:   codes.Aborted,
+	domain.KindPreconditionRequired: codes.FailedPrecondition,
+	domain.KindRateLimited:          codes.ResourceExhausted,
+	domain.KindTooLarge:             codes.ResourceExhausted,
+}
+
+// Code returns the gRPC code matching err.
+func Code(err error) codes.Code {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		if code, ok := codesByKind[domainErr.Kind]; ok {
+			return code
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+
+	return codes.Internal
+}
+
+// Status returns the status err is reported with. Only the message
+// of the domain error is exposed, along with its code as the reason
+// of an ErrorInfo. Errors outside the domain are internal errors.
+func Status(err error) *status.Status {
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	code := Code(err)
+
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		return status.New(code, code.String())
+	}
+
+	s := status.New(code, domainErr.Message)
+	if detailed, err := s.WithDetails(&errdetails.ErrorInfo{Reason: domainErr.Code, Domain: ErrorDomain}); err == nil {
+		return detailed
+	}
+
+	return s
+}
+
+// UnaryErrors logs the errors of the calls and converts them to statuses.
+func UnaryErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, report(ctx, info.FullMethod, err)
+		}
+
+		return resp, nil
+	}
+}
+
+// StreamErrors logs the errors of the streams and converts them to statuses.
+func StreamErrors() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return report(ss.Context(), info.FullMethod, err)
+		}
+
+		return nil
+	}
+}
+
+func report(ctx context.Context, method string, err error) error {
+	s := Status(err)
+
+	switch s.Code() {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		clog.ErrorContext(ctx, err, method+": "+s.Message())
+	default:
+		clog.FromContext(ctx).Warn().Ctx(ctx).Err(err).Msg(method + ": " + s.Message())
+	}
+
+	return s.Err()
+}
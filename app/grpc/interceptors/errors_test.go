@@ -0,0 +1,65 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{domain.ErrResourceNotFound, codes.NotFound},
+		{domain.ErrTokenInvalid, codes.Unauthenticated},
+		{domain.ErrAdminRequired, codes.PermissionDenied},
+		{domain.ErrAlbumsImportTrashed, codes.AlreadyExists},
+		{domain.ErrPreconditionFailed, codes.Aborted},
+		{domain.ErrPreconditionRequired, codes.FailedPrecondition},
+		{domain.ErrRateLimited, codes.ResourceExhausted},
+		{fmt.Errorf("wrapped: %w", domain.ErrValidation), codes.InvalidArgument},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{errors.New("boom"), codes.Internal},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.code, Code(test.err), test.err.Error())
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("domain error", func(t *testing.T) {
+		s := Status(fmt.Errorf("%w: the driver failed", domain.ErrResourceNotFound))
+
+		assert.Equal(t, codes.NotFound, s.Code())
+		assert.Equal(t, domain.ErrResourceNotFound.Message, s.Message())
+
+		if assert.Len(t, s.Details(), 1) {
+			info := s.Details()[0].(*errdetails.ErrorInfo)
+			assert.Equal(t, domain.ErrResourceNotFound.Code, info.GetReason())
+			assert.Equal(t, ErrorDomain, info.GetDomain())
+		}
+	})
+
+	t.Run("unknown error is not exposed", func(t *testing.T) {
+		s := Status(errors.New("pq: password authentication failed"))
+
+		assert.Equal(t, codes.Internal, s.Code())
+		assert.Equal(t, "Internal", s.Message())
+	})
+
+	t.Run("status is kept", func(t *testing.T) {
+		s := Status(status.Error(codes.InvalidArgument, "bad"))
+
+		assert.Equal(t, codes.InvalidArgument, s.Code())
+		assert.Equal(t, "bad", s.Message())
+	})
+}
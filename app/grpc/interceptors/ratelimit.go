@@ -0,0 +1,59 @@
+package interceptors
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/clog"
+	"hexagony/libs/ratelimit"
+	"math"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// UnaryRateLimit limits the calls of the methods whose full name
+// starts with one of methods with policy, counted by peer address
+// under group, the same as the HTTP routes of the group. The denied
+// calls fail with ErrRateLimited and a retry-after header, in seconds.
+// The calls are let through when the limiter fails.
+func UnaryRateLimit(limiter *ratelimit.Limiter, group string, policy ratelimit.Policy, methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !policy.Enabled() || !matches(info.FullMethod, methods) {
+			return handler(ctx, req)
+		}
+
+		result, err := limiter.Allow(ctx, group+":ip:"+peerAddress(ctx), policy)
+		if err != nil {
+			clog.ErrorContext(ctx, err, "failed to rate limit the call")
+			return handler(ctx, req)
+		}
+
+		if !result.Allowed {
+			retryAfter := strconv.FormatInt(int64(math.Ceil(result.RetryAfter.Seconds())), 10)
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+
+			return nil, domain.ErrRateLimited
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// peerAddress returns the IP address of the client of the call,
+// without its port.
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
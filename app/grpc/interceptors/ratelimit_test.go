@@ -0,0 +1,43 @@
+package interceptors
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/ratelimit"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func TestUnaryRateLimit(t *testing.T) {
+	policy := ratelimit.Policy{Rate: 2, Period: time.Minute, Burst: 2, Key: ratelimit.KeyIP}
+	interceptor := UnaryRateLimit(ratelimit.New(ratelimit.NewMemoryStore()), "auth", policy, "/hexagony.v1.AuthService/")
+
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	call := func(method, addr string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 40000 + len(method)},
+		})
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	const authenticate = "/hexagony.v1.AuthService/Authenticate"
+
+	assert.NoError(t, call(authenticate, "10.0.0.1"))
+	assert.NoError(t, call(authenticate, "10.0.0.1"))
+	assert.ErrorIs(t, call(authenticate, "10.0.0.1"), domain.ErrRateLimited)
+
+	// the other peers and the other methods are not limited
+	assert.NoError(t, call(authenticate, "10.0.0.2"))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, call("/hexagony.v1.AlbumsService/ListAlbums", "10.0.0.1"))
+	}
+}
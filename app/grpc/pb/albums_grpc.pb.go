@@ -0,0 +1,560 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.24.4
+// source: hexagony/v1/albums.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AlbumsService_ListAlbums_FullMethodName           = "/hexagony.v1.AlbumsService/ListAlbums"
+	AlbumsService_GetAlbum_FullMethodName             = "/hexagony.v1.AlbumsService/GetAlbum"
+	AlbumsService_CreateAlbum_FullMethodName          = "/hexagony.v1.AlbumsService/CreateAlbum"
+	AlbumsService_UpdateAlbum_FullMethodName          = "/hexagony.v1.AlbumsService/UpdateAlbum"
+	AlbumsService_PatchAlbum_FullMethodName           = "/hexagony.v1.AlbumsService/PatchAlbum"
+	AlbumsService_DeleteAlbum_FullMethodName          = "/hexagony.v1.AlbumsService/DeleteAlbum"
+	AlbumsService_ListDeletedAlbums_FullMethodName    = "/hexagony.v1.AlbumsService/ListDeletedAlbums"
+	AlbumsService_RestoreAlbum_FullMethodName         = "/hexagony.v1.AlbumsService/RestoreAlbum"
+	AlbumsService_ListAlbumRevisions_FullMethodName   = "/hexagony.v1.AlbumsService/ListAlbumRevisions"
+	AlbumsService_RestoreAlbumRevision_FullMethodName = "/hexagony.v1.AlbumsService/RestoreAlbumRevision"
+	AlbumsService_BulkAlbums_FullMethodName           = "/hexagony.v1.AlbumsService/BulkAlbums"
+	AlbumsService_ExportAlbums_FullMethodName         = "/hexagony.v1.AlbumsService/ExportAlbums"
+)
+
+// AlbumsServiceClient is the client API for AlbumsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AlbumsServiceClient interface {
+	ListAlbums(ctx context.Context, in *ListAlbumsRequest, opts ...grpc.CallOption) (*ListAlbumsResponse, error)
+	GetAlbum(ctx context.Context, in *GetAlbumRequest, opts ...grpc.CallOption) (*Album, error)
+	CreateAlbum(ctx context.Context, in *CreateAlbumRequest, opts ...grpc.CallOption) (*Album, error)
+	UpdateAlbum(ctx context.Context, in *UpdateAlbumRequest, opts ...grpc.CallOption) (*Album, error)
+	// PatchAlbum changes the fields that are set.
+	PatchAlbum(ctx context.Context, in *PatchAlbumRequest, opts ...grpc.CallOption) (*Album, error)
+	// DeleteAlbum moves the album to the trash.
+	DeleteAlbum(ctx context.Context, in *DeleteAlbumRequest, opts ...grpc.CallOption) (*DeleteAlbumResponse, error)
+	ListDeletedAlbums(ctx context.Context, in *ListDeletedAlbumsRequest, opts ...grpc.CallOption) (*ListAlbumsResponse, error)
+	RestoreAlbum(ctx context.Context, in *RestoreAlbumRequest, opts ...grpc.CallOption) (*Album, error)
+	// ListAlbumRevisions lists the revisions oldest first.
+	ListAlbumRevisions(ctx context.Context, in *ListAlbumRevisionsRequest, opts ...grpc.CallOption) (*ListAlbumRevisionsResponse, error)
+	// RestoreAlbumRevision writes back the fields of a revision.
+	RestoreAlbumRevision(ctx context.Context, in *RestoreAlbumRevisionRequest, opts ...grpc.CallOption) (*Album, error)
+	// BulkAlbums writes the operations in one transaction. An atomic
+	// bulk is rolled back as soon as an operation fails, otherwise only
+	// the failed operations are undone.
+	BulkAlbums(ctx context.Context, in *BulkAlbumsRequest, opts ...grpc.CallOption) (*BulkAlbumsResponse, error)
+	// ExportAlbums streams every album.
+	ExportAlbums(ctx context.Context, in *ExportAlbumsRequest, opts ...grpc.CallOption) (AlbumsService_ExportAlbumsClient, error)
+}
+
+type albumsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAlbumsServiceClient(cc grpc.ClientConnInterface) AlbumsServiceClient {
+	return &albumsServiceClient{cc}
+}
+
+func (c *albumsServiceClient) ListAlbums(ctx context.Context, in *ListAlbumsRequest, opts ...grpc.CallOption) (*ListAlbumsResponse, error) {
+	out := new(ListAlbumsResponse)
+	err := c.cc.Invoke(ctx, AlbumsService_ListAlbums_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) GetAlbum(ctx context.Context, in *GetAlbumRequest, opts ...grpc.CallOption) (*Album, error) {
+	out := new(Album)
+	err := c.cc.Invoke(ctx, AlbumsService_GetAlbum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) CreateAlbum(ctx context.Context, in *CreateAlbumRequest, opts ...grpc.CallOption) (*Album, error) {
+	out := new(Album)
+	err := c.cc.Invoke(ctx, AlbumsService_CreateAlbum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) UpdateAlbum(ctx context.Context, in *UpdateAlbumRequest, opts ...grpc.CallOption) (*Album, error) {
+	out := new(Album)
+	err := c.cc.Invoke(ctx, AlbumsService_UpdateAlbum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) PatchAlbum(ctx context.Context, in *PatchAlbumRequest, opts ...grpc.CallOption) (*Album, error) {
+	out := new(Album)
+	err := c.cc.Invoke(ctx, AlbumsService_PatchAlbum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) DeleteAlbum(ctx context.Context, in *DeleteAlbumRequest, opts ...grpc.CallOption) (*DeleteAlbumResponse, error) {
+	out := new(DeleteAlbumResponse)
+	err := c.cc.Invoke(ctx, AlbumsService_DeleteAlbum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) ListDeletedAlbums(ctx context.Context, in *ListDeletedAlbumsRequest, opts ...grpc.CallOption) (*ListAlbumsResponse, error) {
+	out := new(ListAlbumsResponse)
+	err := c.cc.Invoke(ctx, AlbumsService_ListDeletedAlbums_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) RestoreAlbum(ctx context.Context, in *RestoreAlbumRequest, opts ...grpc.CallOption) (*Album, error) {
+	out := new(Album)
+	err := c.cc.Invoke(ctx, AlbumsService_RestoreAlbum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) ListAlbumRevisions(ctx context.Context, in *ListAlbumRevisionsRequest, opts ...grpc.CallOption) (*ListAlbumRevisionsResponse, error) {
+	out := new(ListAlbumRevisionsResponse)
+	err := c.cc.Invoke(ctx, AlbumsService_ListAlbumRevisions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) RestoreAlbumRevision(ctx context.Context, in *RestoreAlbumRevisionRequest, opts ...grpc.CallOption) (*Album, error) {
+	out := new(Album)
+	err := c.cc.Invoke(ctx, AlbumsService_RestoreAlbumRevision_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) BulkAlbums(ctx context.Context, in *BulkAlbumsRequest, opts ...grpc.CallOption) (*BulkAlbumsResponse, error) {
+	out := new(BulkAlbumsResponse)
+	err := c.cc.Invoke(ctx, AlbumsService_BulkAlbums_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *albumsServiceClient) ExportAlbums(ctx context.Context, in *ExportAlbumsRequest, opts ...grpc.CallOption) (AlbumsService_ExportAlbumsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AlbumsService_ServiceDesc.Streams[0], AlbumsService_ExportAlbums_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &albumsServiceExportAlbumsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AlbumsService_ExportAlbumsClient interface {
+	Recv() (*Album, error)
+	grpc.ClientStream
+}
+
+type albumsServiceExportAlbumsClient struct {
+	grpc.ClientStream
+}
+
+func (x *albumsServiceExportAlbumsClient) Recv() (*Album, error) {
+	m := new(Album)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AlbumsServiceServer is the server API for AlbumsService service.
+// All implementations must embed UnimplementedAlbumsServiceServer
+// for forward compatibility
+type AlbumsServiceServer interface {
+	ListAlbums(context.Context, *ListAlbumsRequest) (*ListAlbumsResponse, error)
+	GetAlbum(context.Context, *GetAlbumRequest) (*Album, error)
+	CreateAlbum(context.Context, *CreateAlbumRequest) (*Album, error)
+	UpdateAlbum(context.Context, *UpdateAlbumRequest) (*Album, error)
+	// PatchAlbum changes the fields that are set.
+	PatchAlbum(context.Context, *PatchAlbumRequest) (*Album, error)
+	// DeleteAlbum moves the album to the trash.
+	DeleteAlbum(context.Context, *DeleteAlbumRequest) (*DeleteAlbumResponse, error)
+	ListDeletedAlbums(context.Context, *ListDeletedAlbumsRequest) (*ListAlbumsResponse, error)
+	RestoreAlbum(context.Context, *RestoreAlbumRequest) (*Album, error)
+	// ListAlbumRevisions lists the revisions oldest first.
+	ListAlbumRevisions(context.Context, *ListAlbumRevisionsRequest) (*ListAlbumRevisionsResponse, error)
+	// RestoreAlbumRevision writes back the fields of a revision.
+	RestoreAlbumRevision(context.Context, *RestoreAlbumRevisionRequest) (*Album, error)
+	// BulkAlbums writes the operations in one transaction. An atomic
+	// bulk is rolled back as soon as an operation fails, otherwise only
+	// the failed operations are undone.
+	BulkAlbums(context.Context, *BulkAlbumsRequest) (*BulkAlbumsResponse, error)
+	// ExportAlbums streams every album.
+	ExportAlbums(*ExportAlbumsRequest, AlbumsService_ExportAlbumsServer) error
+	mustEmbedUnimplementedAlbumsServiceServer()
+}
+
+// UnimplementedAlbumsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAlbumsServiceServer struct {
+}
+
+func (UnimplementedAlbumsServiceServer) ListAlbums(context.Context, *ListAlbumsRequest) (*ListAlbumsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAlbums not implemented")
+}
+func (UnimplementedAlbumsServiceServer) GetAlbum(context.Context, *GetAlbumRequest) (*Album, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAlbum not implemented")
+}
+func (UnimplementedAlbumsServiceServer) CreateAlbum(context.Context, *CreateAlbumRequest) (*Album, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAlbum not implemented")
+}
+func (UnimplementedAlbumsServiceServer) UpdateAlbum(context.Context, *UpdateAlbumRequest) (*Album, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAlbum not implemented")
+}
+func (UnimplementedAlbumsServiceServer) PatchAlbum(context.Context, *PatchAlbumRequest) (*Album, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchAlbum not implemented")
+}
+func (UnimplementedAlbumsServiceServer) DeleteAlbum(context.Context, *DeleteAlbumRequest) (*DeleteAlbumResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAlbum not implemented")
+}
+func (UnimplementedAlbumsServiceServer) ListDeletedAlbums(context.Context, *ListDeletedAlbumsRequest) (*ListAlbumsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedAlbums not implemented")
+}
+func (UnimplementedAlbumsServiceServer) RestoreAlbum(context.Context, *RestoreAlbumRequest) (*Album, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreAlbum not implemented")
+}
+func (UnimplementedAlbumsServiceServer) ListAlbumRevisions(context.Context, *ListAlbumRevisionsRequest) (*ListAlbumRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAlbumRevisions not implemented")
+}
+func (UnimplementedAlbumsServiceServer) RestoreAlbumRevision(context.Context, *RestoreAlbumRevisionRequest) (*Album, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreAlbumRevision not implemented")
+}
+func (UnimplementedAlbumsServiceServer) BulkAlbums(context.Context, *BulkAlbumsRequest) (*BulkAlbumsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkAlbums not implemented")
+}
+func (UnimplementedAlbumsServiceServer) ExportAlbums(*ExportAlbumsRequest, AlbumsService_ExportAlbumsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportAlbums not implemented")
+}
+func (UnimplementedAlbumsServiceServer) mustEmbedUnimplementedAlbumsServiceServer() {}
+
+// UnsafeAlbumsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AlbumsServiceServer will
+// result in compilation errors.
+type UnsafeAlbumsServiceServer interface {
+	mustEmbedUnimplementedAlbumsServiceServer()
+}
+
+func RegisterAlbumsServiceServer(s grpc.ServiceRegistrar, srv AlbumsServiceServer) {
+	s.RegisterService(&AlbumsService_ServiceDesc, srv)
+}
+
+func _AlbumsService_ListAlbums_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAlbumsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).ListAlbums(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_ListAlbums_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).ListAlbums(ctx, req.(*ListAlbumsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_GetAlbum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAlbumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).GetAlbum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_GetAlbum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).GetAlbum(ctx, req.(*GetAlbumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_CreateAlbum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAlbumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).CreateAlbum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_CreateAlbum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).CreateAlbum(ctx, req.(*CreateAlbumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_UpdateAlbum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAlbumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).UpdateAlbum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_UpdateAlbum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).UpdateAlbum(ctx, req.(*UpdateAlbumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_PatchAlbum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchAlbumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).PatchAlbum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_PatchAlbum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).PatchAlbum(ctx, req.(*PatchAlbumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_DeleteAlbum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAlbumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).DeleteAlbum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_DeleteAlbum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).DeleteAlbum(ctx, req.(*DeleteAlbumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_ListDeletedAlbums_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedAlbumsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).ListDeletedAlbums(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_ListDeletedAlbums_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).ListDeletedAlbums(ctx, req.(*ListDeletedAlbumsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_RestoreAlbum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreAlbumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).RestoreAlbum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_RestoreAlbum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).RestoreAlbum(ctx, req.(*RestoreAlbumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_ListAlbumRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAlbumRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).ListAlbumRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_ListAlbumRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).ListAlbumRevisions(ctx, req.(*ListAlbumRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_RestoreAlbumRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreAlbumRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).RestoreAlbumRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_RestoreAlbumRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).RestoreAlbumRevision(ctx, req.(*RestoreAlbumRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_BulkAlbums_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkAlbumsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlbumsServiceServer).BulkAlbums(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlbumsService_BulkAlbums_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlbumsServiceServer).BulkAlbums(ctx, req.(*BulkAlbumsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlbumsService_ExportAlbums_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportAlbumsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AlbumsServiceServer).ExportAlbums(m, &albumsServiceExportAlbumsServer{stream})
+}
+
+type AlbumsService_ExportAlbumsServer interface {
+	Send(*Album) error
+	grpc.ServerStream
+}
+
+type albumsServiceExportAlbumsServer struct {
+	grpc.ServerStream
+}
+
+func (x *albumsServiceExportAlbumsServer) Send(m *Album) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// AlbumsService_ServiceDesc is the grpc.ServiceDesc for AlbumsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AlbumsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hexagony.v1.AlbumsService",
+	HandlerType: (*AlbumsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAlbums",
+			Handler:    _AlbumsService_ListAlbums_Handler,
+		},
+		{
+			MethodName: "GetAlbum",
+			Handler:    _AlbumsService_GetAlbum_Handler,
+		},
+		{
+			MethodName: "CreateAlbum",
+			Handler:    _AlbumsService_CreateAlbum_Handler,
+		},
+		{
+			MethodName: "UpdateAlbum",
+			Handler:    _AlbumsService_UpdateAlbum_Handler,
+		},
+		{
+			MethodName: "PatchAlbum",
+			Handler:    _AlbumsService_PatchAlbum_Handler,
+		},
+		{
+			MethodName: "DeleteAlbum",
+			Handler:    _AlbumsService_DeleteAlbum_Handler,
+		},
+		{
+			MethodName: "ListDeletedAlbums",
+			Handler:    _AlbumsService_ListDeletedAlbums_Handler,
+		},
+		{
+			MethodName: "RestoreAlbum",
+			Handler:    _AlbumsService_RestoreAlbum_Handler,
+		},
+		{
+			MethodName: "ListAlbumRevisions",
+			Handler:    _AlbumsService_ListAlbumRevisions_Handler,
+		},
+		{
+			MethodName: "RestoreAlbumRevision",
+			Handler:    _AlbumsService_RestoreAlbumRevision_Handler,
+		},
+		{
+			MethodName: "BulkAlbums",
+			Handler:    _AlbumsService_BulkAlbums_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportAlbums",
+			Handler:       _AlbumsService_ExportAlbums_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "hexagony/v1/albums.proto",
+}
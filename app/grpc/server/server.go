@@ -0,0 +1,126 @@
+// Package server runs the gRPC server with its interceptors, the
+// health service and the server reflection, and a bounded graceful
+// stop.
+package server
+
+import (
+	"context"
+	"hexagony/app/grpc/interceptors"
+	"hexagony/config"
+	"hexagony/libs/clog"
+	"hexagony/libs/ratelimit"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// authMethods are the methods of the authentication, rate limited as
+// the /auth route.
+const authMethods = "/hexagony.v1.AuthService/"
+
+// publicMethods are called without a token: the authentication
+// itself, the health checks and the reflection.
+var publicMethods = []string{
+	authMethods,
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// Server wraps a grpc.Server.
+type Server struct {
+	srv             *grpc.Server
+	health          *health.Server
+	addr            string
+	shutdownTimeout time.Duration
+}
+
+// New creates a server checking the tokens with the JWT settings.
+// The authentication calls are limited by limiter with the auth
+// policy. The errors of the services are converted to statuses and
+// their panics to internal errors.
+func New(cfg config.GRPC, jwt config.JWT, limiter *ratelimit.Limiter, auth ratelimit.Policy, shutdownTimeout time.Duration) *Server {
+	s := &Server{
+		srv: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				interceptors.UnaryErrors(),
+				interceptors.UnaryRecovery(),
+				interceptors.UnaryRateLimit(limiter, "auth", auth, authMethods),
+				interceptors.UnaryAuth(jwt, publicMethods...),
+			),
+			grpc.ChainStreamInterceptor(
+				interceptors.StreamErrors(),
+				interceptors.StreamRecovery(),
+				interceptors.StreamAuth(jwt, publicMethods...),
+			),
+		),
+		health:          health.NewServer(),
+		addr:            ":" + cfg.Port,
+		shutdownTimeout: shutdownTimeout,
+	}
+
+	healthpb.RegisterHealthServer(s.srv, s.health)
+
+	if cfg.Reflection {
+		reflection.Register(s.srv)
+	}
+
+	return s
+}
+
+// RegisterService registers a service and reports it as serving,
+// it implements grpc.ServiceRegistrar.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	s.srv.RegisterService(desc, impl)
+	s.health.SetServingStatus(desc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+}
+
+// Run serves the calls until ctx is done, then reports every service
+// as not serving, stops accepting connections and waits up to the
+// shutdown timeout for the in-flight calls to finish. The remaining
+// calls are canceled after that.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ctx, ln)
+}
+
+func (s *Server) serve(ctx context.Context, ln net.Listener) error {
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- s.srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	clog.Info("shutting down the gRPC server...")
+
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		clog.Warn("gRPC server failed to drain the calls in time")
+		s.srv.Stop()
+		<-stopped
+	}
+
+	return <-serveErr
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/app/grpc/pb"
+	service "hexagony/app/grpc/services"
+	"hexagony/config"
+	"hexagony/libs/bearer"
+	"hexagony/libs/ratelimit"
+	"hexagony/libs/validation"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer(t *testing.T) {
+	missing := uuid.New()
+
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+	mockAlbumUseCase.On("FindByID", mock.Anything, missing).Return(nil, domain.ErrResourceNotFound)
+
+	v, err := validation.New()
+	assert.NoError(t, err)
+
+	s := New(config.GRPC{Reflection: true}, config.JWT{Secret: "secret"}, ratelimit.New(ratelimit.NewMemoryStore()), ratelimit.Policy{}, time.Second)
+	pb.RegisterAlbumsServiceServer(s, &service.AlbumsService{AlbumsUseCase: mockAlbumUseCase, Validator: v})
+
+	ln := bufconn.Listen(1 << 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.serve(ctx, ln)
+	}()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	t.Run("health", func(t *testing.T) {
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "hexagony.v1.AlbumsService"})
+		assert.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	})
+
+	albums := pb.NewAlbumsServiceClient(conn)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := albums.GetAlbum(ctx, &pb.GetAlbumRequest{Id: missing.String()})
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		if assert.Len(t, st.Details(), 1) {
+			assert.Equal(t, domain.ErrTokenEmpty.Code, st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+		}
+	})
+
+	t.Run("domain error", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, bearer.Claims{UUID: uuid.New(), Email: "john@example.com"}).
+			SignedString([]byte("secret"))
+		assert.NoError(t, err)
+
+		authorized := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+		_, err = albums.GetAlbum(authorized, &pb.GetAlbumRequest{Id: missing.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	cancel()
+	assert.NoError(t, <-served)
+}
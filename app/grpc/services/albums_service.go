@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/grpc/interceptors"
+	"hexagony/app/grpc/pb"
+	"hexagony/config"
+	"hexagony/libs/validation"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+// bulkMaxOperations bounds the operations of a bulk, as over HTTP.
+const bulkMaxOperations = 5000
+
+type AlbumsService struct {
+	pb.UnimplementedAlbumsServiceServer
+
+	AlbumsUseCase domain.AlbumsUseCase
+	Validator     validation.Validator
+	Concurrency   config.Concurrency
+}
+
+type albumRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Length  int    `json:"length" validate:"required,album_length"`
+	Barcode string `json:"barcode,omitempty" validate:"omitempty,barcode"`
+}
+
+type bulkAlbumOperation struct {
+	Op      string `json:"op" validate:"required,oneof=create update delete"`
+	ID      string `json:"id" validate:"required_unless=Op create,omitempty,uuid"`
+	Version int    `json:"version" validate:"gte=0"`
+	Name    string `json:"name" validate:"required_unless=Op delete"`
+	Length  int    `json:"length" validate:"required_unless=Op delete,omitempty,album_length"`
+	Barcode string `json:"barcode" validate:"omitempty,barcode"`
+}
+
+func (s *AlbumsService) ListAlbums(ctx context.Context, _ *pb.ListAlbumsRequest) (*pb.ListAlbumsResponse, error) {
+	albums, err := s.AlbumsUseCase.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListAlbumsResponse{Albums: toAlbums(albums)}, nil
+}
+
+func (s *AlbumsService) GetAlbum(ctx context.Context, req *pb.GetAlbumRequest) (*pb.Album, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	album, err := s.AlbumsUseCase.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAlbum(album), nil
+}
+
+func (s *AlbumsService) CreateAlbum(ctx context.Context, req *pb.CreateAlbumRequest) (*pb.Album, error) {
+	payload := albumRequest{Name: req.GetName(), Length: int(req.GetLength()), Barcode: req.GetBarcode()}
+
+	if err := bind(ctx, s.Validator, payload); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	album := domain.Albums{
+		UUID:      uuid.New(),
+		Name:      payload.Name,
+		Length:    payload.Length,
+		Barcode:   payload.Barcode,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.AlbumsUseCase.Add(ctx, &album); err != nil {
+		return nil, err
+	}
+
+	return toAlbum(&album), nil
+}
+
+func (s *AlbumsService) UpdateAlbum(ctx context.Context, req *pb.UpdateAlbumRequest) (*pb.Album, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	payload := albumRequest{Name: req.GetName(), Length: int(req.GetLength()), Barcode: req.GetBarcode()}
+
+	if err := bind(ctx, s.Validator, payload); err != nil {
+		return nil, err
+	}
+
+	version, err := version(s.Concurrency, req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	album := domain.Albums{
+		Name:      payload.Name,
+		Length:    payload.Length,
+		Barcode:   payload.Barcode,
+		Version:   version,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.AlbumsUseCase.Update(ctx, id, &album); err != nil {
+		return nil, err
+	}
+
+	return s.GetAlbum(ctx, &pb.GetAlbumRequest{Id: id.String()})
+}
+
+// PatchAlbum changes the fields that are set, on the version read
+// first so that it is only written if nobody changed the album since.
+func (s *AlbumsService) PatchAlbum(ctx context.Context, req *pb.PatchAlbumRequest) (*pb.Album, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := version(s.Concurrency, req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	album, err := s.AlbumsUseCase.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && version != album.Version {
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	payload := albumRequest{Name: album.Name, Length: album.Length, Barcode: album.Barcode}
+
+	if req.Name != nil {
+		payload.Name = req.GetName()
+	}
+	if req.Length != nil {
+		payload.Length = int(req.GetLength())
+	}
+	if req.Barcode != nil {
+		payload.Barcode = req.GetBarcode()
+	}
+
+	if err := bind(ctx, s.Validator, payload); err != nil {
+		return nil, err
+	}
+
+	patch := domain.AlbumsPatch{UpdatedAt: time.Now(), Version: album.Version}
+
+	if payload.Name != album.Name {
+		patch.Name = &payload.Name
+	}
+	if payload.Length != album.Length {
+		patch.Length = &payload.Length
+	}
+	if payload.Barcode != album.Barcode {
+		patch.Barcode = &payload.Barcode
+	}
+
+	if err := s.AlbumsUseCase.Patch(ctx, id, &patch); err != nil {
+		return nil, err
+	}
+
+	if !patch.Empty() {
+		album.Name, album.Length, album.Barcode = payload.Name, payload.Length, payload.Barcode
+		album.Version, album.UpdatedAt = patch.Version, patch.UpdatedAt
+	}
+
+	return toAlbum(album), nil
+}
+
+func (s *AlbumsService) DeleteAlbum(ctx context.Context, req *pb.DeleteAlbumRequest) (*pb.DeleteAlbumResponse, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := version(s.Concurrency, req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.AlbumsUseCase.Delete(ctx, id, version); err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteAlbumResponse{}, nil
+}
+
+func (s *AlbumsService) ListDeletedAlbums(ctx context.Context, _ *pb.ListDeletedAlbumsRequest) (*pb.ListAlbumsResponse, error) {
+	albums, err := s.AlbumsUseCase.FindTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListAlbumsResponse{Albums: toAlbums(albums)}, nil
+}
+
+func (s *AlbumsService) RestoreAlbum(ctx context.Context, req *pb.RestoreAlbumRequest) (*pb.Album, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.AlbumsUseCase.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetAlbum(ctx, &pb.GetAlbumRequest{Id: id.String()})
+}
+
+func (s *AlbumsService) ListAlbumRevisions(ctx context.Context, req *pb.ListAlbumRevisionsRequest) (*pb.ListAlbumRevisionsResponse, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.AlbumsUseCase.FindRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*pb.AlbumRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		items = append(items, toRevision(revision))
+	}
+
+	return &pb.ListAlbumRevisionsResponse{Revisions: items}, nil
+}
+
+func (s *AlbumsService) RestoreAlbumRevision(ctx context.Context, req *pb.RestoreAlbumRevisionRequest) (*pb.Album, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Validator.BindField(ctx, "revision", int(req.GetRevision()), "gt=0"); err != nil {
+		return nil, invalid(s.Validator.InvalidParams(err, acceptLanguage(ctx)))
+	}
+
+	if _, err := s.AlbumsUseCase.RestoreRevision(ctx, id, int(req.GetRevision()), int(req.GetVersion())); err != nil {
+		return nil, err
+	}
+
+	return s.GetAlbum(ctx, &pb.GetAlbumRequest{Id: id.String()})
+}
+
+// BulkAlbums writes the valid operations, an atomic bulk with an
+// invalid operation is aborted without writing anything. Every
+// operation has a result, its code being OK once written.
+func (s *AlbumsService) BulkAlbums(ctx context.Context, req *pb.BulkAlbumsRequest) (*pb.BulkAlbumsResponse, error) {
+	if err := s.Validator.BindField(ctx, "operations", len(req.GetOperations()), fmt.Sprintf("min=1,max=%d", bulkMaxOperations)); err != nil {
+		return nil, invalid(s.Validator.InvalidParams(err, acceptLanguage(ctx)))
+	}
+
+	body := &pb.BulkAlbumsResponse{Results: make([]*pb.BulkAlbumResult, len(req.GetOperations()))}
+
+	var (
+		operations []*domain.AlbumsBulkOperation
+		indexes    []int
+	)
+
+	now := time.Now()
+
+	for i, item := range req.GetOperations() {
+		result := &pb.BulkAlbumResult{Index: int32(i), Op: item.GetOp(), Id: item.GetId()}
+		body.Results[i] = result
+
+		payload := bulkAlbumOperation{
+			Op:      item.GetOp(),
+			ID:      item.GetId(),
+			Version: int(item.GetVersion()),
+			Name:    item.GetName(),
+			Length:  int(item.GetLength()),
+			Barcode: item.GetBarcode(),
+		}
+
+		if err := s.Validator.BindStruct(ctx, payload); err != nil {
+			params := s.Validator.InvalidParams(err, acceptLanguage(ctx))
+			for _, param := range params {
+				param.Name = fmt.Sprintf("operations[%d].%s", i, param.Name)
+			}
+
+			fail(result, invalid(params))
+			continue
+		}
+
+		operation := domain.AlbumsBulkOperation{
+			Op:    payload.Op,
+			Album: domain.Albums{Version: payload.Version, UpdatedAt: now},
+		}
+
+		if payload.Op == domain.BulkCreate {
+			operation.Album.UUID, operation.Album.CreatedAt = uuid.New(), now
+		} else {
+			operation.Album.UUID = uuid.MustParse(payload.ID)
+		}
+
+		if payload.Op != domain.BulkDelete {
+			operation.Album.Name = payload.Name
+			operation.Album.Length = payload.Length
+			operation.Album.Barcode = payload.Barcode
+		}
+
+		result.Id = operation.Album.UUID.String()
+
+		operations = append(operations, &operation)
+		indexes = append(indexes, i)
+	}
+
+	var err error
+
+	// nothing is written when an atomic bulk has an invalid operation
+	if req.GetAtomic() && len(operations) < len(req.GetOperations()) {
+		err = domain.ErrAlbumsBulkAborted
+	} else if len(operations) > 0 {
+		err = s.AlbumsUseCase.Bulk(ctx, operations, req.GetAtomic())
+	}
+
+	if err != nil && !errors.Is(err, domain.ErrAlbumsBulkAborted) {
+		return nil, err
+	}
+
+	body.Aborted = err != nil
+
+	for i, operation := range operations {
+		result := body.Results[indexes[i]]
+
+		switch {
+		case operation.Err != nil:
+			fail(result, operation.Err)
+		case body.Aborted:
+			fail(result, domain.ErrAlbumsBulkAborted)
+		default:
+			result.Code, result.Version = int32(codes.OK), int32(operation.Album.Version)
+		}
+	}
+
+	for _, result := range body.Results {
+		if result.ErrorCode != "" {
+			body.Failed++
+		} else {
+			body.Applied++
+		}
+	}
+
+	return body, nil
+}
+
+func (s *AlbumsService) ExportAlbums(_ *pb.ExportAlbumsRequest, stream pb.AlbumsService_ExportAlbumsServer) error {
+	return s.AlbumsUseCase.Export(stream.Context(), func(album *domain.Albums) error {
+		return stream.Send(toAlbum(album))
+	})
+}
+
+// fail sets the status of err on the result of a bulk operation,
+// the error code being the reason of its ErrorInfo.
+func fail(result *pb.BulkAlbumResult, err error) {
+	s := interceptors.Status(err)
+
+	result.Code, result.ErrorMessage, result.ErrorCode = int32(s.Code()), s.Message(), "internal_error"
+
+	for _, detail := range s.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			result.ErrorCode = info.GetReason()
+		}
+	}
+}
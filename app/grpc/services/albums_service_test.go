@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/app/grpc/pb"
+	controller "hexagony/app/http/controllers"
+	"hexagony/config"
+	"hexagony/libs/validation"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testValidator validation.Validator
+
+func init() {
+	v, err := validation.New(controller.ValidationRules...)
+	if err != nil {
+		panic(err)
+	}
+	testValidator = v
+}
+
+func TestGetAlbum(t *testing.T) {
+	now := time.Now()
+	mockAlbum := domain.Albums{UUID: uuid.New(), Name: "St. Anger", Length: 75, Version: 2, CreatedAt: now, UpdatedAt: now}
+
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+	mockAlbumUseCase.On("FindByID", mock.Anything, mockAlbum.UUID).Return(&mockAlbum, nil)
+
+	s := AlbumsService{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}
+
+	album, err := s.GetAlbum(context.Background(), &pb.GetAlbumRequest{Id: mockAlbum.UUID.String()})
+	assert.NoError(t, err)
+	assert.Equal(t, mockAlbum.UUID.String(), album.GetId())
+	assert.Equal(t, "St. Anger", album.GetName())
+	assert.Equal(t, int32(2), album.GetVersion())
+	assert.Nil(t, album.GetDeletedAt())
+
+	// the UUIDs are case insensitive
+	album, err = s.GetAlbum(context.Background(), &pb.GetAlbumRequest{Id: strings.ToUpper(mockAlbum.UUID.String())})
+	assert.NoError(t, err)
+	assert.Equal(t, mockAlbum.UUID.String(), album.GetId())
+
+	_, err = s.GetAlbum(context.Background(), &pb.GetAlbumRequest{Id: "nope"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockAlbumUseCase.AssertExpectations(t)
+}
+
+func TestCreateAlbumValidation(t *testing.T) {
+	s := AlbumsService{AlbumsUseCase: new(mocks.AlbumUseCase), Validator: testValidator}
+
+	_, err := s.CreateAlbum(context.Background(), &pb.CreateAlbumRequest{Name: "St. Anger", Length: 0})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	var fields []string
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			assert.Equal(t, domain.ErrValidation.Code, detail.GetReason())
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				fields = append(fields, violation.GetField())
+			}
+		}
+	}
+	assert.Equal(t, []string{"length"}, fields)
+}
+
+func TestUpdateAlbumRequiresVersion(t *testing.T) {
+	s := AlbumsService{
+		AlbumsUseCase: new(mocks.AlbumUseCase),
+		Validator:     testValidator,
+		Concurrency:   config.Concurrency{RequireIfMatch: true},
+	}
+
+	_, err := s.UpdateAlbum(context.Background(), &pb.UpdateAlbumRequest{Id: uuid.NewString(), Name: "Load", Length: 79})
+	assert.ErrorIs(t, err, errVersionRequired)
+}
+
+func TestBulkAlbums(t *testing.T) {
+	existing := uuid.New()
+
+	operations := []*pb.BulkAlbumOperation{
+		{Op: "create", Name: "St. Anger", Length: 75},
+		{Op: "update", Id: existing.String(), Version: 2, Name: "Load", Length: 79},
+		{Op: "delete", Id: existing.String(), Version: 3},
+		{Op: "create", Length: 75},
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Bulk", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsBulkOperation"), false).
+			Run(func(args mock.Arguments) {
+				operations := args.Get(1).([]*domain.AlbumsBulkOperation)
+				assert.Len(t, operations, 3)
+
+				operations[0].Album.Version = 1
+				operations[1].Album.Version = 3
+				operations[2].Err = domain.ErrPreconditionFailed
+			}).
+			Return(nil).Once()
+
+		s := AlbumsService{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}
+
+		body, err := s.BulkAlbums(context.Background(), &pb.BulkAlbumsRequest{Operations: operations})
+		assert.NoError(t, err)
+		assert.False(t, body.GetAborted())
+		assert.Equal(t, int32(2), body.GetApplied())
+		assert.Equal(t, int32(2), body.GetFailed())
+
+		results := body.GetResults()
+		assert.Equal(t, int32(codes.OK), results[0].GetCode())
+		assert.Equal(t, int32(1), results[0].GetVersion())
+		assert.Equal(t, int32(codes.Aborted), results[2].GetCode())
+		assert.Equal(t, domain.ErrPreconditionFailed.Code, results[2].GetErrorCode())
+		assert.Equal(t, int32(codes.InvalidArgument), results[3].GetCode())
+		assert.Equal(t, domain.ErrValidation.Code, results[3].GetErrorCode())
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("atomic with an invalid operation", func(t *testing.T) {
+		s := AlbumsService{AlbumsUseCase: new(mocks.AlbumUseCase), Validator: testValidator}
+
+		body, err := s.BulkAlbums(context.Background(), &pb.BulkAlbumsRequest{Atomic: true, Operations: operations})
+		assert.NoError(t, err)
+		assert.True(t, body.GetAborted())
+		assert.Equal(t, int32(0), body.GetApplied())
+		assert.Equal(t, domain.ErrAlbumsBulkAborted.Code, body.GetResults()[0].GetErrorCode())
+	})
+}
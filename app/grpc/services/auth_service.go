@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/grpc/pb"
+	"hexagony/libs/validation"
+)
+
+type AuthService struct {
+	pb.UnimplementedAuthServiceServer
+
+	AuthUseCase domain.AuthUseCase
+	Validator   validation.Validator
+}
+
+type authRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,gte=8"`
+}
+
+func (s *AuthService) Authenticate(ctx context.Context, req *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	payload := authRequest{Email: req.GetEmail(), Password: req.GetPassword()}
+
+	if err := bind(ctx, s.Validator, payload); err != nil {
+		return nil, err
+	}
+
+	token, err := s.AuthUseCase.Authenticate(ctx, payload.Email, payload.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AuthenticateResponse{Token: token.Token}, nil
+}
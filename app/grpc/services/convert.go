@@ -0,0 +1,105 @@
+package service
+
+import (
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/grpc/pb"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toAlbum(album *domain.Albums) *pb.Album {
+	return &pb.Album{
+		Id:        album.UUID.String(),
+		Name:      album.Name,
+		Length:    int32(album.Length),
+		Barcode:   album.Barcode,
+		Version:   int32(album.Version),
+		CreatedAt: timestamppb.New(album.CreatedAt),
+		UpdatedAt: timestamppb.New(album.UpdatedAt),
+		DeletedAt: timestamp(album.DeletedAt),
+	}
+}
+
+func toAlbums(albums []*domain.Albums) []*pb.Album {
+	items := make([]*pb.Album, 0, len(albums))
+	for _, album := range albums {
+		items = append(items, toAlbum(album))
+	}
+
+	return items
+}
+
+func toRevision(revision *domain.AlbumRevision) *pb.AlbumRevision {
+	item := &pb.AlbumRevision{
+		AlbumId:    revision.AlbumUUID.String(),
+		Revision:   int32(revision.Revision),
+		Action:     revision.Action,
+		Snapshot:   toAlbum(&revision.Snapshot),
+		ActorEmail: revision.ActorEmail,
+		CreatedAt:  timestamppb.New(revision.CreatedAt),
+		Changes:    make([]*pb.FieldChange, 0, len(revision.Changes)),
+	}
+
+	if revision.ActorUUID != nil {
+		item.ActorId = revision.ActorUUID.String()
+	}
+
+	for _, change := range revision.Changes {
+		item.Changes = append(item.Changes, &pb.FieldChange{
+			Field: change.Field,
+			From:  value(change.From),
+			To:    value(change.To),
+		})
+	}
+
+	return item
+}
+
+func toUser(user *domain.UsersList) *pb.User {
+	return &pb.User{
+		Id:        user.UUID.String(),
+		Name:      user.Name,
+		Email:     user.Email,
+		Version:   int32(user.Version),
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+		DeletedAt: timestamp(user.DeletedAt),
+	}
+}
+
+func toUsers(users []*domain.UsersList) []*pb.User {
+	items := make([]*pb.User, 0, len(users))
+	for _, user := range users {
+		items = append(items, toUser(user))
+	}
+
+	return items
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+// value converts a value of a field change as it is written in JSON,
+// e.g. a time as an RFC 3339 string.
+func value(v interface{}) *structpb.Value {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return structpb.NewNullValue()
+	}
+
+	var converted structpb.Value
+	if err := protojson.Unmarshal(raw, &converted); err != nil {
+		return structpb.NewNullValue()
+	}
+
+	return &converted
+}
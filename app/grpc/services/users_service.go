@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/grpc/pb"
+	"hexagony/config"
+	"hexagony/libs/crypto"
+	"hexagony/libs/validation"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UsersService struct {
+	pb.UnimplementedUsersServiceServer
+
+	UsersUseCase domain.UsersUseCase
+	Validator    validation.Validator
+	Concurrency  config.Concurrency
+}
+
+type createUserRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required,gte=8"`
+}
+
+type updateUserRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required"`
+}
+
+func (s *UsersService) ListUsers(ctx context.Context, _ *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	users, err := s.UsersUseCase.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListUsersResponse{Users: toUsers(users)}, nil
+}
+
+func (s *UsersService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.UsersUseCase.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUser(user), nil
+}
+
+func (s *UsersService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	payload := createUserRequest{Name: req.GetName(), Email: req.GetEmail(), Password: req.GetPassword()}
+
+	if err := bind(ctx, s.Validator, payload); err != nil {
+		return nil, err
+	}
+
+	hashPass, err := crypto.New().HashPassword(payload.Password, 10)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUsersHashPassword, err)
+	}
+
+	now := time.Now()
+
+	user := domain.Users{
+		UUID:      uuid.New(),
+		Name:      payload.Name,
+		Email:     payload.Email,
+		Password:  hashPass,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.UsersUseCase.Add(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(ctx, &pb.GetUserRequest{Id: user.UUID.String()})
+}
+
+func (s *UsersService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	payload := updateUserRequest{Name: req.GetName(), Email: req.GetEmail()}
+
+	if err := bind(ctx, s.Validator, payload); err != nil {
+		return nil, err
+	}
+
+	version, err := version(s.Concurrency, req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	user := domain.Users{
+		Name:      payload.Name,
+		Email:     payload.Email,
+		Version:   version,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.UsersUseCase.Update(ctx, id, &user); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(ctx, &pb.GetUserRequest{Id: id.String()})
+}
+
+// PatchUser changes the fields that are set, on the version read
+// first so that it is only written if nobody changed the user since.
+func (s *UsersService) PatchUser(ctx context.Context, req *pb.PatchUserRequest) (*pb.User, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := version(s.Concurrency, req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.UsersUseCase.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && version != user.Version {
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	payload := updateUserRequest{Name: user.Name, Email: user.Email}
+
+	if req.Name != nil {
+		payload.Name = req.GetName()
+	}
+	if req.Email != nil {
+		payload.Email = req.GetEmail()
+	}
+
+	if err := bind(ctx, s.Validator, payload); err != nil {
+		return nil, err
+	}
+
+	patch := domain.UsersPatch{UpdatedAt: time.Now(), Version: user.Version}
+
+	if payload.Name != user.Name {
+		patch.Name = &payload.Name
+	}
+	if payload.Email != user.Email {
+		patch.Email = &payload.Email
+	}
+
+	if err := s.UsersUseCase.Patch(ctx, id, &patch); err != nil {
+		return nil, err
+	}
+
+	if !patch.Empty() {
+		user.Name, user.Email = payload.Name, payload.Email
+		user.Version, user.UpdatedAt = patch.Version, patch.UpdatedAt
+	}
+
+	return toUser(user), nil
+}
+
+func (s *UsersService) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := version(s.Concurrency, req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.UsersUseCase.Delete(ctx, id, version); err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteUserResponse{}, nil
+}
+
+func (s *UsersService) ListDeletedUsers(ctx context.Context, _ *pb.ListDeletedUsersRequest) (*pb.ListUsersResponse, error) {
+	users, err := s.UsersUseCase.FindTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListUsersResponse{Users: toUsers(users)}, nil
+}
+
+func (s *UsersService) RestoreUser(ctx context.Context, req *pb.RestoreUserRequest) (*pb.User, error) {
+	id, err := uuidField(ctx, s.Validator, "id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.UsersUseCase.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(ctx, &pb.GetUserRequest{Id: id.String()})
+}
@@ -0,0 +1,93 @@
+// Package service implements the gRPC services on top of the use
+// cases, the counterpart of the HTTP controllers.
+package service
+
+import (
+	"context"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/grpc/interceptors"
+	"hexagony/config"
+	"hexagony/libs/problem"
+	"hexagony/libs/validation"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errVersionRequired refuses the unconditioned writes, the
+// counterpart of a missing If-Match header.
+var errVersionRequired = domain.NewError(domain.KindPreconditionRequired, domain.ErrPreconditionRequired.Code,
+	"the request must be conditioned with the version")
+
+// bind validates the payload and returns the InvalidArgument status
+// listing the invalid fields, in the language asked by the client.
+func bind(ctx context.Context, v validation.Validator, payload interface{}) error {
+	if err := v.BindStruct(ctx, payload); err != nil {
+		return invalid(v.InvalidParams(err, acceptLanguage(ctx)))
+	}
+
+	return nil
+}
+
+// uuidField validates and parses the uuid field name. The validator
+// only accepts lowercase hexadecimal digits, the value is lowercased
+// first as the HTTP route parameters are.
+func uuidField(ctx context.Context, v validation.Validator, name, value string) (uuid.UUID, error) {
+	value = strings.ToLower(value)
+
+	if err := v.BindField(ctx, name, value, "required,uuid"); err != nil {
+		return uuid.Nil, invalid(v.InvalidParams(err, acceptLanguage(ctx)))
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	return id, nil
+}
+
+// version returns the version a write is conditioned on, refusing
+// the unconditioned writes when the configuration requires it.
+func version(cfg config.Concurrency, version int32) (int, error) {
+	if version == 0 && cfg.RequireIfMatch {
+		return 0, errVersionRequired
+	}
+
+	return int(version), nil
+}
+
+// invalid returns the validation status with the invalid fields as
+// the violations of a BadRequest.
+func invalid(params []*problem.InvalidParam) error {
+	s := status.New(codes.InvalidArgument, domain.ErrValidation.Message)
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(params))
+	for _, param := range params {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: param.Name, Description: param.Reason})
+	}
+
+	detailed, err := s.WithDetails(
+		&errdetails.ErrorInfo{Reason: domain.ErrValidation.Code, Domain: interceptors.ErrorDomain},
+		&errdetails.BadRequest{FieldViolations: violations},
+	)
+	if err != nil {
+		return s.Err()
+	}
+
+	return detailed.Err()
+}
+
+// acceptLanguage returns the accept-language metadata of the call.
+func acceptLanguage(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, "accept-language"); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/problem"
+	"hexagony/libs/rest"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errVersionRequired refuses an update or a delete made without a version
+// when the configuration requires the writes to be conditioned.
+var errVersionRequired = domain.NewError(domain.KindPreconditionRequired, domain.ErrPreconditionRequired.Code,
+	"the operation must be conditioned with the version")
+
+// Modes of a bulk write.
+const (
+	bulkAtomic     = "atomic"
+	bulkBestEffort = "best_effort"
+)
+
+type bulkAlbumsRequest struct {
+	Mode       string                `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []*bulkAlbumOperation `json:"operations" validate:"required,min=1,max=5000"`
+}
+
+type bulkAlbumOperation struct {
+	Op      string        `json:"op" validate:"required,oneof=create update delete"`
+	ID      string        `json:"id,omitempty" validate:"required_unless=Op create,omitempty,uuid"`
+	Version int           `json:"version,omitempty" validate:"gte=0"`
+	Album   *albumRequest `json:"album,omitempty" validate:"required_unless=Op delete"`
+}
+
+type bulkAlbumsResponse struct {
+	Mode    string             `json:"mode"`
+	Applied int                `json:"applied"`
+	Failed  int                `json:"failed"`
+	Results []*bulkAlbumResult `json:"results"`
+}
+
+// bulkAlbumResult is the outcome of an operation, Index being its
+// position in the request.
+type bulkAlbumResult struct {
+	Index   int              `json:"index"`
+	Op      string           `json:"op"`
+	ID      *uuid.UUID       `json:"id,omitempty"`
+	Status  int              `json:"status"`
+	Version int              `json:"version,omitempty"`
+	Error   *problem.Problem `json:"error,omitempty"`
+}
+
+// invalid fails the operation with the validation problem of params.
+func (r *bulkAlbumResult) invalid(params []*problem.InvalidParam) {
+	r.Status = http.StatusBadRequest
+	r.Error = problem.New(http.StatusBadRequest, domain.ErrValidation.Code, domain.ErrValidation.Message)
+	r.Error.InvalidParams = params
+}
+
+// Bulk godoc
+// @Summary      Write albums in bulk
+// @Description  creates, updates and deletes albums in one transaction. An atomic bulk is rolled back as soon as an operation fails, a best_effort one only undoes the failed operations. With REQUIRE_IF_MATCH the updates and deletes without a version fail with 428.
+// @Tags         album
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string             true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        payload        body      bulkAlbumsRequest  true  "operations"
+// @Success      200            {object}  bulkAlbumsResponse
+// @Success      207            {object}  bulkAlbumsResponse
+// @Failure      400            {object}  response.Problem
+// @Failure      422            {object}  bulkAlbumsResponse
+// @Failure      500            {object}  response.Problem
+// @Router       /album/bulk [post]
+func (a *AlbumsController) Bulk(w http.ResponseWriter, r *http.Request) {
+	var payload bulkAlbumsRequest
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
+		return
+	}
+
+	if !bind(w, r, a.Validator, payload) {
+		return
+	}
+
+	if payload.Mode == "" {
+		payload.Mode = bulkAtomic
+	}
+	atomic := payload.Mode == bulkAtomic
+
+	body := bulkAlbumsResponse{Mode: payload.Mode, Results: make([]*bulkAlbumResult, len(payload.Operations))}
+
+	var (
+		operations []*domain.AlbumsBulkOperation
+		indexes    []int
+	)
+
+	now := time.Now()
+
+	for i, item := range payload.Operations {
+		result := &bulkAlbumResult{Index: i}
+		body.Results[i] = result
+
+		// a null operation is reported as a missing one
+		if item == nil {
+			missing := a.Validator.BindField(r.Context(), fmt.Sprintf("operations[%d]", i), item, "required")
+			result.invalid(a.Validator.InvalidParams(missing, r.Header.Get("Accept-Language")))
+
+			continue
+		}
+
+		result.Op = item.Op
+
+		if err := a.Validator.BindStruct(r.Context(), item); err != nil {
+			params := a.Validator.InvalidParams(err, r.Header.Get("Accept-Language"))
+			for _, param := range params {
+				param.Name = fmt.Sprintf("operations[%d].%s", i, param.Name)
+			}
+
+			result.invalid(params)
+
+			continue
+		}
+
+		// as the single writes, the updates and deletes are refused
+		// without the version they are conditioned on
+		if item.Op != domain.BulkCreate && item.Version == 0 && a.Concurrency.RequireIfMatch {
+			result.Status, result.Error = response.Status(errVersionRequired), response.FromError(errVersionRequired)
+
+			continue
+		}
+
+		operation := domain.AlbumsBulkOperation{
+			Op:    item.Op,
+			Album: domain.Albums{Version: item.Version, UpdatedAt: now},
+		}
+
+		if item.Op == domain.BulkCreate {
+			operation.Album.UUID, operation.Album.CreatedAt = uuid.New(), now
+		} else {
+			operation.Album.UUID = uuid.MustParse(item.ID)
+		}
+
+		if item.Album != nil {
+			operation.Album.Name = item.Album.Name
+			operation.Album.Length = item.Album.Length
+			operation.Album.Barcode = item.Album.Barcode
+		}
+
+		result.ID = &operation.Album.UUID
+
+		operations = append(operations, &operation)
+		indexes = append(indexes, i)
+	}
+
+	invalid := len(operations) < len(payload.Operations)
+
+	// nothing is written when an atomic bulk has an invalid operation
+	if atomic && invalid {
+		err = domain.ErrAlbumsBulkAborted
+	} else if len(operations) > 0 {
+		err = a.AlbumsUseCase.Bulk(r.Context(), operations, atomic)
+	}
+
+	if err != nil && !errors.Is(err, domain.ErrAlbumsBulkAborted) {
+		response.Error(w, r, err)
+		return
+	}
+
+	aborted := err != nil
+
+	for i, operation := range operations {
+		result := body.Results[indexes[i]]
+
+		switch {
+		case operation.Err != nil:
+			result.Status = response.Status(operation.Err)
+			result.Error = response.FromError(operation.Err)
+		case aborted:
+			result.Status = http.StatusFailedDependency
+			result.Error = response.FromError(domain.ErrAlbumsBulkAborted)
+		case operation.Op == domain.BulkCreate:
+			result.Status, result.Version = http.StatusCreated, operation.Album.Version
+		default:
+			result.Status, result.Version = http.StatusOK, operation.Album.Version
+		}
+	}
+
+	for _, result := range body.Results {
+		if result.Error != nil {
+			body.Failed++
+		} else {
+			body.Applied++
+		}
+	}
+
+	status := http.StatusOK
+	switch {
+	case aborted:
+		status = http.StatusUnprocessableEntity
+	case body.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+
+	rest.JSON(w, status, &body)
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAlbumsBulk(t *testing.T) {
+	existing := uuid.New()
+
+	operations := []map[string]interface{}{
+		{"op": "create", "album": map[string]interface{}{"name": "St. Anger", "length": 75}},
+		{"op": "update", "id": existing.String(), "version": 2, "album": map[string]interface{}{"name": "Load", "length": 79}},
+		{"op": "delete", "id": existing.String(), "version": 3},
+		{"op": "create", "album": map[string]interface{}{"name": "", "length": 75}},
+	}
+
+	send := func(handler AlbumsController, mode string) (*httptest.ResponseRecorder, bulkAlbumsResponse) {
+		payload, err := json.Marshal(map[string]interface{}{"mode": mode, "operations": operations})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/album/bulk", bytes.NewBuffer(payload))
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc(handler.Bulk).ServeHTTP(rec, req)
+
+		var body bulkAlbumsResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+
+		return rec, body
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Bulk", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsBulkOperation"), false).
+			Run(func(args mock.Arguments) {
+				operations := args.Get(1).([]*domain.AlbumsBulkOperation)
+				assert.Len(t, operations, 3)
+
+				operations[0].Album.Version = 1
+				operations[1].Album.Version = 3
+				operations[2].Err = domain.ErrPreconditionFailed
+			}).
+			Return(nil).Once()
+
+		rec, body := send(AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}, "best_effort")
+
+		assert.Equal(t, http.StatusMultiStatus, rec.Code)
+		assert.Equal(t, 2, body.Applied)
+		assert.Equal(t, 2, body.Failed)
+
+		assert.Equal(t, http.StatusCreated, body.Results[0].Status)
+		assert.Equal(t, http.StatusOK, body.Results[1].Status)
+		assert.Equal(t, 3, body.Results[1].Version)
+		assert.Equal(t, http.StatusPreconditionFailed, body.Results[2].Status)
+		assert.Equal(t, http.StatusBadRequest, body.Results[3].Status)
+		assert.Equal(t, "operations[3].album.name", body.Results[3].Error.InvalidParams[0].Name)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("atomic with an invalid operation", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+		rec, body := send(AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}, "")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, "atomic", body.Mode)
+		assert.Equal(t, 0, body.Applied)
+		assert.Equal(t, http.StatusFailedDependency, body.Results[0].Status)
+		assert.Equal(t, http.StatusBadRequest, body.Results[3].Status)
+
+		mockAlbumUseCase.AssertNotCalled(t, "Bulk", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("atomic rolled back", func(t *testing.T) {
+		operations = operations[:3]
+
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Bulk", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsBulkOperation"), true).
+			Run(func(args mock.Arguments) {
+				args.Get(1).([]*domain.AlbumsBulkOperation)[1].Err = domain.ErrResourceNotFound
+			}).
+			Return(domain.ErrAlbumsBulkAborted).Once()
+
+		rec, body := send(AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}, "atomic")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, http.StatusFailedDependency, body.Results[0].Status)
+		assert.Equal(t, http.StatusNotFound, body.Results[1].Status)
+		assert.Equal(t, "resource_not_found", body.Results[1].Error.Code)
+		assert.Equal(t, http.StatusFailedDependency, body.Results[2].Status)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("null operation", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Bulk", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsBulkOperation"), false).
+			Run(func(args mock.Arguments) {
+				assert.Len(t, args.Get(1).([]*domain.AlbumsBulkOperation), 1)
+			}).
+			Return(nil).Once()
+
+		payload := `{"mode":"best_effort","operations":[null,{"op":"create","album":{"name":"Load","length":79}}]}`
+
+		req, err := http.NewRequest(http.MethodPost, "/album/bulk", bytes.NewBufferString(payload))
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc((&AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}).Bulk).ServeHTTP(rec, req)
+
+		var body bulkAlbumsResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+
+		assert.Equal(t, http.StatusMultiStatus, rec.Code)
+		assert.Equal(t, http.StatusBadRequest, body.Results[0].Status)
+		assert.Equal(t, "operations[0]", body.Results[0].Error.InvalidParams[0].Name)
+		assert.Equal(t, http.StatusCreated, body.Results[1].Status)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("version required", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Bulk", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsBulkOperation"), false).
+			Run(func(args mock.Arguments) {
+				assert.Len(t, args.Get(1).([]*domain.AlbumsBulkOperation), 2)
+			}).
+			Return(nil).Once()
+
+		payload := `{"mode":"best_effort","operations":[` +
+			`{"op":"update","id":"` + existing.String() + `","album":{"name":"Load","length":79}},` +
+			`{"op":"delete","id":"` + existing.String() + `"},` +
+			`{"op":"delete","id":"` + existing.String() + `","version":3},` +
+			`{"op":"create","album":{"name":"Reload","length":76}}]}`
+
+		req, err := http.NewRequest(http.MethodPost, "/album/bulk", bytes.NewBufferString(payload))
+		assert.NoError(t, err)
+
+		handler := &AlbumsController{
+			AlbumsUseCase: mockAlbumUseCase,
+			Validator:     testValidator,
+			Concurrency:   config.Concurrency{RequireIfMatch: true},
+		}
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc(handler.Bulk).ServeHTTP(rec, req)
+
+		var body bulkAlbumsResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+
+		assert.Equal(t, http.StatusMultiStatus, rec.Code)
+		assert.Equal(t, http.StatusPreconditionRequired, body.Results[0].Status)
+		assert.Equal(t, http.StatusPreconditionRequired, body.Results[1].Status)
+		assert.Equal(t, http.StatusOK, body.Results[2].Status)
+		assert.Equal(t, http.StatusCreated, body.Results[3].Status)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("invalid bulk", func(t *testing.T) {
+		for _, payload := range []string{`{"operations":[]}`, `{"mode":"maybe","operations":[{"op":"delete"}]}`, `[`} {
+			req, err := http.NewRequest(http.MethodPost, "/album/bulk", bytes.NewBufferString(payload))
+			assert.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			http.HandlerFunc((&AlbumsController{Validator: testValidator}).Bulk).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, payload)
+		}
+	})
+}
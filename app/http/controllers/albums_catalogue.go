@@ -0,0 +1,590 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/clog"
+	"hexagony/libs/problem"
+	"hexagony/libs/requestid"
+	"hexagony/libs/rest"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Formats of the catalogue files.
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+const (
+	// exportFlushRows is the number of rows sent to the client at once.
+	exportFlushRows = 100
+
+	// importBatchSize is the number of rows written in a transaction.
+	importBatchSize = 500
+
+	// importMaxErrors bounds the row errors listed in the summary.
+	importMaxErrors = 1000
+
+	// importMaxLine bounds the size of a JSON Lines row.
+	importMaxLine = 1 << 20
+
+	// catalogueProgress is the time an export or an import has to
+	// send or read its next rows, past the timeouts of the server.
+	catalogueProgress = time.Minute
+)
+
+// exportColumns are the header of the CSV export, the import reads
+// the files it writes back.
+var exportColumns = []string{"id", "name", "length", "barcode", "version", "created_at", "updated_at"}
+
+// importFields are the fields of an imported row, read from the
+// columns, or keys, of the same name unless mapped otherwise.
+var importFields = []string{"id", "name", "length", "barcode", "version"}
+
+// importRow is an imported row as read from the file.
+type importRow struct {
+	ID      string `json:"id" validate:"omitempty,uuid"`
+	Name    string `json:"name" validate:"required"`
+	Length  string `json:"length" validate:"required,number,max=5"`
+	Barcode string `json:"barcode" validate:"omitempty,barcode"`
+	Version string `json:"version" validate:"omitempty,number"`
+}
+
+// importSummary reports the rows of an import. Error is the failure
+// that stopped it, the batches written before are still counted and
+// the rows of the failed one are failed.
+type importSummary struct {
+	DryRun  bool             `json:"dry_run"`
+	Rows    int              `json:"rows"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []*importError   `json:"errors"`
+	Error   *problem.Problem `json:"error,omitempty"`
+}
+
+// importError is the failure of the row at Line, 1 being the first
+// line of the file.
+type importError struct {
+	Line          int                     `json:"line"`
+	Code          string                  `json:"code"`
+	Detail        string                  `json:"detail"`
+	InvalidParams []*problem.InvalidParam `json:"invalid_params,omitempty"`
+}
+
+func (s *importSummary) fail(line int, p *problem.Problem) {
+	s.Failed++
+
+	if len(s.Errors) < importMaxErrors {
+		s.Errors = append(s.Errors, &importError{
+			Line:          line,
+			Code:          p.Code,
+			Detail:        p.Detail,
+			InvalidParams: p.InvalidParams,
+		})
+	}
+}
+
+// extendDeadlines gives an export or an import catalogueProgress to
+// go on, the read deadline being extended along with the write one
+// when read is set. A writer without deadlines is left as is.
+func extendDeadlines(controller *http.ResponseController, read bool) error {
+	deadline := time.Now().Add(catalogueProgress)
+
+	if read {
+		if err := controller.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+
+	if err := controller.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+// rowReader reads the rows of an import, keyed by field. A row that
+// cannot be parsed is reported with ErrImportMalformed, the end of
+// the file with io.EOF.
+type rowReader interface {
+	Read() (line int, fields map[string]string, err error)
+}
+
+type csvRows struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// newCSVRows reads the header of the file, the name and the length
+// columns are required.
+func newCSVRows(body io.Reader, mapping map[string]string) (*csvRows, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrImportMapping, err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		// spreadsheets may start the file with a byte order mark
+		column = strings.TrimPrefix(column, "\ufeff")
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	columns := make(map[string]int, len(importFields))
+
+	for _, field := range importFields {
+		column := mapping[field]
+
+		if i, ok := index[strings.ToLower(column)]; ok {
+			columns[field] = i
+		} else if field == "name" || field == "length" {
+			return nil, fmt.Errorf("%w: missing column %q", domain.ErrImportMapping, column)
+		}
+	}
+
+	return &csvRows{reader: reader, columns: columns}, nil
+}
+
+func (c *csvRows) Read() (int, map[string]string, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.Line, nil, fmt.Errorf("%w: %w", domain.ErrImportMalformed, err)
+		}
+
+		return 0, nil, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+
+	fields := make(map[string]string, len(c.columns))
+	for field, i := range c.columns {
+		if i < len(record) {
+			fields[field] = strings.TrimSpace(record[i])
+		}
+	}
+
+	return line, fields, nil
+}
+
+type jsonlRows struct {
+	scanner *bufio.Scanner
+	mapping map[string]string
+	line    int
+}
+
+func newJSONLRows(body io.Reader, mapping map[string]string) *jsonlRows {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), importMaxLine)
+
+	return &jsonlRows{scanner: scanner, mapping: mapping}
+}
+
+func (j *jsonlRows) Read() (int, map[string]string, error) {
+	for j.scanner.Scan() {
+		j.line++
+
+		text := bytes.TrimSpace(j.scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.UseNumber()
+
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			return j.line, nil, fmt.Errorf("%w: %w", domain.ErrImportMalformed, err)
+		}
+
+		fields := make(map[string]string, len(importFields))
+		for _, field := range importFields {
+			if value, ok := object[j.mapping[field]]; ok && value != nil {
+				fields[field] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+
+		return j.line, fields, nil
+	}
+
+	if err := j.scanner.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	return 0, nil, io.EOF
+}
+
+// importMapping parses a mapping such as "name:Title,length:Minutes",
+// the fields not listed keep their own name.
+func importMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string, len(importFields))
+	for _, field := range importFields {
+		mapping[field] = field
+	}
+
+	if value == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, ":")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+
+		if _, known := mapping[field]; !ok || !known || column == "" {
+			return nil, fmt.Errorf("%w: %q", domain.ErrImportMapping, pair)
+		}
+
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+// importFormat returns the format of the import, from the format
+// parameter or else from the media type of the body.
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "text/csv":
+		return formatCSV
+	case "application/x-ndjson", "application/jsonl":
+		return formatJSONL
+	}
+
+	return ""
+}
+
+// Export godoc
+// @Summary      Export the albums
+// @Description  streams the whole catalogue as CSV or JSON Lines
+// @Tags         album
+// @Produce      text/csv,application/x-ndjson
+// @Param        Authorization  header    string  true   "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        format         query     string  false  "csv (default) or jsonl"
+// @Success      200
+// @Failure      400            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /album/export [get]
+func (a *AlbumsController) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+
+	if err := a.Validator.BindField(r.Context(), "format", format, "omitempty,oneof=csv jsonl"); err != nil {
+		response.Validation(w, r, a.Validator.InvalidParams(err, r.Header.Get("Accept-Language")))
+		return
+	}
+
+	var (
+		encode func(*domain.Albums) error
+		flush  func() error
+		header func() error
+	)
+
+	controller := http.NewResponseController(w)
+
+	// the export outlives the timeouts of the server as long as
+	// it sends its rows
+	if err := extendDeadlines(controller, false); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	if format == formatJSONL {
+		encoder := json.NewEncoder(w)
+
+		encode = func(album *domain.Albums) error { return encoder.Encode(album) }
+		flush = func() error {
+			if err := controller.Flush(); err != nil {
+				return err
+			}
+			return extendDeadlines(controller, false)
+		}
+		header = func() error { return nil }
+	} else {
+		format = formatCSV
+		writer := csv.NewWriter(w)
+
+		encode = func(album *domain.Albums) error {
+			return writer.Write([]string{
+				album.UUID.String(),
+				album.Name,
+				strconv.Itoa(album.Length),
+				album.Barcode,
+				strconv.Itoa(album.Version),
+				album.CreatedAt.Format(time.RFC3339),
+				album.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			if err := controller.Flush(); err != nil {
+				return err
+			}
+			return extendDeadlines(controller, false)
+		}
+		header = func() error { return writer.Write(exportColumns) }
+	}
+
+	// the response starts with the first album, so that a failed
+	// query is still reported as a problem
+	started := false
+
+	start := func() error {
+		started = true
+
+		contentType := map[string]string{formatCSV: "text/csv; charset=utf-8", formatJSONL: "application/x-ndjson"}[format]
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="albums.`+format+`"`)
+		w.WriteHeader(http.StatusOK)
+
+		return header()
+	}
+
+	rows := 0
+
+	err := a.AlbumsUseCase.Export(r.Context(), func(album *domain.Albums) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if err := encode(album); err != nil {
+			return err
+		}
+
+		if rows++; rows%exportFlushRows == 0 {
+			return flush()
+		}
+		return nil
+	})
+
+	if err == nil && !started {
+		err = start()
+	}
+
+	if err != nil {
+		if !started {
+			response.Error(w, r, err)
+			return
+		}
+
+		// the status is already sent, the client sees a truncated file
+		clog.ErrorContext(r.Context(), err, "failed to export the albums")
+		return
+	}
+
+	if err := flush(); err != nil {
+		clog.ErrorContext(r.Context(), err, "failed to export the albums")
+	}
+}
+
+// Import godoc
+// @Summary      Import albums
+// @Description  upserts the albums of a CSV or JSON Lines file, matched by id or else by barcode, an optional version conditioning the updates. The rows are written by batches of 500, a dry run writes nothing. A failure stops the import and is returned along with the summary of the batches written before it. With REQUIRE_IF_MATCH the updates without a version fail.
+// @Tags         album
+// @Accept       text/csv,application/x-ndjson
+// @Produce      json
+// @Param        Authorization  header    string  true   "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        format         query     string  false  "csv or jsonl, from the Content-Type by default"
+// @Param        mapping        query     string  false  "columns of the fields, e.g. name:Title,length:Minutes"
+// @Param        dry_run        query     bool    false  "reports the outcome without writing"
+// @Success      200            {object}  importSummary
+// @Failure      400            {object}  response.Problem
+// @Failure      415            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /album/import [post]
+func (a *AlbumsController) Import(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if err := a.Validator.BindField(r.Context(), "dry_run", query.Get("dry_run"), "omitempty,boolean"); err != nil {
+		response.Validation(w, r, a.Validator.InvalidParams(err, r.Header.Get("Accept-Language")))
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(query.Get("dry_run"))
+
+	mapping, err := importMapping(query.Get("mapping"))
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	var rows rowReader
+
+	switch importFormat(r) {
+	case formatCSV:
+		if rows, err = newCSVRows(r.Body, mapping); err != nil {
+			response.Error(w, r, err)
+			return
+		}
+	case formatJSONL:
+		rows = newJSONLRows(r.Body, mapping)
+	default:
+		response.Error(w, r, domain.ErrImportMediaType)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+
+	// the import outlives the timeouts of the server as long as
+	// it reads its rows
+	if err := extendDeadlines(controller, true); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	summary := importSummary{DryRun: dryRun, Errors: []*importError{}}
+
+	var batch []*domain.AlbumsImportRow
+
+	// stop reports the failure along with the batches already written,
+	// the rows of the failed one are not
+	stop := func(err error) {
+		summary.Error = response.FromError(err)
+		summary.Error.Instance = r.URL.Path
+		summary.Error.RequestID = requestid.FromContext(r.Context())
+		summary.Failed += len(batch)
+
+		if summary.Error.Status >= http.StatusInternalServerError {
+			clog.ErrorContext(r.Context(), err, "failed to import the albums")
+		}
+
+		rest.JSON(w, summary.Error.Status, &summary)
+	}
+
+	write := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := a.AlbumsUseCase.Import(r.Context(), batch, dryRun); err != nil {
+			return err
+		}
+
+		for _, row := range batch {
+			switch {
+			case row.Err != nil:
+				summary.fail(row.Line, response.FromError(row.Err))
+			case row.Outcome == domain.ImportCreated:
+				summary.Created++
+			default:
+				summary.Updated++
+			}
+		}
+
+		batch = nil
+		return extendDeadlines(controller, true)
+	}
+
+	now := time.Now()
+
+	for {
+		line, fields, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil && !errors.Is(err, domain.ErrImportMalformed) {
+			stop(fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
+			return
+		}
+
+		summary.Rows++
+
+		if err != nil {
+			summary.fail(line, response.FromError(err))
+			continue
+		}
+
+		album, p := a.importAlbum(r, fields)
+		if p != nil {
+			summary.fail(line, p)
+			continue
+		}
+
+		album.CreatedAt, album.UpdatedAt = now, now
+		batch = append(batch, &domain.AlbumsImportRow{
+			Line:           line,
+			Album:          *album,
+			RequireVersion: a.Concurrency.RequireIfMatch,
+		})
+
+		if len(batch) == importBatchSize {
+			if err := write(); err != nil {
+				stop(err)
+				return
+			}
+		}
+	}
+
+	if err := write(); err != nil {
+		stop(err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, &summary)
+}
+
+// importAlbum validates the fields of an imported row, the failure is
+// reported as a validation problem.
+func (a *AlbumsController) importAlbum(r *http.Request, fields map[string]string) (*domain.Albums, *problem.Problem) {
+	row := importRow{
+		ID:      fields["id"],
+		Name:    fields["name"],
+		Length:  fields["length"],
+		Barcode: fields["barcode"],
+		Version: fields["version"],
+	}
+
+	err := a.Validator.BindStruct(r.Context(), row)
+
+	if err == nil {
+		length, _ := strconv.Atoi(row.Length)
+		err = a.Validator.BindStruct(r.Context(), albumRequest{Name: row.Name, Length: length, Barcode: row.Barcode})
+	}
+
+	if err != nil {
+		p := problem.New(http.StatusBadRequest, domain.ErrValidation.Code, domain.ErrValidation.Message)
+		p.InvalidParams = a.Validator.InvalidParams(err, r.Header.Get("Accept-Language"))
+
+		return nil, p
+	}
+
+	album := domain.Albums{Name: row.Name, Barcode: row.Barcode}
+	album.Length, _ = strconv.Atoi(row.Length)
+	album.Version, _ = strconv.Atoi(row.Version)
+
+	if row.ID != "" {
+		album.UUID = uuid.MustParse(row.ID)
+	}
+
+	return &album, nil
+}
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAlbumsExport(t *testing.T) {
+	newUUID := uuid.New()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+	mockAlbumUseCase.
+		On("Export", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, fn func(*domain.Albums) error) error {
+			return fn(&domain.Albums{
+				UUID:      newUUID,
+				Name:      "St. Anger",
+				Length:    75,
+				Version:   2,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			})
+		})
+
+	handler := AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}
+
+	t.Run("csv", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/album/export", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc(handler.Export).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t,
+			"id,name,length,barcode,version,created_at,updated_at\n"+
+				newUUID.String()+",St. Anger,75,,2,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n",
+			rec.Body.String())
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/album/export?format=jsonl", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc(handler.Export).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+		var album domain.Albums
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &album))
+		assert.Equal(t, newUUID, album.UUID)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		failing := new(mocks.AlbumUseCase)
+		failing.On("Export", mock.Anything, mock.Anything).Return(domain.ErrAlbumsExport)
+
+		req, err := http.NewRequest(http.MethodGet, "/album/export?format=jsonl", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc((&AlbumsController{AlbumsUseCase: failing, Validator: testValidator}).Export).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "albums_export_failed")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/album/export?format=xlsx", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc(handler.Export).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAlbumsImport(t *testing.T) {
+	existing := uuid.New()
+
+	send := func(handler AlbumsController, query, contentType, body string) (*httptest.ResponseRecorder, importSummary) {
+		req, err := http.NewRequest(http.MethodPost, "/album/import"+query, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+
+		rec := httptest.NewRecorder()
+		http.HandlerFunc(handler.Import).ServeHTTP(rec, req)
+
+		var summary importSummary
+		_ = json.Unmarshal(rec.Body.Bytes(), &summary)
+
+		return rec, summary
+	}
+
+	t.Run("csv dry run with a mapping", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Import", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsImportRow"), true).
+			Run(func(args mock.Arguments) {
+				rows := args.Get(1).([]*domain.AlbumsImportRow)
+				assert.Len(t, rows, 2)
+
+				assert.Equal(t, 2, rows[0].Line)
+				assert.Equal(t, "St. Anger", rows[0].Album.Name)
+				assert.Equal(t, 75, rows[0].Album.Length)
+				assert.Equal(t, existing, rows[1].Album.UUID)
+
+				rows[0].Outcome = domain.ImportCreated
+				rows[1].Err = domain.ErrAlbumsImportTrashed
+			}).
+			Return(nil).Once()
+
+		csv := "\ufeffTitle,Minutes,ID\n" +
+			"St. Anger,75,\n" +
+			"Load,79," + existing.String() + "\n" +
+			"Reload,abc,\n"
+
+		rec, summary := send(
+			AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator},
+			"?dry_run=true&mapping=name:title,length:minutes",
+			"text/csv",
+			csv,
+		)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, summary.DryRun)
+		assert.Equal(t, 3, summary.Rows)
+		assert.Equal(t, 1, summary.Created)
+		assert.Equal(t, 2, summary.Failed)
+
+		assert.Equal(t, 4, summary.Errors[0].Line)
+		assert.Equal(t, "length", summary.Errors[0].InvalidParams[0].Name)
+		assert.Equal(t, 3, summary.Errors[1].Line)
+		assert.Equal(t, "albums_import_trashed", summary.Errors[1].Code)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Import", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsImportRow"), false).
+			Run(func(args mock.Arguments) {
+				rows := args.Get(1).([]*domain.AlbumsImportRow)
+				assert.Len(t, rows, 1)
+				assert.Equal(t, "4006381333931", rows[0].Album.Barcode)
+
+				rows[0].Outcome = domain.ImportUpdated
+			}).
+			Return(nil).Once()
+
+		jsonl := `{"name": "St. Anger", "length": 75, "barcode": "4006381333931"}` + "\n\n" + `{"name": ` + "\n"
+
+		rec, summary := send(AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}, "", "application/x-ndjson", jsonl)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 2, summary.Rows)
+		assert.Equal(t, 1, summary.Updated)
+		assert.Equal(t, 3, summary.Errors[0].Line)
+		assert.Equal(t, "import_malformed_row", summary.Errors[0].Code)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("versions required", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Import", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsImportRow"), false).
+			Run(func(args mock.Arguments) {
+				rows := args.Get(1).([]*domain.AlbumsImportRow)
+				assert.Len(t, rows, 2)
+
+				assert.Equal(t, 4, rows[0].Album.Version)
+				assert.True(t, rows[0].RequireVersion)
+				assert.Equal(t, 0, rows[1].Album.Version)
+
+				rows[0].Outcome = domain.ImportUpdated
+				rows[1].Err = domain.ErrAlbumsImportVersion
+			}).
+			Return(nil).Once()
+
+		handler := AlbumsController{
+			AlbumsUseCase: mockAlbumUseCase,
+			Validator:     testValidator,
+			Concurrency:   config.Concurrency{RequireIfMatch: true},
+		}
+
+		csv := "id,name,length,version\n" +
+			existing.String() + ",Load,79,4\n" +
+			existing.String() + ",Reload,76,\n" +
+			",Garage Inc.,137,v2\n"
+
+		rec, summary := send(handler, "", "text/csv", csv)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, summary.Updated)
+		assert.Equal(t, 2, summary.Failed)
+		assert.Equal(t, "version", summary.Errors[0].InvalidParams[0].Name)
+		assert.Equal(t, "albums_import_version_required", summary.Errors[1].Code)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("failed batch", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("Import", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsImportRow"), false).
+			Run(func(args mock.Arguments) {
+				for _, row := range args.Get(1).([]*domain.AlbumsImportRow) {
+					row.Outcome = domain.ImportCreated
+				}
+			}).
+			Return(nil).Once()
+		mockAlbumUseCase.
+			On("Import", mock.Anything, mock.AnythingOfType("[]*domain.AlbumsImportRow"), false).
+			Return(domain.ErrAlbumsImport).Once()
+
+		csv := "name,length\n" + strings.Repeat("St. Anger,75\n", importBatchSize+2)
+
+		rec, summary := send(AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: testValidator}, "", "text/csv", csv)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, importBatchSize+2, summary.Rows)
+		assert.Equal(t, importBatchSize, summary.Created)
+		assert.Equal(t, 2, summary.Failed)
+		assert.Equal(t, "albums_import_failed", summary.Error.Code)
+		assert.Equal(t, "/album/import", summary.Error.Instance)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		handler := AlbumsController{AlbumsUseCase: new(mocks.AlbumUseCase), Validator: testValidator}
+
+		for _, c := range []struct {
+			query, contentType, body string
+			status                   int
+		}{
+			{"", "application/pdf", "", http.StatusUnsupportedMediaType},
+			{"?mapping=title", "text/csv", "name,length\n", http.StatusBadRequest},
+			{"?mapping=genre:Genre", "text/csv", "name,length\n", http.StatusBadRequest},
+			{"", "text/csv", "title,length\n", http.StatusBadRequest},
+			{"?dry_run=maybe", "text/csv", "name,length\n", http.StatusBadRequest},
+		} {
+			rec, _ := send(handler, c.query, c.contentType, c.body)
+			assert.Equal(t, c.status, rec.Code, c)
+		}
+	})
+}
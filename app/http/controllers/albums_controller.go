@@ -2,25 +2,41 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"hexagony/app/domain"
-	"hexagony/libs/clog"
+	"hexagony/app/http/response"
+	"hexagony/config"
 	"hexagony/libs/rest"
 	"hexagony/libs/validation"
 	"net/http"
 	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type AlbumsController struct {
 	AlbumsUseCase domain.AlbumsUseCase
+	Validator     validation.Validator
+
+	// Feed streams the album events, as configured by Stream.
+	Feed   domain.EventFeed
+	Stream config.Stream
+
+	// Hub tracks the editors connected by WebSocket, as configured by
+	// Presence, from the browsers of the AllowedOrigins.
+	Hub            domain.PresenceHub
+	Presence       config.Presence
+	AllowedOrigins []string
+
+	// Concurrency conditions the writes made without If-Match,
+	// the bulk operations and the imported updates.
+	Concurrency config.Concurrency
 }
 
 type albumRequest struct {
-	Name   string `json:"name" validate:"required"`
-	Length int    `json:"length" validate:"required"`
+	Name    string `json:"name" validate:"required"`
+	Length  int    `json:"length" validate:"required,album_length"`
+	Barcode string `json:"barcode,omitempty" validate:"omitempty,barcode"`
 }
 
 // FindAll godoc
@@ -31,13 +47,12 @@ type albumRequest struct {
 // @Produce      json
 // @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Success      200            {object}  []*domain.Albums
-// @Failure      500            {object}  rest.Message
+// @Failure      500            {object}  response.Problem
 // @Router       /album [get]
 func (a *AlbumsController) FindAll(w http.ResponseWriter, r *http.Request) {
 	albums, err := a.AlbumsUseCase.FindAll(r.Context())
 	if err != nil {
-		clog.Error(err, domain.ErrAlbumsFindAll.Error())
-		rest.DecodeError(w, r, domain.ErrAlbumsFindAll, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
@@ -52,30 +67,30 @@ func (a *AlbumsController) FindAll(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        uuid           path      string  true  "album uuid"
+// @Param        If-None-Match  header    string  false  "entity tag of a cached version"
 // @Success      200            {object}  *domain.Albums
-// @Failure      404            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Success      304
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /album/{uuid} [get]
 func (a *AlbumsController) FindByID(w http.ResponseWriter, r *http.Request) {
-	uuid, err := uuid.Parse(chi.URLParam(r, "uuid"))
-	if err != nil {
-		clog.Error(err, domain.ErrAlbumsUUIDParse.Error())
-		rest.DecodeError(w, r, domain.ErrAlbumsFindByID, http.StatusInternalServerError)
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
 		return
 	}
 
 	album, err := a.AlbumsUseCase.FindByID(r.Context(), uuid)
-
-	exists := errors.Is(err, domain.ErrResourceNotFound)
-	if exists {
-		clog.Error(err, domain.ErrResourceNotFound.Error())
-		rest.DecodeError(w, r, domain.ErrResourceNotFound, http.StatusNotFound)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
-	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+	tag := etag(album.Version)
+	w.Header().Set("ETag", tag)
+
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -91,23 +106,19 @@ func (a *AlbumsController) FindByID(w http.ResponseWriter, r *http.Request) {
 // @Param        Authorization  header    string        true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        payload        body      albumRequest  true  "add a new album"
 // @Success      201            {object}  rest.Message
-// @Failure      400            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /album [post]
 func (a *AlbumsController) Add(w http.ResponseWriter, r *http.Request) {
 	var payload albumRequest
 
 	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
-		clog.Error(err, domain.ErrAlbumsAdd.Error())
-		rest.DecodeError(w, r, domain.ErrAlbumsAdd, http.StatusInternalServerError)
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
 		return
 	}
 
-	validation := validation.New()
-
-	if err := validation.BindStruct(r.Context(), payload); err != nil {
-		validation.DecodeError(w, err)
+	if !bind(w, r, a.Validator, payload) {
 		return
 	}
 
@@ -115,14 +126,14 @@ func (a *AlbumsController) Add(w http.ResponseWriter, r *http.Request) {
 		UUID:      uuid.New(),
 		Name:      payload.Name,
 		Length:    payload.Length,
+		Barcode:   payload.Barcode,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	err = a.AlbumsUseCase.Add(r.Context(), &album)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
@@ -137,94 +148,292 @@ func (a *AlbumsController) Add(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        Authorization  header    string        true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        uuid           path      string        true  "album uuid"
+// @Param        If-Match       header    string        false  "entity tag of the version being updated"
 // @Param        payload        body      albumRequest  true  "update an album by uuid"
 // @Success      200            {object}  rest.Message
-// @Failure      404            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      412            {object}  response.Problem
+// @Failure      428            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /album/{uuid} [put]
 func (a *AlbumsController) Update(w http.ResponseWriter, r *http.Request) {
-	uuid, err := uuid.Parse(chi.URLParam(r, "uuid"))
-	if err != nil {
-		clog.Error(err, domain.ErrAlbumsUUIDParse.Error())
-		rest.DecodeError(w, r, domain.ErrAlbumsUpdate, http.StatusInternalServerError)
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
 		return
 	}
 
 	var payload albumRequest
 
-	err = json.NewDecoder(r.Body).Decode(&payload)
+	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
 		return
 	}
 
-	validation := validation.New()
+	if !bind(w, r, a.Validator, payload) {
+		return
+	}
 
-	if err := validation.BindStruct(r.Context(), payload); err != nil {
-		clog.Error(err, domain.ErrAlbumsUpdate.Error())
-		validation.DecodeError(w, err)
+	version, err := ifMatch(r)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
 	album := domain.Albums{
 		Name:      payload.Name,
 		Length:    payload.Length,
+		Barcode:   payload.Barcode,
+		Version:   version,
 		UpdatedAt: time.Now(),
 	}
 
 	err = a.AlbumsUseCase.Update(r.Context(), uuid, &album)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(album.Version))
+
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Updated"})
+}
+
+// Patch godoc
+// @Summary      Patch an album
+// @Description  partially update an album by uuid with a merge patch (RFC 7396) or a JSON patch (RFC 6902)
+// @Tags         album
+// @Accept       application/merge-patch+json,application/json-patch+json
+// @Produce      json
+// @Param        Authorization  header    string        true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string        true  "album uuid"
+// @Param        If-Match       header    string        false  "entity tag of the version being patched"
+// @Param        payload        body      albumRequest  true  "patch document"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      412            {object}  response.Problem
+// @Failure      415            {object}  response.Problem
+// @Failure      422            {object}  response.Problem
+// @Failure      428            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /album/{uuid} [patch]
+func (a *AlbumsController) Patch(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
+		return
+	}
 
-	exists := errors.Is(err, domain.ErrResourceNotFound)
-	if exists {
-		clog.Error(err, domain.ErrResourceNotFound.Error())
-		rest.DecodeError(w, r, domain.ErrResourceNotFound, http.StatusNotFound)
+	version, err := ifMatch(r)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
+	album, err := a.AlbumsUseCase.FindByID(r.Context(), uuid)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
+		return
+	}
+
+	if version != 0 && version != album.Version {
+		response.Error(w, r, domain.ErrPreconditionFailed)
 		return
 	}
 
+	payload := albumRequest{
+		Name:    album.Name,
+		Length:  album.Length,
+		Barcode: album.Barcode,
+	}
+
+	if err := applyPatch(r, &payload); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	if !bind(w, r, a.Validator, payload) {
+		return
+	}
+
+	// the patch was applied to the version read above, so
+	// it is only written if nobody changed the album since
+	patch := domain.AlbumsPatch{UpdatedAt: time.Now(), Version: album.Version}
+
+	if payload.Name != album.Name {
+		patch.Name = &payload.Name
+	}
+	if payload.Length != album.Length {
+		patch.Length = &payload.Length
+	}
+	if payload.Barcode != album.Barcode {
+		patch.Barcode = &payload.Barcode
+	}
+
+	err = a.AlbumsUseCase.Patch(r.Context(), uuid, &patch)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(patch.Version))
+
 	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Updated"})
 }
 
 // Update godoc
 // @Summary      Delete an album
-// @Description  delete an album by uuid
+// @Description  move an album to the trash by uuid
 // @Tags         album
 // @Accept       json
 // @Produce      json
 // @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        uuid           path      string  true  "album uuid"
+// @Param        If-Match       header    string  false  "entity tag of the version being deleted"
 // @Success      200            {object}  rest.Message
-// @Failure      404            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      412            {object}  response.Problem
+// @Failure      428            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /album/{uuid} [delete]
 func (a *AlbumsController) Delete(w http.ResponseWriter, r *http.Request) {
-	uuid, err := uuid.Parse(chi.URLParam(r, "uuid"))
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatch(r)
 	if err != nil {
-		clog.Error(err, domain.ErrAlbumsUUIDParse.Error())
-		rest.DecodeError(w, r, domain.ErrAlbumsDelete, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
-	err = a.AlbumsUseCase.Delete(r.Context(), uuid)
+	err = a.AlbumsUseCase.Delete(r.Context(), uuid, version)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
 
-	exists := errors.Is(err, domain.ErrResourceNotFound)
-	if exists {
-		clog.Error(err, domain.ErrResourceNotFound.Error())
-		rest.DecodeError(w, r, domain.ErrResourceNotFound, http.StatusNotFound)
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Deleted"})
+}
+
+// Trash godoc
+// @Summary      List of deleted albums
+// @Description  lists the deleted albums that were not purged yet
+// @Tags         album
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Success      200            {object}  []*domain.Albums
+// @Failure      500            {object}  response.Problem
+// @Router       /album/trash [get]
+func (a *AlbumsController) Trash(w http.ResponseWriter, r *http.Request) {
+	albums, err := a.AlbumsUseCase.FindTrash(r.Context())
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
+	rest.JSON(w, http.StatusOK, &albums)
+}
+
+// Restore godoc
+// @Summary      Restore an album
+// @Description  restore a deleted album by uuid
+// @Tags         album
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "album uuid"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /album/{uuid}/restore [post]
+func (a *AlbumsController) Restore(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
+		return
+	}
+
+	err := a.AlbumsUseCase.Restore(r.Context(), uuid)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
-	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Deleted"})
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Restored"})
+}
+
+// Revisions godoc
+// @Summary      List the revisions of an album
+// @Description  lists the revisions of an album by uuid, oldest first, with the changes since the previous one
+// @Tags         album
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "album uuid"
+// @Success      200            {object}  []domain.AlbumRevision
+// @Failure      400            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /album/{uuid}/revisions [get]
+func (a *AlbumsController) Revisions(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
+		return
+	}
+
+	revisions, err := a.AlbumsUseCase.FindRevisions(r.Context(), uuid)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, &revisions)
+}
+
+// RestoreRevision godoc
+// @Summary      Restore a revision of an album
+// @Description  writes back the name, length and barcode of an album revision
+// @Tags         album
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true   "album uuid"
+// @Param        revision       path      int     true   "album revision"
+// @Param        If-Match       header    string  false  "entity tag of the version being overwritten"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      412            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /album/{uuid}/revisions/{revision}/restore [post]
+func (a *AlbumsController) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
+		return
+	}
+
+	revision, ok := revisionParam(w, r, a.Validator)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	version, err = a.AlbumsUseCase.RestoreRevision(r.Context(), uuid, revision, version)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(version))
+
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Restored"})
 }
@@ -31,6 +31,7 @@ func TestAlbumsFindAll(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -57,6 +58,7 @@ func TestAlbumsFindAllFail(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -87,6 +89,7 @@ func TestAlbumsFetchByID(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -114,6 +117,7 @@ func TestAlbumsFetchByIDFail(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -144,7 +148,7 @@ func TestAlbumsFetchByIDFail(t *testing.T) {
 	router.HandleFunc("/album/{uuid}", handler.FindByID)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockAlbumUseCase.AssertExpectations(t)
 }
@@ -159,6 +163,7 @@ func TestAlbumsFetchByIDFailResource(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -189,6 +194,7 @@ func TestAlbumsAdd(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -222,6 +228,7 @@ func TestAlbumsAddFail(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -257,7 +264,7 @@ func TestAlbumsAddFail(t *testing.T) {
 	router.HandleFunc("/album", handler.Add)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockAlbumUseCase.AssertExpectations(t)
 
@@ -295,6 +302,7 @@ func TestAlbumsUpdate(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -328,6 +336,7 @@ func TestAlbumsUpdateFail(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -361,7 +370,7 @@ func TestAlbumsUpdateFail(t *testing.T) {
 	router.HandleFunc("/album/{uuid}", handler.Update)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockAlbumUseCase.AssertExpectations(t)
 
@@ -381,7 +390,7 @@ func TestAlbumsUpdateFail(t *testing.T) {
 	router.HandleFunc("/album/{uuid}", handler.Update)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockAlbumUseCase.AssertExpectations(t)
 
@@ -419,6 +428,7 @@ func TestAlbumsUpdateFailResouce(t *testing.T) {
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -439,16 +449,113 @@ func TestAlbumsUpdateFailResouce(t *testing.T) {
 	mockAlbumUseCase.AssertExpectations(t)
 }
 
+func TestAlbumsPatch(t *testing.T) {
+	newUUID := uuid.New()
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+	mockAlbum := &domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}
+
+	mockAlbumUseCase.
+		On("FindByID", mock.Anything, newUUID).
+		Return(mockAlbum, nil)
+
+	// only the changed columns are sent to the use case
+	mockAlbumUseCase.
+		On("Patch", mock.Anything, newUUID, mock.MatchedBy(func(p *domain.AlbumsPatch) bool {
+			return p.Name == nil && p.Barcode == nil && p.Length != nil && *p.Length == 80
+		})).
+		Return(nil)
+
+	handler := AlbumsController{
+		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.HandleFunc("/album/{uuid}", handler.Patch)
+
+	tests := map[string]string{
+		mergePatchType: `{"name":"St. Anger","length":80}`,
+		jsonPatchType:  `[{"op":"test","path":"/name","value":"St. Anger"},{"op":"replace","path":"/length","value":80}]`,
+	}
+
+	for contentType, body := range tests {
+		req, err := http.NewRequest(http.MethodPatch, "/album/"+newUUID.String(), bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, contentType)
+	}
+
+	mockAlbumUseCase.AssertExpectations(t)
+}
+
+func TestAlbumsPatchFail(t *testing.T) {
+	newUUID := uuid.New()
+	missingUUID := uuid.New()
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+	mockAlbum := &domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}
+
+	mockAlbumUseCase.
+		On("FindByID", mock.Anything, newUUID).
+		Return(mockAlbum, nil)
+
+	mockAlbumUseCase.
+		On("FindByID", mock.Anything, missingUUID).
+		Return(nil, domain.ErrResourceNotFound)
+
+	handler := AlbumsController{
+		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.HandleFunc("/album/{uuid}", handler.Patch)
+
+	tests := []struct {
+		name        string
+		uuid        uuid.UUID
+		contentType string
+		body        string
+		status      int
+	}{
+		{"not found", missingUUID, mergePatchType, `{"length":80}`, http.StatusNotFound},
+		{"media type", newUUID, "application/json", `{"length":80}`, http.StatusUnsupportedMediaType},
+		{"malformed", newUUID, jsonPatchType, `{"op":"remove"}`, http.StatusBadRequest},
+		{"failed test", newUUID, jsonPatchType, `[{"op":"test","path":"/name","value":"Load"}]`, http.StatusUnprocessableEntity},
+		{"unknown member", newUUID, mergePatchType, `{"id":"1"}`, http.StatusUnprocessableEntity},
+		{"validation", newUUID, mergePatchType, `{"length":null}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodPatch, "/album/"+tt.uuid.String(), bytes.NewBufferString(tt.body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", tt.contentType)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.status, rec.Code, tt.name)
+	}
+
+	mockAlbumUseCase.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestAlbumsDelete(t *testing.T) {
 	newUUID := uuid.New()
 	mockAlbumUseCase := new(mocks.AlbumUseCase)
 
 	mockAlbumUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(nil)
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -471,11 +578,12 @@ func TestAlbumsDeleteFail(t *testing.T) {
 	mockAlbumUseCase := new(mocks.AlbumUseCase)
 
 	mockAlbumUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(domain.ErrAlbumsDelete)
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -495,7 +603,7 @@ func TestAlbumsDeleteFail(t *testing.T) {
 	// err uuid parsing
 
 	mockAlbumUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(nil, domain.ErrAlbumsUUIDParse)
 
 	req, err = http.NewRequest(http.MethodDelete, "/album/{uuid}", nil)
@@ -506,14 +614,14 @@ func TestAlbumsDeleteFail(t *testing.T) {
 	router.HandleFunc("/album/{uuid}", handler.Delete)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockAlbumUseCase.AssertExpectations(t)
 
 	// err decoding json
 
 	mockAlbumUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(domain.ErrAlbumsDelete)
 
 	mockAlbum2 := []byte(`{"id":"1"}`)
@@ -536,11 +644,12 @@ func TestAlbumsDeleteFailResource(t *testing.T) {
 	mockAlbumUseCase := new(mocks.AlbumUseCase)
 
 	mockAlbumUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(domain.ErrResourceNotFound)
 
 	handler := AlbumsController{
 		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -557,3 +666,248 @@ func TestAlbumsDeleteFailResource(t *testing.T) {
 
 	mockAlbumUseCase.AssertExpectations(t)
 }
+
+func TestAlbumsConditionalRequests(t *testing.T) {
+	newUUID := uuid.New()
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+	mockAlbum := &domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75, Version: 3}
+
+	mockAlbumUseCase.
+		On("FindByID", mock.Anything, newUUID).
+		Return(mockAlbum, nil)
+
+	handler := AlbumsController{
+		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.Get("/album/{uuid}", handler.FindByID)
+	router.Put("/album/{uuid}", handler.Update)
+	router.Patch("/album/{uuid}", handler.Patch)
+	router.Delete("/album/{uuid}", handler.Delete)
+
+	// the current version is returned as the entity tag
+
+	req, err := http.NewRequest(http.MethodGet, "/album/"+newUUID.String(), nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+	// cached version
+
+	req.Header.Set("If-None-Match", `W/"2", "3"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// the update is conditioned on the version and returns the new one
+
+	mockAlbumUseCase.
+		On("Update", mock.Anything, newUUID, mock.MatchedBy(func(a *domain.Albums) bool {
+			return a.Version == 3
+		})).
+		Run(func(args mock.Arguments) { args.Get(2).(*domain.Albums).Version = 4 }).
+		Return(nil).Once()
+
+	req, err = http.NewRequest(http.MethodPut, "/album/"+newUUID.String(), bytes.NewBufferString(`{"name":"Load","length":79}`))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"3"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+
+	// somebody else updated the album
+
+	mockAlbumUseCase.
+		On("Update", mock.Anything, newUUID, mock.Anything).
+		Return(domain.ErrPreconditionFailed).Once()
+
+	req, err = http.NewRequest(http.MethodPut, "/album/"+newUUID.String(), bytes.NewBufferString(`{"name":"Load","length":79}`))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"3"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	// the patch is refused before being applied to a stale version
+
+	req, err = http.NewRequest(http.MethodPatch, "/album/"+newUUID.String(), bytes.NewBufferString(`{"length":80}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", mergePatchType)
+	req.Header.Set("If-Match", `"2"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	// weak entity tags never match
+
+	req, err = http.NewRequest(http.MethodDelete, "/album/"+newUUID.String(), nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `W/"3"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	// the delete is conditioned on the version
+
+	mockAlbumUseCase.
+		On("Delete", mock.Anything, newUUID, 3).
+		Return(nil).Once()
+
+	req.Header.Set("If-Match", `"3"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	mockAlbumUseCase.AssertExpectations(t)
+	mockAlbumUseCase.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAlbumsTrash(t *testing.T) {
+	newUUID := uuid.New()
+	deletedAt := time.Now()
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+	mockTrash := []*domain.Albums{{UUID: newUUID, Name: "St. Anger", Length: 75, DeletedAt: &deletedAt}}
+
+	mockAlbumUseCase.
+		On("FindTrash", mock.Anything).
+		Return(mockTrash, nil)
+
+	mockAlbumUseCase.
+		On("Restore", mock.Anything, newUUID).
+		Return(nil).Once()
+
+	mockAlbumUseCase.
+		On("Restore", mock.Anything, newUUID).
+		Return(domain.ErrResourceNotFound).Once()
+
+	handler := AlbumsController{
+		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.Get("/album/trash", handler.Trash)
+	router.Post("/album/{uuid}/restore", handler.Restore)
+
+	req, err := http.NewRequest(http.MethodGet, "/album/trash", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"deleted_at"`)
+
+	// restored, then not in the trash anymore
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound} {
+		req, err = http.NewRequest(http.MethodPost, "/album/"+newUUID.String()+"/restore", nil)
+		assert.NoError(t, err)
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, status, rec.Code)
+	}
+
+	mockAlbumUseCase.AssertExpectations(t)
+}
+
+func TestAlbumsRevisions(t *testing.T) {
+	newUUID := uuid.New()
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+	mockRevisions := []*domain.AlbumRevision{{
+		AlbumUUID: newUUID,
+		Revision:  1,
+		Action:    domain.RevisionCreated,
+		Snapshot:  domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75, Version: 1},
+		Changes:   []domain.FieldChange{{Field: "name", From: "", To: "St. Anger"}},
+	}}
+
+	mockAlbumUseCase.
+		On("FindRevisions", mock.Anything, newUUID).
+		Return(mockRevisions, nil)
+
+	mockAlbumUseCase.
+		On("RestoreRevision", mock.Anything, newUUID, 1, 3).
+		Return(4, nil).Once()
+
+	mockAlbumUseCase.
+		On("RestoreRevision", mock.Anything, newUUID, 2, 0).
+		Return(0, domain.ErrResourceNotFound).Once()
+
+	handler := AlbumsController{
+		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.Get("/album/{uuid}/revisions", handler.Revisions)
+	router.Post("/album/{uuid}/revisions/{revision}/restore", handler.RestoreRevision)
+
+	req, err := http.NewRequest(http.MethodGet, "/album/"+newUUID.String()+"/revisions", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"action":"created"`)
+	assert.Contains(t, rec.Body.String(), `"changes":[{"field":"name","from":"","to":"St. Anger"}]`)
+
+	// restored over the version 3
+	req, err = http.NewRequest(http.MethodPost, "/album/"+newUUID.String()+"/revisions/1/restore", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"3"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+
+	// unknown revision
+	req, err = http.NewRequest(http.MethodPost, "/album/"+newUUID.String()+"/revisions/2/restore", nil)
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// invalid revisions never reach the use case
+	for _, revision := range []string{"abc", "-1", "0", "000", "1234567890"} {
+		req, err = http.NewRequest(http.MethodPost, "/album/"+newUUID.String()+"/revisions/"+revision+"/restore", nil)
+		assert.NoError(t, err)
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, revision)
+	}
+
+	mockAlbumUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/sse"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// eventReset tells a client that the events it missed are no longer
+// buffered, it has to read the albums again.
+const eventReset = "reset"
+
+// Events godoc
+// @Summary      Stream the album events
+// @Description  streams the album.created, album.updated, album.deleted and album.restored events as Server-Sent Events, a client reconnecting with Last-Event-ID receives the events it missed while they are buffered, a reset event otherwise
+// @Tags         album
+// @Produce      text/event-stream
+// @Param        Authorization  header    string  true   "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        Last-Event-ID  header    string  false  "ID of the last event received"
+// @Success      200            {object}  domain.Event
+// @Failure      500            {object}  response.Problem
+// @Router       /album/events [get]
+func (a *AlbumsController) Events(w http.ResponseWriter, r *http.Request) {
+	controller := http.NewResponseController(w)
+
+	// the stream outlives the write timeout of the server
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrStreamUnsupported, err))
+		return
+	}
+
+	subscription := a.Feed.Subscribe(r.Header.Get(sse.LastEventIDHeader))
+	defer subscription.Cancel()
+
+	w.Header().Set("Content-Type", sse.ContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event *domain.Event) error {
+		data, _ := json.Marshal(event)
+
+		_, err := (&sse.Event{ID: strconv.FormatInt(event.Sequence, 10), Event: event.Type, Data: data}).WriteTo(w)
+		return err
+	}
+
+	// the retry is sent first, so that it applies to the next reconnection
+	start := sse.Event{Retry: a.Stream.Retry}
+	if !subscription.Resumed {
+		start.Event, start.Data = eventReset, []byte("{}")
+	}
+
+	if _, err := start.WriteTo(w); err != nil {
+		return
+	}
+
+	for _, event := range subscription.Replay {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(a.Stream.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			// a client falling behind reconnects and resumes from the buffer
+			if !ok {
+				return
+			}
+			err = send(event)
+		case <-heartbeat.C:
+			err = sse.Comment(w, "heartbeat")
+		}
+
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
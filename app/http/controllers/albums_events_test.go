@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"hexagony/app/domain"
+	publisher "hexagony/app/publishers"
+	"hexagony/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// readEvent reads the lines of the next event, or comment, of a stream.
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	var lines []string
+
+	for {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return lines
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestAlbumsEvents(t *testing.T) {
+	ctx := context.Background()
+	feed := publisher.NewFeed("album", 10)
+
+	handler := AlbumsController{
+		Feed:   feed,
+		Stream: config.Stream{Heartbeat: 50 * time.Millisecond, Retry: 3 * time.Second},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/album/events", handler.Events)
+
+	// the stream outlives the write timeout of the server
+	srv := httptest.NewUnstartedServer(router)
+	srv.Config.WriteTimeout = 20 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	missed := &domain.Event{ID: uuid.New(), Sequence: 42, Type: domain.EventAlbumCreated, AggregateType: "album"}
+	seen := &domain.Event{ID: uuid.New(), Sequence: 41, Type: domain.EventAlbumUpdated, AggregateType: "album"}
+	assert.NoError(t, feed.Publish(ctx, seen))
+	assert.NoError(t, feed.Publish(ctx, missed))
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/album/events", nil)
+	req.Header.Set("Last-Event-ID", "41")
+
+	res, err := srv.Client().Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	stream := bufio.NewReader(res.Body)
+
+	// the events missed since Last-Event-ID are replayed
+	assert.Equal(t, []string{"retry: 3000"}, readEvent(t, stream))
+
+	lines := readEvent(t, stream)
+	assert.Contains(t, lines, "id: 42")
+	assert.Contains(t, lines, "event: album.created")
+
+	time.Sleep(40 * time.Millisecond)
+
+	deleted := &domain.Event{ID: uuid.New(), Sequence: 43, Type: domain.EventAlbumDeleted, AggregateType: "album"}
+	assert.NoError(t, feed.Publish(ctx, deleted))
+
+	// heartbeats keep the idle stream alive
+	for lines = readEvent(t, stream); len(lines) > 0 && lines[0] == ": heartbeat"; {
+		lines = readEvent(t, stream)
+	}
+	assert.Contains(t, lines, "id: 43")
+
+	// the stream ends when the feed is closed
+	feed.Close()
+
+	_, err = stream.ReadString('\n')
+	assert.Error(t, err)
+}
+
+func TestAlbumsEventsReset(t *testing.T) {
+	handler := AlbumsController{
+		Feed:   publisher.NewFeed("album", 10),
+		Stream: config.Stream{Heartbeat: time.Second, Retry: time.Second},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(handler.Events))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "41")
+
+	res, err := srv.Client().Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	// the missed events are no longer buffered, the client reads the albums again
+	assert.Equal(t, []string{"event: reset", "retry: 1000", "data: {}"}, readEvent(t, bufio.NewReader(res.Body)))
+}
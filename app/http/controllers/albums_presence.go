@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// presenceProtocol is the subprotocol of the presence connections,
+	// offered along with the bearer.<token> one by the browsers.
+	presenceProtocol = "presence"
+
+	// presenceMessageSize is the size limit of the messages of the clients.
+	presenceMessageSize = 1024
+
+	// presenceWriteWait is the time allowed to write a message.
+	presenceWriteWait = 10 * time.Second
+)
+
+var presenceUpgrader = websocket.Upgrader{
+	Subprotocols: []string{presenceProtocol},
+
+	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		response.StatusProblem(w, r, status, "websocket_upgrade_failed")
+	},
+}
+
+// Join godoc
+// @Summary      Join the editors of an album
+// @Description  upgrades to a WebSocket broadcasting who is viewing or editing the album and the fields they have locked, and notifying the changes of the album. The client sends {"type": "state", "state": "viewing|editing"}, {"type": "lock", "field": "name"} and {"type": "unlock", "field": "name"}, a lock is released unless it is sent again before it expires
+// @Tags         album
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "album uuid"
+// @Success      101            {object}  domain.PresenceMessage
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /album/{uuid}/presence [get]
+func (a *AlbumsController) Join(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, a.Validator)
+	if !ok {
+		return
+	}
+
+	if _, err := a.AlbumsUseCase.FindByID(r.Context(), uuid); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	// the upgrader has answered the request when it fails
+	conn, err := a.upgrader().Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	actor, _ := domain.ActorFromContext(r.Context())
+	session := a.Hub.Join(uuid, actor)
+
+	go a.presenceWrite(conn, session)
+	a.presenceRead(conn, session)
+}
+
+// upgrader returns the upgrader of the presence connections, checking
+// their origin against AllowedOrigins.
+func (a *AlbumsController) upgrader() *websocket.Upgrader {
+	upgrader := presenceUpgrader
+	upgrader.CheckOrigin = a.checkOrigin
+
+	return &upgrader
+}
+
+// checkOrigin allows the origins allowed by CORS, which the browsers do
+// not apply to the WebSockets. The requests without an Origin are not
+// made by a browser and are allowed.
+func (a *AlbumsController) checkOrigin(r *http.Request) bool {
+	origin := strings.ToLower(r.Header.Get("Origin"))
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range a.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+
+		prefix, suffix, wildcard := strings.Cut(allowed, "*")
+		if !wildcard && origin == allowed {
+			return true
+		}
+
+		if wildcard && len(origin) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// presenceRead hands the messages of the client to the hub until the
+// connection fails or stops answering the pings, then leaves the album.
+func (a *AlbumsController) presenceRead(conn *websocket.Conn, session *domain.PresenceSession) {
+	defer a.Hub.Leave(session)
+
+	pongWait := 2 * a.Presence.PingInterval
+
+	conn.SetReadLimit(presenceMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var message domain.PresenceMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			closing := websocket.FormatCloseMessage(websocket.CloseInvalidFramePayloadData, "malformed message")
+			_ = conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(presenceWriteWait))
+			return
+		}
+
+		a.Hub.Receive(session, &message)
+	}
+}
+
+// presenceWrite sends the messages of the session and the pings, the
+// connection is closed once the session ends, e.g. on shutdown.
+func (a *AlbumsController) presenceWrite(conn *websocket.Conn, session *domain.PresenceSession) {
+	ping := time.NewTicker(a.Presence.PingInterval)
+
+	defer func() {
+		ping.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-session.Messages:
+			_ = conn.SetWriteDeadline(time.Now().Add(presenceWriteWait))
+
+			if !ok {
+				closing := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+				_ = conn.WriteMessage(websocket.CloseMessage, closing)
+				return
+			}
+
+			if err := conn.WriteJSON(message); err != nil {
+				return
+			}
+
+		case <-ping.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(presenceWriteWait))
+
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	publisher "hexagony/app/publishers"
+	"hexagony/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAlbumsPresence(t *testing.T) {
+	albumUUID := uuid.New()
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+	mockAlbumUseCase.
+		On("FindByID", mock.Anything, albumUUID).
+		Return(&domain.Albums{UUID: albumUUID}, nil).Twice()
+
+	mockAlbumUseCase.
+		On("FindByID", mock.Anything, mock.Anything).
+		Return(nil, domain.ErrResourceNotFound).Once()
+
+	hub := publisher.NewHub(time.Minute)
+
+	handler := AlbumsController{
+		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
+		Hub:           hub,
+		Presence:      config.Presence{PingInterval: time.Minute},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/album/{uuid}/presence", handler.Join)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/album/"
+	dialer := websocket.Dialer{Subprotocols: []string{"presence"}}
+
+	// the album must exist
+	_, res, err := dialer.Dial(url+uuid.NewString()+"/presence", nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	first, res, err := dialer.Dial(url+albumUUID.String()+"/presence", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "presence", res.Header.Get("Sec-WebSocket-Protocol"))
+	defer first.Close()
+
+	read := func(conn *websocket.Conn) *domain.PresenceMessage {
+		var message domain.PresenceMessage
+
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		assert.NoError(t, conn.ReadJSON(&message))
+
+		return &message
+	}
+
+	assert.Len(t, read(first).Editors, 1)
+
+	second, _, err := dialer.Dial(url+albumUUID.String()+"/presence", nil)
+	assert.NoError(t, err)
+	defer second.Close()
+
+	assert.Len(t, read(first).Editors, 2)
+	assert.Len(t, read(second).Editors, 2)
+
+	// the locks are broadcast to the editors
+	assert.NoError(t, first.WriteJSON(domain.PresenceMessage{Type: domain.PresenceLock, Field: "name"}))
+
+	editors := read(second).Editors
+	assert.Equal(t, []string{"name"}, editors[0].Fields)
+	read(first)
+
+	assert.NoError(t, second.WriteJSON(domain.PresenceMessage{Type: domain.PresenceLock, Field: "name"}))
+	assert.Equal(t, domain.PresenceLockDenied, read(second).Type)
+
+	// and so are the changes of the album
+	event := &domain.Event{ID: uuid.New(), Type: domain.EventAlbumUpdated, AggregateType: "album", AggregateID: albumUUID}
+	assert.NoError(t, hub.Publish(context.Background(), event))
+
+	assert.Equal(t, event.ID, read(first).Event.ID)
+	assert.Equal(t, event.ID, read(second).Event.ID)
+
+	// a malformed message closes the connection, releasing its locks
+	assert.NoError(t, first.WriteMessage(websocket.TextMessage, []byte("{")))
+
+	_, _, err = first.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseInvalidFramePayloadData))
+
+	editors = read(second).Editors
+	assert.Len(t, editors, 1)
+	assert.Empty(t, editors[0].Fields)
+
+	// the connections are closed on shutdown
+	hub.Close()
+
+	_, _, err = second.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+
+	mockAlbumUseCase.AssertExpectations(t)
+}
+
+func TestAlbumsPresenceOrigin(t *testing.T) {
+	albumUUID := uuid.New()
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+
+	mockAlbumUseCase.
+		On("FindByID", mock.Anything, albumUUID).
+		Return(&domain.Albums{UUID: albumUUID}, nil)
+
+	hub := publisher.NewHub(time.Minute)
+	defer hub.Close()
+
+	handler := AlbumsController{
+		AlbumsUseCase:  mockAlbumUseCase,
+		Validator:      testValidator,
+		Hub:            hub,
+		Presence:       config.Presence{PingInterval: time.Minute},
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/album/{uuid}/presence", handler.Join)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/album/" + albumUUID.String() + "/presence"
+	dialer := websocket.Dialer{Subprotocols: []string{"presence"}}
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{"no origin", "", http.StatusSwitchingProtocols},
+		{"allowed origin", "https://app.example.com", http.StatusSwitchingProtocols},
+		{"allowed wildcard", "https://Edit.Example.org", http.StatusSwitchingProtocols},
+		{"other origin", "https://evil.example.net", http.StatusForbidden},
+		{"other scheme", "http://app.example.com", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+
+			conn, res, err := dialer.Dial(url, header)
+			if conn != nil {
+				conn.Close()
+			}
+
+			assert.Equal(t, tt.status == http.StatusSwitchingProtocols, err == nil)
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
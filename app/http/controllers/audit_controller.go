@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/rest"
+	"hexagony/libs/validation"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+type AuditController struct {
+	AuditUseCase domain.AuditUseCase
+	Validator    validation.Validator
+}
+
+type auditQuery struct {
+	Actor        string `json:"actor" validate:"omitempty,uuid|email"`
+	Action       string `json:"action" validate:"omitempty,max=32"`
+	ResourceType string `json:"resource_type" validate:"omitempty,oneof=album user"`
+	ResourceID   string `json:"resource_id" validate:"omitempty,max=36"`
+	From         string `json:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To           string `json:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Before       string `json:"before" validate:"omitempty,number,max=18"`
+	Limit        string `json:"limit" validate:"omitempty,number,max=4"`
+}
+
+// filter converts the validated query to the filter of the audit log.
+func (q *auditQuery) filter() domain.AuditFilter {
+	filter := domain.AuditFilter{
+		Action:       q.Action,
+		ResourceType: q.ResourceType,
+		ResourceID:   q.ResourceID,
+		Limit:        auditDefaultLimit,
+	}
+
+	if id, err := uuid.Parse(q.Actor); err == nil {
+		filter.ActorUUID = &id
+	} else {
+		filter.ActorEmail = q.Actor
+	}
+
+	if from, err := time.Parse(time.RFC3339, q.From); err == nil {
+		filter.From = &from
+	}
+	if to, err := time.Parse(time.RFC3339, q.To); err == nil {
+		filter.To = &to
+	}
+
+	filter.Before, _ = strconv.ParseInt(q.Before, 10, 64)
+
+	if limit, err := strconv.Atoi(q.Limit); err == nil && limit > 0 {
+		filter.Limit = min(limit, auditMaxLimit)
+	}
+
+	return filter
+}
+
+// Find godoc
+// @Summary      List the audit log
+// @Description  lists the audit entries newest first, the next page is linked in the Link header
+// @Tags         audit
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        actor          query     string  false  "actor uuid or email"
+// @Param        action         query     string  false  "action, e.g. update or login"
+// @Param        resource_type  query     string  false  "album or user"
+// @Param        resource_id    query     string  false  "resource uuid"
+// @Param        from           query     string  false  "RFC 3339 time, inclusive"
+// @Param        to             query     string  false  "RFC 3339 time, exclusive"
+// @Param        before         query     int     false  "entry ID the page starts after"
+// @Param        limit          query     int     false  "page size, 100 by default and 1000 at most"
+// @Success      200            {object}  []domain.AuditEntry
+// @Failure      400            {object}  response.Problem
+// @Failure      403            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /audit [get]
+func (a *AuditController) Find(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	query := auditQuery{
+		Actor:        values.Get("actor"),
+		Action:       values.Get("action"),
+		ResourceType: values.Get("resource_type"),
+		ResourceID:   values.Get("resource_id"),
+		From:         values.Get("from"),
+		To:           values.Get("to"),
+		Before:       values.Get("before"),
+		Limit:        values.Get("limit"),
+	}
+
+	if !bind(w, r, a.Validator, query) {
+		return
+	}
+
+	filter := query.filter()
+
+	entries, err := a.AuditUseCase.Find(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	if len(entries) == filter.Limit {
+		values.Set("before", strconv.FormatInt(entries[len(entries)-1].ID, 10))
+		w.Header().Set("Link", "<"+r.URL.Path+"?"+values.Encode()+`>; rel="next"`)
+	}
+
+	rest.JSON(w, http.StatusOK, &entries)
+}
+
+// Verify godoc
+// @Summary      Verify the audit log
+// @Description  checks the hash chain of the audit log, broken_at is the first entry that does not match
+// @Tags         audit
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Success      200            {object}  domain.AuditVerification
+// @Failure      403            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /audit/verify [get]
+func (a *AuditController) Verify(w http.ResponseWriter, r *http.Request) {
+	verification, err := a.AuditUseCase.Verify(r.Context())
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, verification)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditFind(t *testing.T) {
+	actor := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockAuditUseCase := new(mocks.AuditUseCase)
+
+	mockAuditUseCase.
+		On("Find", mock.Anything, domain.AuditFilter{
+			ActorUUID:    &actor,
+			ResourceType: "album",
+			From:         &from,
+			Before:       10,
+			Limit:        2,
+		}).
+		Return([]*domain.AuditEntry{{ID: 9}, {ID: 8}}, nil).Once()
+
+	mockAuditUseCase.
+		On("Find", mock.Anything, domain.AuditFilter{ActorEmail: "john@doe.com", Limit: 100}).
+		Return([]*domain.AuditEntry{{ID: 1}}, nil).Once()
+
+	handler := AuditController{
+		AuditUseCase: mockAuditUseCase,
+		Validator:    testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.Get("/audit", handler.Find)
+
+	// a full page links to the next one
+	req, err := http.NewRequest(http.MethodGet, "/audit?actor="+actor.String()+"&resource_type=album&from=2024-01-01T00:00:00Z&before=10&limit=2", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Link"), "before=8")
+	assert.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+
+	// the last page does not
+	req, err = http.NewRequest(http.MethodGet, "/audit?actor=john@doe.com", nil)
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Link"))
+
+	for _, query := range []string{"actor=john", "resource_type=song", "from=yesterday", "limit=-1"} {
+		req, err = http.NewRequest(http.MethodGet, "/audit?"+query, nil)
+		assert.NoError(t, err)
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	mockAuditUseCase.AssertExpectations(t)
+}
+
+func TestAuditVerify(t *testing.T) {
+	brokenAt := int64(3)
+	mockAuditUseCase := new(mocks.AuditUseCase)
+
+	mockAuditUseCase.
+		On("Verify", mock.Anything).
+		Return(&domain.AuditVerification{Valid: false, Entries: 3, BrokenAt: &brokenAt}, nil).Once()
+
+	handler := AuditController{AuditUseCase: mockAuditUseCase}
+
+	req, err := http.NewRequest(http.MethodGet, "/audit/verify", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	http.HandlerFunc(handler.Verify).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"valid":false,"entries":3,"broken_at":3}`, rec.Body.String())
+
+	mockAuditUseCase.AssertExpectations(t)
+}
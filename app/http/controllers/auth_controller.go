@@ -2,9 +2,9 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"hexagony/app/domain"
-	"hexagony/libs/clog"
+	"hexagony/app/http/response"
 	"hexagony/libs/rest"
 	"hexagony/libs/validation"
 	"net/http"
@@ -12,6 +12,7 @@ import (
 
 type AuthController struct {
 	AuthUseCase domain.AuthUseCase
+	Validator   validation.Validator
 }
 
 type authRequest struct {
@@ -27,24 +28,20 @@ type authRequest struct {
 // @Produce      json
 // @Param        payload  body      authRequest  true  "authenticates the user"
 // @Success      200      {object}  domain.AuthToken
-// @Failure      400      {object}  rest.Message
-// @Failure      401      {object}  rest.Message
-// @Failure      500      {object}  rest.Message
+// @Failure      400      {object}  response.Problem
+// @Failure      401      {object}  response.Problem
+// @Failure      500      {object}  response.Problem
 // @Router       /auth [post]
 func (a *AuthController) Authenticate(w http.ResponseWriter, r *http.Request) {
 	var payload authRequest
 
 	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
-		clog.Error(err, domain.ErrAuth.Error())
-		rest.DecodeError(w, r, domain.ErrAuth, http.StatusInternalServerError)
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
 		return
 	}
 
-	validation := validation.New()
-
-	if err := validation.BindStruct(r.Context(), payload); err != nil {
-		validation.DecodeError(w, err)
+	if !bind(w, r, a.Validator, payload) {
 		return
 	}
 
@@ -54,24 +51,8 @@ func (a *AuthController) Authenticate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	res, err := a.AuthUseCase.Authenticate(r.Context(), user.Email, user.Password)
-
-	userNotFound := errors.Is(err, domain.ErrAuthUserNotFound)
-	if userNotFound {
-		clog.Error(err, domain.ErrAuthUserNotFound.Error())
-		rest.DecodeError(w, r, domain.ErrAuthUserNotFound, http.StatusUnauthorized)
-		return
-	}
-
-	passwordMatch := errors.Is(err, domain.ErrAuthPassword)
-	if passwordMatch {
-		clog.Error(err, domain.ErrAuthPassword.Error())
-		rest.DecodeError(w, r, domain.ErrAuthPassword, http.StatusUnauthorized)
-		return
-	}
-
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, domain.ErrAuth, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
@@ -35,6 +35,7 @@ func TestAuthenticate(t *testing.T) {
 
 	handler := AuthController{
 		AuthUseCase: mockAuthUseCase,
+		Validator:   testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -70,6 +71,7 @@ func TestAuthenticateFail(t *testing.T) {
 
 	handler := AuthController{
 		AuthUseCase: mockAuthUseCase,
+		Validator:   testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -104,6 +106,7 @@ func TestAuthenticateFailDecode(t *testing.T) {
 
 	handler := AuthController{
 		AuthUseCase: mockAuthUseCase,
+		Validator:   testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -119,7 +122,7 @@ func TestAuthenticateFailDecode(t *testing.T) {
 	router.HandleFunc("/auth", handler.Authenticate)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
 
 func TestAuthenticateFailValidation(t *testing.T) {
@@ -134,6 +137,7 @@ func TestAuthenticateFailValidation(t *testing.T) {
 
 	handler := AuthController{
 		AuthUseCase: mockAuthUseCase,
+		Validator:   testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -165,6 +169,7 @@ func TestAuthenticateFailUser(t *testing.T) {
 
 	handler := AuthController{
 		AuthUseCase: mockAuthUseCase,
+		Validator:   testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -200,6 +205,7 @@ func TestAuthenticateFailPassword(t *testing.T) {
 
 	handler := AuthController{
 		AuthUseCase: mockAuthUseCase,
+		Validator:   testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"fmt"
+	"hexagony/app/domain"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag returns the entity tag of a resource version.
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatch returns the version a write is conditioned on by the
+// If-Match header, zero when the header is missing or is "*".
+// Only a single strong entity tag can be matched, any other
+// value is reported as a failed precondition.
+func ifMatch(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag, ok := strings.CutPrefix(header, `"`)
+	if !ok {
+		return 0, domain.ErrPreconditionFailed
+	}
+
+	tag, ok = strings.CutSuffix(tag, `"`)
+	if !ok {
+		return 0, domain.ErrPreconditionFailed
+	}
+
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("%w: invalid entity tag %s", domain.ErrPreconditionFailed, header)
+	}
+
+	return version, nil
+}
+
+// notModified reports whether the If-None-Match header matches
+// tag. As required for GET, the weak comparison is used.
+func notModified(r *http.Request, tag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+
+	return false
+}
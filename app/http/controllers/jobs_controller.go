@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/rest"
+	"hexagony/libs/validation"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	jobsDefaultLimit = 100
+	jobsMaxLimit     = 1000
+)
+
+type JobsController struct {
+	JobsUseCase domain.JobsUseCase
+	Validator   validation.Validator
+}
+
+type jobsQuery struct {
+	Status string `json:"status" validate:"omitempty,oneof=pending running succeeded failed canceled"`
+	Kind   string `json:"kind" validate:"omitempty,max=64"`
+	Before string `json:"before" validate:"omitempty,datetime=2006-01-02T15:04:05.999999999Z07:00"`
+	Limit  string `json:"limit" validate:"omitempty,number,max=4"`
+}
+
+// filter converts the validated query to the filter of the jobs.
+func (q *jobsQuery) filter() domain.JobFilter {
+	filter := domain.JobFilter{Status: q.Status, Kind: q.Kind, Limit: jobsDefaultLimit}
+
+	if before, err := time.Parse(time.RFC3339Nano, q.Before); err == nil {
+		filter.Before = &before
+	}
+
+	if limit, err := strconv.Atoi(q.Limit); err == nil && limit > 0 {
+		filter.Limit = min(limit, jobsMaxLimit)
+	}
+
+	return filter
+}
+
+// FindAll godoc
+// @Summary      List of jobs
+// @Description  lists the jobs newest first, the next page is linked in the Link header
+// @Tags         job
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        status         query     string  false  "pending, running, succeeded, failed or canceled"
+// @Param        kind           query     string  false  "kind of job"
+// @Param        before         query     string  false  "RFC 3339 creation time the page starts after"
+// @Param        limit          query     int     false  "page size, 100 by default and 1000 at most"
+// @Success      200            {object}  []domain.Job
+// @Failure      400            {object}  response.Problem
+// @Failure      403            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /jobs [get]
+func (h *JobsController) FindAll(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	query := jobsQuery{
+		Status: values.Get("status"),
+		Kind:   values.Get("kind"),
+		Before: values.Get("before"),
+		Limit:  values.Get("limit"),
+	}
+
+	if !bind(w, r, h.Validator, query) {
+		return
+	}
+
+	filter := query.filter()
+
+	jobs, err := h.JobsUseCase.FindAll(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	if len(jobs) == filter.Limit {
+		values.Set("before", jobs[len(jobs)-1].CreatedAt.Format(time.RFC3339Nano))
+		w.Header().Set("Link", "<"+r.URL.Path+"?"+values.Encode()+`>; rel="next"`)
+	}
+
+	rest.JSON(w, http.StatusOK, &jobs)
+}
+
+// FindByID godoc
+// @Summary      List a job
+// @Description  lists a job by uuid
+// @Tags         job
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "job uuid"
+// @Success      200            {object}  domain.Job
+// @Failure      400            {object}  response.Problem
+// @Failure      403            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /jobs/{uuid} [get]
+func (h *JobsController) FindByID(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	job, err := h.JobsUseCase.FindByID(r.Context(), uuid)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, job)
+}
+
+// Retry godoc
+// @Summary      Retry a job
+// @Description  runs a failed or canceled job again with all its attempts
+// @Tags         job
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "job uuid"
+// @Success      202            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      403            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      409            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /jobs/{uuid}/retry [post]
+func (h *JobsController) Retry(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	if err := h.JobsUseCase.Retry(r.Context(), uuid); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusAccepted, &rest.Message{Message: "Retry scheduled"})
+}
+
+// Cancel godoc
+// @Summary      Cancel a job
+// @Description  cancels a pending or running job, a running job finishes its attempt but its outcome is discarded
+// @Tags         job
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "job uuid"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      403            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      409            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /jobs/{uuid}/cancel [post]
+func (h *JobsController) Cancel(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	if err := h.JobsUseCase.Cancel(r.Context(), uuid); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Canceled"})
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestJobsFindAll(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockJobUseCase := new(mocks.JobUseCase)
+
+	mockJobUseCase.
+		On("FindAll", mock.Anything, domain.JobFilter{Status: domain.JobFailed, Kind: "albums.export", Limit: 1}).
+		Return([]*domain.Job{{UUID: uuid.New(), CreatedAt: createdAt}}, nil).Once()
+
+	handler := JobsController{JobsUseCase: mockJobUseCase, Validator: testValidator}
+
+	router := chi.NewRouter()
+	router.Get("/jobs", handler.FindAll)
+
+	// a full page links to the next one
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs?status=failed&kind=albums.export&limit=1", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Link"), "before=2024-01-01T00%3A00%3A00Z")
+
+	for _, query := range []string{"status=lost", "before=yesterday", "limit=-1"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs?"+query, nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	mockJobUseCase.AssertExpectations(t)
+}
+
+func TestJobsRetryAndCancel(t *testing.T) {
+	id := uuid.New()
+	mockJobUseCase := new(mocks.JobUseCase)
+
+	mockJobUseCase.On("Retry", mock.Anything, id).Return(nil).Once()
+	mockJobUseCase.On("Cancel", mock.Anything, id).Return(domain.ErrJobsState).Once()
+
+	handler := JobsController{JobsUseCase: mockJobUseCase, Validator: testValidator}
+
+	router := chi.NewRouter()
+	router.Post("/jobs/{uuid}/retry", handler.Retry)
+	router.Post("/jobs/{uuid}/cancel", handler.Cancel)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs/"+id.String()+"/retry", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	// a finished job can not be canceled
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs/"+id.String()+"/cancel", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs/1/cancel", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockJobUseCase.AssertExpectations(t)
+}
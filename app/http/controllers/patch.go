@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hexagony/app/domain"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Media types of the PATCH request bodies.
+const (
+	mergePatchType = "application/merge-patch+json" // RFC 7396
+	jsonPatchType  = "application/json-patch+json"  // RFC 6902
+)
+
+// applyPatch applies the patch document in the request body to
+// payload, which holds the current state of the resource. The kind
+// of patch is chosen from the Content-Type header.
+func applyPatch(r *http.Request, payload interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrPatchMediaType, err)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrPatchMalformed, err)
+	}
+
+	doc, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	switch mediaType {
+	case mergePatchType:
+		if !json.Valid(body) {
+			return domain.ErrPatchMalformed
+		}
+
+		doc, err = jsonpatch.MergePatch(doc, body)
+		if err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrPatchUnprocessable, err)
+		}
+	case jsonPatchType:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrPatchMalformed, err)
+		}
+
+		doc, err = patch.Apply(doc)
+		if err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrPatchUnprocessable, err)
+		}
+	default:
+		return domain.ErrPatchMediaType
+	}
+
+	// the members removed by the patch must not keep their
+	// current values, so the payload is decoded from scratch
+	reflect.ValueOf(payload).Elem().SetZero()
+
+	// the patched document must still be a valid payload,
+	// e.g. it can not add the id or any unknown member
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(payload); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrPatchUnprocessable, err)
+	}
+
+	return nil
+}
@@ -2,21 +2,21 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"hexagony/app/domain"
-	"hexagony/libs/clog"
+	"hexagony/app/http/response"
 	"hexagony/libs/crypto"
 	"hexagony/libs/rest"
 	"hexagony/libs/validation"
 	"net/http"
 	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type UsersController struct {
 	UsersUseCase domain.UsersUseCase
+	Validator    validation.Validator
 }
 
 type createUserRequest struct {
@@ -38,13 +38,12 @@ type updateUserRequest struct {
 // @Produce      json
 // @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Success      200            {object}  []domain.User
-// @Failure      500            {object}  rest.Message
+// @Failure      500            {object}  response.Problem
 // @Router       /user [get]
 func (u *UsersController) FindAll(w http.ResponseWriter, r *http.Request) {
 	users, err := u.UsersUseCase.FindAll(r.Context())
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
@@ -59,30 +58,30 @@ func (u *UsersController) FindAll(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        uuid           path      string  true  "user uuid"
+// @Param        If-None-Match  header    string  false  "entity tag of a cached version"
 // @Success      200            {object}  domain.User
-// @Failure      404            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Success      304
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /user/{uuid} [get]
 func (u *UsersController) FindByID(w http.ResponseWriter, r *http.Request) {
-	uuid, err := uuid.Parse(chi.URLParam(r, "uuid"))
-	if err != nil {
-		clog.Error(err, domain.ErrUsersUUIDParse.Error())
-		rest.DecodeError(w, r, domain.ErrUsersFindByID, http.StatusInternalServerError)
+	uuid, ok := uuidParam(w, r, u.Validator)
+	if !ok {
 		return
 	}
 
 	user, err := u.UsersUseCase.FindByID(r.Context(), uuid)
-
-	exists := errors.Is(err, domain.ErrResourceNotFound)
-	if exists {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusNotFound)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
-	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+	tag := etag(user.Version)
+	w.Header().Set("ETag", tag)
+
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -98,25 +97,21 @@ func (u *UsersController) FindByID(w http.ResponseWriter, r *http.Request) {
 // @Param        Authorization  header    string             true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        payload        body      createUserRequest  true  "add a new user"
 // @Success      201            {object}  rest.Message
-// @Failure      400            {object}  rest.Message
-// @Failure      409            {object}  rest.Message
-// @Failure      422            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      409            {object}  response.Problem
+// @Failure      422            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /user [post]
 func (u *UsersController) Add(w http.ResponseWriter, r *http.Request) {
 	var payload createUserRequest
 
 	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, domain.ErrUsersAdd, http.StatusInternalServerError)
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
 		return
 	}
 
-	validation := validation.New()
-
-	if err := validation.BindStruct(r.Context(), payload); err != nil {
-		validation.DecodeError(w, err)
+	if !bind(w, r, u.Validator, payload) {
 		return
 	}
 
@@ -124,8 +119,7 @@ func (u *UsersController) Add(w http.ResponseWriter, r *http.Request) {
 
 	hashPass, err := bcrypt.HashPassword(payload.Password, 10)
 	if err != nil {
-		clog.Error(err, domain.ErrUsersHashPassword.Error())
-		rest.DecodeError(w, r, domain.ErrUsersAdd, http.StatusUnprocessableEntity)
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrUsersHashPassword, err))
 		return
 	}
 
@@ -139,18 +133,8 @@ func (u *UsersController) Add(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = u.UsersUseCase.Add(r.Context(), &user)
-
-	isDuplicate := errors.Is(err, domain.ErrUsersDuplicateEmail)
-
-	if isDuplicate {
-		clog.Error(err, domain.ErrUsersDuplicateEmail.Error())
-		rest.DecodeError(w, r, domain.ErrUsersDuplicateEmail, http.StatusConflict)
-		return
-	}
-
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
@@ -165,95 +149,217 @@ func (u *UsersController) Add(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        Authorization  header    string             true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        uuid           path      string             true  "user uuid"
+// @Param        If-Match       header    string             false  "entity tag of the version being updated"
 // @Param        payload        body      updateUserRequest  true  "update an user by uuid"
 // @Success      200            {object}  rest.Message
-// @Failure      400            {object}  rest.Message
-// @Failure      404            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      412            {object}  response.Problem
+// @Failure      428            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /user/{uuid} [put]
 func (u *UsersController) Update(w http.ResponseWriter, r *http.Request) {
-	uuid, err := uuid.Parse(chi.URLParam(r, "uuid"))
-	if err != nil {
-		clog.Error(err, domain.ErrUsersUUIDParse.Error())
-		rest.DecodeError(w, r, domain.ErrUsersUpdate, http.StatusInternalServerError)
+	uuid, ok := uuidParam(w, r, u.Validator)
+	if !ok {
 		return
 	}
 
 	var payload updateUserRequest
 
-	err = json.NewDecoder(r.Body).Decode(&payload)
+	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
 		return
 	}
 
-	validation := validation.New()
+	if !bind(w, r, u.Validator, payload) {
+		return
+	}
 
-	if err := validation.BindStruct(r.Context(), payload); err != nil {
-		clog.Error(err, domain.ErrUsersUpdate.Error())
-		validation.DecodeError(w, err)
+	version, err := ifMatch(r)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
 	user := domain.Users{
 		Name:      payload.Name,
 		Email:     payload.Email,
+		Version:   version,
 		UpdatedAt: time.Now(),
 	}
 
 	err = u.UsersUseCase.Update(r.Context(), uuid, &user)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
 
-	exists := errors.Is(err, domain.ErrResourceNotFound)
-	if exists {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusNotFound)
+	w.Header().Set("ETag", etag(user.Version))
+
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Updated"})
+}
+
+// Patch godoc
+// @Summary      Patch an user
+// @Description  partially update an user by uuid with a merge patch (RFC 7396) or a JSON patch (RFC 6902)
+// @Tags         user
+// @Accept       application/merge-patch+json,application/json-patch+json
+// @Produce      json
+// @Param        Authorization  header    string             true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string             true  "user uuid"
+// @Param        If-Match       header    string             false  "entity tag of the version being patched"
+// @Param        payload        body      updateUserRequest  true  "patch document"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      409            {object}  response.Problem
+// @Failure      412            {object}  response.Problem
+// @Failure      415            {object}  response.Problem
+// @Failure      422            {object}  response.Problem
+// @Failure      428            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /user/{uuid} [patch]
+func (u *UsersController) Patch(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, u.Validator)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
+	user, err := u.UsersUseCase.FindByID(r.Context(), uuid)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
+		return
+	}
+
+	if version != 0 && version != user.Version {
+		response.Error(w, r, domain.ErrPreconditionFailed)
+		return
+	}
+
+	payload := updateUserRequest{
+		Name:  user.Name,
+		Email: user.Email,
+	}
+
+	if err := applyPatch(r, &payload); err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
+	if !bind(w, r, u.Validator, payload) {
+		return
+	}
+
+	// the patch was applied to the version read above, so
+	// it is only written if nobody changed the user since
+	patch := domain.UsersPatch{UpdatedAt: time.Now(), Version: user.Version}
+
+	if payload.Name != user.Name {
+		patch.Name = &payload.Name
+	}
+	if payload.Email != user.Email {
+		patch.Email = &payload.Email
+	}
+
+	err = u.UsersUseCase.Patch(r.Context(), uuid, &patch)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(patch.Version))
+
 	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Updated"})
 }
 
 // Update godoc
 // @Summary      Delete an user
-// @Description  delete an user by uuid
+// @Description  move an user to the trash by uuid
 // @Tags         user
 // @Accept       json
 // @Produce      json
 // @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
 // @Param        uuid           path      string  true  "user uuid"
+// @Param        If-Match       header    string  false  "entity tag of the version being deleted"
 // @Success      200            {object}  rest.Message
-// @Failure      404            {object}  rest.Message
-// @Failure      500            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      412            {object}  response.Problem
+// @Failure      428            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
 // @Router       /user/{uuid} [delete]
 func (u *UsersController) Delete(w http.ResponseWriter, r *http.Request) {
-	uuid, err := uuid.Parse(chi.URLParam(r, "uuid"))
+	uuid, ok := uuidParam(w, r, u.Validator)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	err = u.UsersUseCase.Delete(r.Context(), uuid, version)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Deleted"})
+}
+
+// Trash godoc
+// @Summary      List of deleted users
+// @Description  lists the deleted users that were not purged yet
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Success      200            {object}  []domain.User
+// @Failure      500            {object}  response.Problem
+// @Router       /user/trash [get]
+func (u *UsersController) Trash(w http.ResponseWriter, r *http.Request) {
+	users, err := u.UsersUseCase.FindTrash(r.Context())
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
-	err = u.UsersUseCase.Delete(r.Context(), uuid)
+	rest.JSON(w, http.StatusOK, &users)
+}
 
-	exists := errors.Is(err, domain.ErrResourceNotFound)
-	if exists {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusNotFound)
+// Restore godoc
+// @Summary      Restore an user
+// @Description  restore a deleted user by uuid
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "user uuid"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /user/{uuid}/restore [post]
+func (u *UsersController) Restore(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, u.Validator)
+	if !ok {
 		return
 	}
 
+	err := u.UsersUseCase.Restore(r.Context(), uuid)
 	if err != nil {
-		clog.Error(err, err.Error())
-		rest.DecodeError(w, r, err, http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
-	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Deleted"})
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Restored"})
 }
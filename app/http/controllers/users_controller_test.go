@@ -37,6 +37,7 @@ func TestFindAll(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -63,6 +64,7 @@ func TestUsersFindAllFail(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -99,6 +101,7 @@ func TestUsersFetchByID(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -126,6 +129,7 @@ func TestUsersFetchByIDFail(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -156,7 +160,7 @@ func TestUsersFetchByIDFail(t *testing.T) {
 	router.HandleFunc("/user/{uuid}", handler.FindByID)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockUserUseCase.AssertExpectations(t)
 }
@@ -171,6 +175,7 @@ func TestUsersFetchByIDFailResource(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -208,6 +213,7 @@ func TestUsersAdd(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -237,6 +243,7 @@ func TestUsersAddFail(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -275,7 +282,7 @@ func TestUsersAddFail(t *testing.T) {
 	router.HandleFunc("/user", handler.Add)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockUserUseCase.AssertExpectations(t)
 
@@ -309,6 +316,7 @@ func TestUsersAddFailDuplicate(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -350,6 +358,7 @@ func TestUsersUpdate(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -388,6 +397,7 @@ func TestUsersUpdateFail(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -421,7 +431,7 @@ func TestUsersUpdateFail(t *testing.T) {
 	router.HandleFunc("/user/{uuid}", handler.Update)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockUserUseCase.AssertExpectations(t)
 
@@ -441,7 +451,7 @@ func TestUsersUpdateFail(t *testing.T) {
 	router.HandleFunc("/user/{uuid}", handler.Update)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockUserUseCase.AssertExpectations(t)
 
@@ -484,6 +494,7 @@ func TestUsersUpdateFailResource(t *testing.T) {
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -504,16 +515,69 @@ func TestUsersUpdateFailResource(t *testing.T) {
 	mockUserUseCase.AssertExpectations(t)
 }
 
+func TestUsersPatch(t *testing.T) {
+	newUUID := uuid.New()
+	mockUserUseCase := new(mocks.UserUseCase)
+
+	mockUser := &domain.UsersList{UUID: newUUID, Name: "John Doe", Email: "john@doe.com"}
+
+	mockUserUseCase.
+		On("FindByID", mock.Anything, newUUID).
+		Return(mockUser, nil)
+
+	// only the changed columns are sent to the use case
+	mockUserUseCase.
+		On("Patch", mock.Anything, newUUID, mock.MatchedBy(func(p *domain.UsersPatch) bool {
+			return p.Name != nil && *p.Name == "Jane Doe" && p.Email == nil
+		})).
+		Return(nil).Once()
+
+	handler := UsersController{
+		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.HandleFunc("/user/{uuid}", handler.Patch)
+
+	req, err := http.NewRequest(http.MethodPatch, "/user/"+newUUID.String(), bytes.NewBufferString(`{"name":"Jane Doe"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", mergePatchType)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// duplicate email
+
+	mockUserUseCase.
+		On("Patch", mock.Anything, newUUID, mock.Anything).
+		Return(domain.ErrUsersDuplicateEmail).Once()
+
+	req, err = http.NewRequest(http.MethodPatch, "/user/"+newUUID.String(), bytes.NewBufferString(`[{"op":"replace","path":"/email","value":"jane@doe.com"}]`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", jsonPatchType)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	mockUserUseCase.AssertExpectations(t)
+}
+
 func TestUsersDelete(t *testing.T) {
 	newUUID := uuid.New()
 	mockUserUseCase := new(mocks.UserUseCase)
 
 	mockUserUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(nil)
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -536,11 +600,12 @@ func TestUsersDeleteFail(t *testing.T) {
 	mockUserUseCase := new(mocks.UserUseCase)
 
 	mockUserUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(domain.ErrUsersDelete)
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -560,7 +625,7 @@ func TestUsersDeleteFail(t *testing.T) {
 	// err uuid parsing
 
 	mockUserUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(nil, domain.ErrUsersUUIDParse)
 
 	req, err = http.NewRequest(http.MethodDelete, "/user/{uuid}", nil)
@@ -571,14 +636,14 @@ func TestUsersDeleteFail(t *testing.T) {
 	router.HandleFunc("/user/{uuid}", handler.Delete)
 	router.ServeHTTP(rec, req)
 
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	mockUserUseCase.AssertExpectations(t)
 
 	// err decoding json
 
 	mockUserUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(domain.ErrUsersDelete)
 
 	mockUser2 := []byte(`{"id":"1"}`)
@@ -601,11 +666,12 @@ func TestUsersDeleteFailResource(t *testing.T) {
 	mockUserUseCase := new(mocks.UserUseCase)
 
 	mockUserUseCase.
-		On("Delete", mock.Anything, mock.Anything).
+		On("Delete", mock.Anything, mock.Anything, mock.Anything).
 		Return(domain.ErrResourceNotFound)
 
 	handler := UsersController{
 		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
 	}
 
 	router := chi.NewRouter()
@@ -622,3 +688,43 @@ func TestUsersDeleteFailResource(t *testing.T) {
 
 	mockUserUseCase.AssertExpectations(t)
 }
+
+func TestUsersTrash(t *testing.T) {
+	newUUID := uuid.New()
+	mockUserUseCase := new(mocks.UserUseCase)
+
+	mockUserUseCase.
+		On("FindTrash", mock.Anything).
+		Return(nil, domain.ErrUsersFindTrash)
+
+	mockUserUseCase.
+		On("Restore", mock.Anything, newUUID).
+		Return(nil)
+
+	handler := UsersController{
+		UsersUseCase: mockUserUseCase,
+		Validator:    testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.Get("/user/trash", handler.Trash)
+	router.Post("/user/{uuid}/restore", handler.Restore)
+
+	req, err := http.NewRequest(http.MethodGet, "/user/trash", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	req, err = http.NewRequest(http.MethodPost, "/user/"+newUUID.String()+"/restore", nil)
+	assert.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	mockUserUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/validation"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ValidationRules are the domain rules used by the request payloads.
+var ValidationRules = []validation.Rule{
+	{
+		Tag:  "album_length",
+		Func: isAlbumLength,
+		Messages: map[string]string{
+			"en": fmt.Sprintf("{0} must be between %d and %d minutes",
+				domain.AlbumMinLength, domain.AlbumMaxLength),
+			"pt_BR": fmt.Sprintf("{0} deve estar entre %d e %d minutos",
+				domain.AlbumMinLength, domain.AlbumMaxLength),
+			"pl": fmt.Sprintf("{0} musi mieć od %d do %d minut",
+				domain.AlbumMinLength, domain.AlbumMaxLength),
+		},
+	},
+	{
+		Tag:  "event_type",
+		Func: isEventType,
+		Messages: map[string]string{
+			"en":    "{0} must be one of " + strings.Join(domain.WebhookEventTypes, ", "),
+			"pt_BR": "{0} deve ser um de " + strings.Join(domain.WebhookEventTypes, ", "),
+			"pl":    "{0} musi być jednym z " + strings.Join(domain.WebhookEventTypes, ", "),
+		},
+	},
+}
+
+func isAlbumLength(fl validator.FieldLevel) bool {
+	length := fl.Field().Int()
+	return length >= domain.AlbumMinLength && length <= domain.AlbumMaxLength
+}
+
+func isEventType(fl validator.FieldLevel) bool {
+	for _, eventType := range domain.WebhookEventTypes {
+		if fl.Field().String() == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bind validates the payload and writes the validation
+// problem, in the language asked by the client, if it is invalid.
+func bind(w http.ResponseWriter, r *http.Request, v validation.Validator, payload interface{}) bool {
+	if err := v.BindStruct(r.Context(), payload); err != nil {
+		response.Validation(w, r, v.InvalidParams(err, r.Header.Get("Accept-Language")))
+		return false
+	}
+
+	return true
+}
+
+// uuidParam validates and parses the uuid URL parameter,
+// writing the validation problem if it is not a valid UUID.
+func uuidParam(w http.ResponseWriter, r *http.Request, v validation.Validator) (uuid.UUID, bool) {
+	return routeUUID(w, r, v, "uuid")
+}
+
+// revisionParam validates the revision route parameter, a positive number.
+func revisionParam(w http.ResponseWriter, r *http.Request, v validation.Validator) (int, bool) {
+	param := chi.URLParam(r, "revision")
+
+	// the length bounds the revision before it is parsed
+	if err := v.BindField(r.Context(), "revision", param, "required,number,max=9"); err != nil {
+		response.Validation(w, r, v.InvalidParams(err, r.Header.Get("Accept-Language")))
+		return 0, false
+	}
+
+	revision, err := strconv.Atoi(param)
+	if err != nil {
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrValidation, err))
+		return 0, false
+	}
+
+	if err := v.BindField(r.Context(), "revision", revision, "required,numeric,min=1"); err != nil {
+		response.Validation(w, r, v.InvalidParams(err, r.Header.Get("Accept-Language")))
+		return 0, false
+	}
+
+	return revision, true
+}
+
+// deliveryParam validates and parses the delivery uuid URL parameter.
+func deliveryParam(w http.ResponseWriter, r *http.Request, v validation.Validator) (uuid.UUID, bool) {
+	return routeUUID(w, r, v, "delivery")
+}
+
+// routeUUID validates and parses the UUID of the name URL parameter.
+// The validator only accepts lowercase hexadecimal digits, the UUIDs
+// being case insensitive the parameter is lowercased first.
+func routeUUID(w http.ResponseWriter, r *http.Request, v validation.Validator, name string) (uuid.UUID, bool) {
+	param := strings.ToLower(chi.URLParam(r, name))
+
+	if err := v.BindField(r.Context(), name, param, "required,uuid"); err != nil {
+		response.Validation(w, r, v.InvalidParams(err, r.Header.Get("Accept-Language")))
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(param)
+	if err != nil {
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrValidation, err))
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/libs/problem"
+	"hexagony/libs/validation"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testValidator validation.Validator
+
+func init() {
+	v, err := validation.New(ValidationRules...)
+	if err != nil {
+		panic(err)
+	}
+	testValidator = v
+}
+
+func TestValidationAlbumRules(t *testing.T) {
+	handler := AlbumsController{
+		AlbumsUseCase: new(mocks.AlbumUseCase),
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.HandleFunc("/album", handler.Add)
+
+	payload := []byte(`{"name":"Nova Era","length":601,"barcode":"4006381333932"}`)
+
+	req, err := http.NewRequest(http.MethodPost, "/album", bytes.NewBuffer(payload))
+	assert.NoError(t, err)
+	req.Header.Set("Accept-Language", "fr;q=0.9, pt-BR")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+
+	assert.Equal(t, []*problem.InvalidParam{
+		{Name: "length", Reason: "length deve estar entre 1 e 600 minutos"},
+		{Name: "barcode", Reason: "barcode deve ser um código de barras EAN-13 ou UPC-A válido"},
+	}, p.InvalidParams)
+}
+
+func TestValidationUUIDParam(t *testing.T) {
+	handler := AlbumsController{
+		AlbumsUseCase: new(mocks.AlbumUseCase),
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.HandleFunc("/album/{uuid}", handler.FindByID)
+
+	req, err := http.NewRequest(http.MethodGet, "/album/not-an-uuid", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+
+	assert.Equal(t, []*problem.InvalidParam{
+		{Name: "uuid", Reason: "uuid must be a valid UUID"},
+	}, p.InvalidParams)
+}
+
+func TestValidationUppercaseUUIDParam(t *testing.T) {
+	id := uuid.New()
+
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+	mockAlbumUseCase.On("FindByID", mock.Anything, id).Return(nil, domain.ErrResourceNotFound)
+
+	handler := AlbumsController{
+		AlbumsUseCase: mockAlbumUseCase,
+		Validator:     testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.HandleFunc("/album/{uuid}", handler.FindByID)
+
+	req, err := http.NewRequest(http.MethodGet, "/album/"+strings.ToUpper(id.String()), nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockAlbumUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,334 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/rest"
+	"hexagony/libs/validation"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	deliveriesDefaultLimit = 100
+	deliveriesMaxLimit     = 1000
+)
+
+type WebhooksController struct {
+	WebhooksUseCase domain.WebhooksUseCase
+	Validator       validation.Validator
+}
+
+type webhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,event_type"`
+	Active     *bool    `json:"active"`
+}
+
+// createdWebhook is a created webhook, the only time its secret is shown.
+type createdWebhook struct {
+	*domain.Webhook
+	Secret string `json:"secret"`
+}
+
+type deliveriesQuery struct {
+	Status string `json:"status" validate:"omitempty,oneof=pending succeeded dead"`
+	Before string `json:"before" validate:"omitempty,datetime=2006-01-02T15:04:05.999999999Z07:00"`
+	Limit  string `json:"limit" validate:"omitempty,number,max=4"`
+}
+
+// filter converts the validated query to the filter of the deliveries.
+func (q *deliveriesQuery) filter() domain.WebhookDeliveryFilter {
+	filter := domain.WebhookDeliveryFilter{Status: q.Status, Limit: deliveriesDefaultLimit}
+
+	if before, err := time.Parse(time.RFC3339Nano, q.Before); err == nil {
+		filter.Before = &before
+	}
+
+	if limit, err := strconv.Atoi(q.Limit); err == nil && limit > 0 {
+		filter.Limit = min(limit, deliveriesMaxLimit)
+	}
+
+	return filter
+}
+
+// decodeWebhook decodes and validates the webhook of the request body,
+// writing the problem if it is invalid. The webhooks are active unless
+// told otherwise.
+func (h *WebhooksController) decodeWebhook(w http.ResponseWriter, r *http.Request) (*domain.Webhook, bool) {
+	var payload webhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		response.Error(w, r, fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err))
+		return nil, false
+	}
+
+	if !bind(w, r, h.Validator, payload) {
+		return nil, false
+	}
+
+	webhook := &domain.Webhook{URL: payload.URL, EventTypes: payload.EventTypes, Active: true, UpdatedAt: time.Now()}
+	if payload.Active != nil {
+		webhook.Active = *payload.Active
+	}
+
+	return webhook, true
+}
+
+// FindAll godoc
+// @Summary      List of webhooks
+// @Description  lists all webhooks
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Success      200            {object}  []domain.Webhook
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook [get]
+func (h *WebhooksController) FindAll(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.WebhooksUseCase.FindAll(r.Context())
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, &webhooks)
+}
+
+// FindByID godoc
+// @Summary      List a webhook
+// @Description  lists a webhook by uuid
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "webhook uuid"
+// @Success      200            {object}  domain.Webhook
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook/{uuid} [get]
+func (h *WebhooksController) FindByID(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.WebhooksUseCase.FindByID(r.Context(), uuid)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, webhook)
+}
+
+// Add godoc
+// @Summary      Add a webhook
+// @Description  add a webhook notified of the chosen event types, the response holds the secret signing its deliveries
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string          true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        payload        body      webhookRequest  true  "add a webhook"
+// @Success      201            {object}  createdWebhook
+// @Failure      400            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook [post]
+func (h *WebhooksController) Add(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	webhook.UUID, webhook.CreatedAt = uuid.New(), webhook.UpdatedAt
+
+	if err := h.WebhooksUseCase.Add(r.Context(), webhook); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusCreated, &createdWebhook{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// Update godoc
+// @Summary      Update a webhook
+// @Description  update the URL, the event types and the state of a webhook by uuid
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string          true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string          true  "webhook uuid"
+// @Param        payload        body      webhookRequest  true  "update a webhook by uuid"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook/{uuid} [put]
+func (h *WebhooksController) Update(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	webhook, ok := h.decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.WebhooksUseCase.Update(r.Context(), uuid, webhook); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Updated"})
+}
+
+// Delete godoc
+// @Summary      Delete a webhook
+// @Description  delete a webhook by uuid along with its deliveries
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "webhook uuid"
+// @Success      200            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook/{uuid} [delete]
+func (h *WebhooksController) Delete(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	if err := h.WebhooksUseCase.Delete(r.Context(), uuid); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, &rest.Message{Message: "Deleted"})
+}
+
+// Deliveries godoc
+// @Summary      List the deliveries of a webhook
+// @Description  lists the deliveries of a webhook newest first, the next page is linked in the Link header
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true   "webhook uuid"
+// @Param        status         query     string  false  "pending, succeeded or dead"
+// @Param        before         query     string  false  "RFC 3339 creation time the page starts after"
+// @Param        limit          query     int     false  "page size, 100 by default and 1000 at most"
+// @Success      200            {object}  []domain.WebhookDelivery
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook/{uuid}/deliveries [get]
+func (h *WebhooksController) Deliveries(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	values := r.URL.Query()
+
+	query := deliveriesQuery{
+		Status: values.Get("status"),
+		Before: values.Get("before"),
+		Limit:  values.Get("limit"),
+	}
+
+	if !bind(w, r, h.Validator, query) {
+		return
+	}
+
+	filter := query.filter()
+
+	deliveries, err := h.WebhooksUseCase.FindDeliveries(r.Context(), uuid, filter)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	if len(deliveries) == filter.Limit {
+		values.Set("before", deliveries[len(deliveries)-1].CreatedAt.Format(time.RFC3339Nano))
+		w.Header().Set("Link", "<"+r.URL.Path+"?"+values.Encode()+`>; rel="next"`)
+	}
+
+	rest.JSON(w, http.StatusOK, &deliveries)
+}
+
+// Delivery godoc
+// @Summary      List a delivery of a webhook
+// @Description  lists a delivery of a webhook along with the log of its attempts
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "webhook uuid"
+// @Param        delivery       path      string  true  "delivery uuid"
+// @Success      200            {object}  domain.WebhookDelivery
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook/{uuid}/deliveries/{delivery} [get]
+func (h *WebhooksController) Delivery(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	delivery, ok := deliveryParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	found, err := h.WebhooksUseCase.FindDelivery(r.Context(), uuid, delivery)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusOK, found)
+}
+
+// Redeliver godoc
+// @Summary      Redeliver a webhook delivery
+// @Description  attempts a delivery again, with all its attempts, whatever its status
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Insert your access token"  default(Bearer <Add access token here>)
+// @Param        uuid           path      string  true  "webhook uuid"
+// @Param        delivery       path      string  true  "delivery uuid"
+// @Success      202            {object}  rest.Message
+// @Failure      400            {object}  response.Problem
+// @Failure      404            {object}  response.Problem
+// @Failure      500            {object}  response.Problem
+// @Router       /webhook/{uuid}/deliveries/{delivery}/redeliver [post]
+func (h *WebhooksController) Redeliver(w http.ResponseWriter, r *http.Request) {
+	uuid, ok := uuidParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	delivery, ok := deliveryParam(w, r, h.Validator)
+	if !ok {
+		return
+	}
+
+	if err := h.WebhooksUseCase.Redeliver(r.Context(), uuid, delivery); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rest.JSON(w, http.StatusAccepted, &rest.Message{Message: "Redelivery scheduled"})
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhooksAdd(t *testing.T) {
+	mockWebhookUseCase := new(mocks.WebhookUseCase)
+
+	mockWebhookUseCase.
+		On("Add", mock.Anything, mock.MatchedBy(func(webhook *domain.Webhook) bool {
+			return webhook.URL == "https://example.com/hook" && webhook.Active && len(webhook.EventTypes) == 2
+		})).
+		Return(func(ctx context.Context, webhook *domain.Webhook) error {
+			webhook.Secret = "whsec_test"
+			return nil
+		}).Once()
+
+	handler := WebhooksController{
+		WebhooksUseCase: mockWebhookUseCase,
+		Validator:       testValidator,
+	}
+
+	router := chi.NewRouter()
+	router.Post("/webhook", handler.Add)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook",
+		strings.NewReader(`{"url":"https://example.com/hook","event_types":["album.created","user.deleted"]}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	// the secret is only shown on creation
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "whsec_test", body["secret"])
+	assert.NotEmpty(t, body["id"])
+
+	for _, payload := range []string{
+		`{"url":"ftp://example.com","event_types":["album.created"]}`,
+		`{"url":"https://example.com/hook","event_types":[]}`,
+		`{"url":"https://example.com/hook","event_types":["album.played"]}`,
+	} {
+		req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, payload)
+	}
+
+	mockWebhookUseCase.AssertExpectations(t)
+}
+
+func TestWebhooksFindByID(t *testing.T) {
+	webhookUUID := uuid.New()
+	mockWebhookUseCase := new(mocks.WebhookUseCase)
+
+	mockWebhookUseCase.
+		On("FindByID", mock.Anything, webhookUUID).
+		Return(&domain.Webhook{UUID: webhookUUID, URL: "https://example.com/hook", Secret: "whsec_test"}, nil).Once()
+
+	handler := WebhooksController{WebhooksUseCase: mockWebhookUseCase, Validator: testValidator}
+
+	router := chi.NewRouter()
+	router.Get("/webhook/{uuid}", handler.FindByID)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook/"+webhookUUID.String(), nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "whsec_test")
+
+	mockWebhookUseCase.AssertExpectations(t)
+}
+
+func TestWebhooksDeliveries(t *testing.T) {
+	webhookUUID := uuid.New()
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockWebhookUseCase := new(mocks.WebhookUseCase)
+
+	mockWebhookUseCase.
+		On("FindDeliveries", mock.Anything, webhookUUID, domain.WebhookDeliveryFilter{Status: domain.DeliveryDead, Limit: 1}).
+		Return([]*domain.WebhookDelivery{{UUID: uuid.New(), CreatedAt: createdAt}}, nil).Once()
+
+	handler := WebhooksController{WebhooksUseCase: mockWebhookUseCase, Validator: testValidator}
+
+	router := chi.NewRouter()
+	router.Get("/webhook/{uuid}/deliveries", handler.Deliveries)
+
+	// a full page links to the next one
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook/"+webhookUUID.String()+"/deliveries?status=dead&limit=1", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Link"), "before=2024-01-01T12%3A00%3A00Z")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook/"+webhookUUID.String()+"/deliveries?status=lost", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockWebhookUseCase.AssertExpectations(t)
+}
+
+func TestWebhooksRedeliver(t *testing.T) {
+	webhookUUID, deliveryUUID := uuid.New(), uuid.New()
+	mockWebhookUseCase := new(mocks.WebhookUseCase)
+
+	mockWebhookUseCase.On("Redeliver", mock.Anything, webhookUUID, deliveryUUID).Return(nil).Once()
+	mockWebhookUseCase.On("Redeliver", mock.Anything, webhookUUID, mock.Anything).Return(domain.ErrResourceNotFound).Once()
+
+	handler := WebhooksController{WebhooksUseCase: mockWebhookUseCase, Validator: testValidator}
+
+	router := chi.NewRouter()
+	router.Post("/webhook/{uuid}/deliveries/{delivery}/redeliver", handler.Redeliver)
+
+	path := "/webhook/" + webhookUUID.String() + "/deliveries/"
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path+deliveryUUID.String()+"/redeliver", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path+uuid.NewString()+"/redeliver", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path+"1/redeliver", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockWebhookUseCase.AssertExpectations(t)
+}
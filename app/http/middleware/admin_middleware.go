@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/config"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// AdminMiddleware lets through the requests of the administrators
+// listed in the admin configuration, the actor being set by
+// AuthMiddleware. Every request is refused when none is listed.
+func AdminMiddleware(adminConfig config.Admin) func(http.Handler) http.Handler {
+	admins := make(map[uuid.UUID]bool, len(adminConfig.Users))
+	for _, user := range adminConfig.Users {
+		// The UUIDs are checked when the configuration is loaded.
+		if id, err := uuid.Parse(user); err == nil {
+			admins[id] = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor, ok := domain.ActorFromContext(r.Context())
+			if !ok || !admins[actor.UUID] {
+				response.Error(w, r, domain.ErrAdminRequired)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"hexagony/app/domain"
+	"hexagony/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	admin := uuid.New()
+
+	handler := AdminMiddleware(config.Admin{Users: []string{admin.String()}})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	send := func(actor *domain.Actor) int {
+		req := httptest.NewRequest(http.MethodGet, "/audit", nil)
+		if actor != nil {
+			req = req.WithContext(domain.NewActorContext(req.Context(), *actor))
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(&domain.Actor{UUID: admin}))
+	assert.Equal(t, http.StatusForbidden, send(&domain.Actor{UUID: uuid.New()}))
+	assert.Equal(t, http.StatusForbidden, send(nil))
+}
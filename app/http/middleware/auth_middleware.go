@@ -3,64 +3,75 @@ package middleware
 import (
 	"errors"
 	"fmt"
-	"hexagony/libs/rest"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/config"
+	"hexagony/libs/bearer"
 	"net/http"
-	"os"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v4"
-)
-
-var (
-	errEmptyToken     = errors.New("empty token")
-	errMalformedToken = errors.New("malformed token")
-	errInvalidToken   = errors.New("invalid token")
-	errUnexpected     = errors.New("unexpected error")
+	"github.com/gorilla/websocket"
 )
 
 // AuthMiddleware checks if the request contains Bearer Token
-// on the headers and if it is valid.
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// on the headers, or in the bearer.<token> subprotocol of a
+// WebSocket handshake, and if it is valid. The user of the token is
+// added to the request context as the actor.
+func AuthMiddleware(jwtConfig config.JWT) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		// Capturing Authorizathion header.
-		tokenHeader := r.Header.Get("Authorization")
-
-		// Checking if the value is empty.
-		if tokenHeader == "" {
-			rest.DecodeError(w, r, errEmptyToken, http.StatusUnauthorized)
-			return
-		}
+			// Capturing Authorizathion header.
+			tokenHeader := r.Header.Get("Authorization")
 
-		// Checking if the header contains Bearer string and if the token exists.
-		if !strings.Contains(tokenHeader, "Bearer") || len(strings.Split(tokenHeader, "Bearer ")) == 1 {
-			rest.DecodeError(w, r, errMalformedToken, http.StatusUnauthorized) // malformed token
-			return
-		}
-
-		// Capturing the token.
-		jwtString := strings.Split(tokenHeader, "Bearer ")[1]
+			// The browsers cannot set the headers of a WebSocket,
+			// the token is then offered as a subprotocol.
+			if tokenHeader == "" {
+				tokenHeader = protocolToken(r)
+			}
 
-		// Parsing the token to verify its authenticity.
-		token, err := jwt.Parse(jwtString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			claims, err := verify(tokenHeader, jwtConfig.Secret)
+			if err != nil {
+				response.Error(w, r, err)
+				return
 			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
+
+			// The user is the actor of the request, e.g. in the album revisions.
+			ctx := domain.NewActorContext(r.Context(), domain.Actor{UUID: claims.UUID, Email: claims.Email})
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
+	}
+}
 
-		// Returning parsing errors.
-		if err != nil {
-			rest.DecodeError(w, r, errUnexpected, http.StatusUnauthorized)
-			return
-		}
+// verify returns the claims of the token of an Authorization header.
+func verify(authorization, secret string) (*bearer.Claims, error) {
+	token, err := bearer.Token(authorization)
+	if errors.Is(err, bearer.ErrEmpty) {
+		return nil, domain.ErrTokenEmpty
+	}
+	if err != nil {
+		return nil, domain.ErrTokenMalformed
+	}
+
+	claims, err := bearer.Verify(token, secret)
+	if errors.Is(err, bearer.ErrInvalid) {
+		return nil, domain.ErrTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrTokenUnexpected, err)
+	}
 
-		// If the token is valid.
-		if token.Valid {
-			next.ServeHTTP(w, r)
-		} else {
-			rest.DecodeError(w, r, errInvalidToken, http.StatusUnauthorized)
-			return
+	return claims, nil
+}
+
+// protocolToken returns the token offered as the bearer.<token>
+// subprotocol of a WebSocket handshake, as an Authorization header.
+func protocolToken(r *http.Request) string {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if token, ok := strings.CutPrefix(protocol, "bearer."); ok && token != "" {
+			return "Bearer " + token
 		}
-	})
+	}
+
+	return ""
 }
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/bearer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	userUUID := uuid.New()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, bearer.Claims{UUID: userUUID, Email: "john@example.com"}).
+		SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	var actor domain.Actor
+	handler := AuthMiddleware(config.JWT{Secret: "secret"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor, _ = domain.ActorFromContext(r.Context())
+		}),
+	)
+
+	send := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodGet, "/album", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("Authorization", "Bearer "+token))
+	assert.Equal(t, userUUID, actor.UUID)
+
+	// the browsers offer the token of a WebSocket as a subprotocol
+	actor = domain.Actor{}
+	assert.Equal(t, http.StatusOK, send("Sec-WebSocket-Protocol", "presence, bearer."+token))
+	assert.Equal(t, "john@example.com", actor.Email)
+
+	assert.Equal(t, http.StatusUnauthorized, send("", ""))
+	assert.Equal(t, http.StatusUnauthorized, send("Sec-WebSocket-Protocol", "presence"))
+	assert.Equal(t, http.StatusUnauthorized, send("Sec-WebSocket-Protocol", "bearer."+token+"x"))
+}
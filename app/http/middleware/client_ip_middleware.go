@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"hexagony/libs/clientip"
+	"net/http"
+)
+
+// ClientIPMiddleware adds the IP address of the client to the
+// request context, e.g. for the audit log and the rate limits.
+// X-Forwarded-For is only honoured when sent by a trusted proxy.
+func ClientIPMiddleware(proxies clientip.Proxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := clientip.NewContext(r.Context(), proxies.FromRequest(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
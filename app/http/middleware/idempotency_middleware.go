@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/config"
+	"hexagony/libs/clientip"
+	"hexagony/libs/clog"
+	"hexagony/libs/requestid"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header naming the key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader marks the responses replayed from the store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyMaxKey = 255
+)
+
+// IdempotencyMiddleware makes the POST requests carrying an Idempotency-Key
+// safe to retry. The first request runs and its response is stored for the
+// configured TTL, the repeats with the same key, path and body get the
+// stored response back. A repeat with a different request is refused with
+// 422 and one arriving while the first still runs with 409.
+//
+// The keys are scoped by user, or by client IP on the public routes. The
+// server errors are not stored, so those requests can be retried. The
+// body is read in memory, a body larger than the configured one is
+// refused with 413.
+func IdempotencyMiddleware(store domain.IdempotencyStore, cfg config.Idempotency) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := r.Header[http.CanonicalHeaderKey(IdempotencyKeyHeader)]
+			if r.Method != http.MethodPost || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) != 1 || key[0] == "" || len(key[0]) > idempotencyMaxKey {
+				response.Error(w, r, domain.ErrIdempotencyKeyInvalid)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.MaxBody))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					response.Error(w, r, domain.ErrIdempotencyTooLarge)
+					return
+				}
+
+				response.Error(w, r, domain.ErrInvalidPayload)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			record := &domain.IdempotencyRecord{
+				Scope:       idempotencyScope(r),
+				Key:         key[0],
+				Fingerprint: fingerprint(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(cfg.TTL),
+			}
+
+			existing, err := store.Reserve(r.Context(), record)
+			if err != nil {
+				response.Error(w, r, err)
+				return
+			}
+
+			if existing != nil {
+				switch {
+				case existing.Fingerprint != record.Fingerprint:
+					response.Error(w, r, domain.ErrIdempotencyKeyReused)
+				case !existing.Completed():
+					response.Error(w, r, domain.ErrIdempotencyInProgress)
+				default:
+					replay(w, existing)
+				}
+				return
+			}
+
+			// the key is released if the handler panics or fails, the
+			// request is over for the client whatever happens to the store
+			ctx := context.WithoutCancel(r.Context())
+			completed := false
+
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(ctx, record.Scope, record.Key); err != nil {
+					clog.ErrorContext(ctx, err, "failed to release the idempotency key")
+				}
+			}()
+
+			var buf bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			next.ServeHTTP(ww, r)
+
+			record.Status = ww.Status()
+			if record.Status == 0 {
+				record.Status = http.StatusOK
+			}
+
+			if record.Status >= http.StatusInternalServerError {
+				return
+			}
+
+			record.Header = ww.Header().Clone()
+			delete(record.Header, requestid.Header)
+			record.Body = buf.Bytes()
+
+			if err := store.Complete(ctx, record); err != nil {
+				clog.ErrorContext(ctx, err, "failed to store the idempotent response")
+				return
+			}
+
+			completed = true
+		})
+	}
+}
+
+// idempotencyScope returns the user of the request,
+// or the client IP when the request is anonymous.
+func idempotencyScope(r *http.Request) string {
+	if actor, ok := domain.ActorFromContext(r.Context()); ok && actor.UUID != uuid.Nil {
+		return actor.UUID.String()
+	}
+
+	return "ip:" + clientip.FromContext(r.Context())
+}
+
+// fingerprint hashes what makes two requests the same.
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay writes the stored response of record.
+func replay(w http.ResponseWriter, record *domain.IdempotencyRecord) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
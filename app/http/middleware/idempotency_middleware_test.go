@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/repositories/memory"
+	"hexagony/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	cfg := config.Idempotency{TTL: time.Hour, MaxBody: 1 << 10}
+	actor := domain.Actor{UUID: uuid.New()}
+
+	send := func(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/album", strings.NewReader(body))
+		req = req.WithContext(domain.NewActorContext(req.Context(), actor))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		var calls atomic.Int32
+		handler := IdempotencyMiddleware(memory.NewIdempotencyStore(), cfg)(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("ETag", `"1"`)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"message":"created"}`))
+			},
+		))
+
+		first := send(handler, "key-1", `{"name":"St. Anger"}`)
+		repeat := send(handler, "key-1", `{"name":"St. Anger"}`)
+
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, http.StatusCreated, repeat.Code)
+		assert.Equal(t, first.Body.String(), repeat.Body.String())
+		assert.Equal(t, `"1"`, repeat.Header().Get("ETag"))
+		assert.Equal(t, "true", repeat.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+		// another key, or none, runs the handler again
+		send(handler, "key-2", `{"name":"St. Anger"}`)
+		send(handler, "", `{"name":"St. Anger"}`)
+		assert.Equal(t, int32(3), calls.Load())
+
+		// the same key with another body is refused
+		reused := send(handler, "key-1", `{"name":"Load"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+		assert.Contains(t, reused.Body.String(), "idempotency_key_reused")
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		handler := IdempotencyMiddleware(memory.NewIdempotencyStore(), cfg)(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				<-release
+				w.WriteHeader(http.StatusCreated)
+			},
+		))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			send(handler, "key", "{}")
+		}()
+
+		<-started
+		rec := send(handler, "key", "{}")
+		close(release)
+		<-done
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, http.StatusCreated, send(handler, "key", "{}").Code)
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		var calls atomic.Int32
+		handler := IdempotencyMiddleware(memory.NewIdempotencyStore(), cfg)(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			},
+		))
+
+		assert.Equal(t, http.StatusInternalServerError, send(handler, "key", "{}").Code)
+		assert.Equal(t, http.StatusCreated, send(handler, "key", "{}").Code)
+		assert.Equal(t, http.StatusCreated, send(handler, "key", "{}").Code)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		handler := IdempotencyMiddleware(memory.NewIdempotencyStore(), cfg)(http.NotFoundHandler())
+
+		rec := send(handler, strings.Repeat("k", 256), "{}")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "idempotency_key_invalid")
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		handler := IdempotencyMiddleware(memory.NewIdempotencyStore(), cfg)(http.NotFoundHandler())
+
+		rec := send(handler, "key", strings.Repeat("x", 1<<10+1))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "idempotency_body_too_large")
+	})
+}
@@ -3,21 +3,43 @@ package middleware
 import (
 	"hexagony/libs/clog"
 	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
+// LoggerMiddleware logs every request once it has completed,
+// along with its status, response size and latency.
 func LoggerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		logger := clog.FromContext(r.Context())
+
+		event := logger.Info()
+		if status >= http.StatusInternalServerError {
+			event = logger.Error()
+		}
 
-		clog.Custom(map[string]interface{}{
-			"host":      r.Host,
-			"method":    r.Method,
-			"url":       r.URL.String(),
-			"agent":     r.UserAgent(),
-			"referer":   r.Referer(),
-			"proto":     r.Proto,
-			"remote_ip": r.RemoteAddr,
-		})
-
-		next.ServeHTTP(w, r)
+		event.Ctx(r.Context()).Fields(map[string]interface{}{
+			"host":       r.Host,
+			"method":     r.Method,
+			"url":        r.URL.String(),
+			"agent":      r.UserAgent(),
+			"referer":    r.Referer(),
+			"proto":      r.Proto,
+			"remote_ip":  r.RemoteAddr,
+			"status":     status,
+			"bytes":      ww.BytesWritten(),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		}).Msg("request completed")
 	})
 }
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"hexagony/libs/requestid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs redirects the logs to the returned buffer during the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	previous := log.Logger
+	log.Logger = log.Output(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+
+	return &buf
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var id string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = requestid.FromContext(r.Context())
+	}))
+
+	t.Run("generated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/album", nil))
+
+		assert.True(t, requestid.Valid(id))
+		assert.Equal(t, id, rec.Header().Get(requestid.Header))
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/album", nil)
+		req.Header.Set(requestid.Header, "client-id-42")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "client-id-42", id)
+		assert.Equal(t, "client-id-42", rec.Header().Get(requestid.Header))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/album", nil)
+		req.Header.Set(requestid.Header, "not a valid\nid")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.NotEqual(t, "not a valid\nid", id)
+		assert.True(t, requestid.Valid(id))
+	})
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	tests := []struct {
+		status int
+		level  string
+	}{
+		{http.StatusCreated, "info"},
+		{http.StatusServiceUnavailable, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			buf := captureLogs(t)
+
+			handler := RequestIDMiddleware(LoggerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("body"))
+			})))
+
+			req := httptest.NewRequest(http.MethodPost, "/album?dry_run=true", nil)
+			req.Header.Set(requestid.Header, "client-id-42")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry map[string]interface{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+			assert.Equal(t, "request completed", entry["message"])
+			assert.Equal(t, tt.level, entry["level"])
+			assert.Equal(t, "client-id-42", entry["request_id"])
+			assert.Equal(t, http.MethodPost, entry["method"])
+			assert.Equal(t, "/album?dry_run=true", entry["url"])
+			assert.Equal(t, float64(tt.status), entry["status"])
+			assert.Equal(t, float64(4), entry["bytes"])
+			assert.Contains(t, entry, "latency_ms")
+			assert.GreaterOrEqual(t, entry["latency_ms"], float64(0))
+		})
+	}
+}
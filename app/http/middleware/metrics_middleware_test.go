@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"hexagony/libs/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(MetricsMiddleware)
+	r.Route("/albums", func(r chi.Router) {
+		r.Get("/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		labels []string
+	}{
+		{"route pattern", "/albums/0b4ee7ba-7a8d-4e11-9e7b-8d2f8f4a0a11", []string{http.MethodGet, "/albums/{uuid}", "204"}},
+		{"unmatched", "/no/such/route", []string{http.MethodGet, unmatchedRoute, "404"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(tt.labels...))
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, before+1, testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(tt.labels...)))
+		})
+	}
+
+	assert.Zero(t, testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/no/such/route", "404")))
+}
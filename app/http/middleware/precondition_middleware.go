@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/config"
+	"net/http"
+)
+
+// PreconditionMiddleware refuses the updates and deletes that are not
+// conditioned with If-Match, when the configuration requires it.
+// The lost updates are then impossible instead of only detectable.
+func PreconditionMiddleware(cfg config.Concurrency) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.RequireIfMatch {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			switch r.Method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				if r.Header.Get("If-Match") == "" {
+					response.Error(w, r, domain.ErrPreconditionRequired)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
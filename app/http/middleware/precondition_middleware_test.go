@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	controller "hexagony/app/http/controllers"
+	"hexagony/config"
+	"hexagony/libs/validation"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPreconditionMiddleware(t *testing.T) {
+	handler := PreconditionMiddleware(config.Concurrency{RequireIfMatch: true})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	send := func(method, ifMatch string) int {
+		req := httptest.NewRequest(method, "/album/1", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	// the writes must be conditioned
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		assert.Equal(t, http.StatusPreconditionRequired, send(method, ""), method)
+		assert.Equal(t, http.StatusOK, send(method, `"3"`), method)
+	}
+
+	// the reads and the creations are not
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, ""))
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, ""))
+}
+
+func TestPreconditionMiddlewareNotRequired(t *testing.T) {
+	handler := PreconditionMiddleware(config.Concurrency{RequireIfMatch: false})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/album/1", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestPreconditionMiddlewareStaleVersion(t *testing.T) {
+	id := uuid.New()
+
+	mockAlbumUseCase := new(mocks.AlbumUseCase)
+	mockAlbumUseCase.On("Delete", mock.Anything, id, 2).Return(domain.ErrPreconditionFailed)
+
+	v, err := validation.New()
+	assert.NoError(t, err)
+
+	albums := &controller.AlbumsController{AlbumsUseCase: mockAlbumUseCase, Validator: v}
+
+	router := chi.NewRouter()
+	router.Use(PreconditionMiddleware(config.Concurrency{RequireIfMatch: true}))
+	router.Delete("/album/{uuid}", albums.Delete)
+
+	send := func(ifMatch string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/album/"+id.String(), nil)
+		req.Header.Set("If-Match", ifMatch)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	// the version read is no longer the current one
+	assert.Equal(t, http.StatusPreconditionFailed, send(`"2"`))
+
+	// the entity tag is not a version
+	assert.Equal(t, http.StatusPreconditionFailed, send(`W/"2"`))
+
+	mockAlbumUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/http/response"
+	"hexagony/libs/clientip"
+	"hexagony/libs/clog"
+	"hexagony/libs/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RateLimitMiddleware limits the requests of a route group with policy
+// and reports the state of the limit in the RateLimit-* headers. The
+// denied requests get a 429 with Retry-After. The name of the group
+// keeps its limits apart from the other groups.
+//
+// The user policies fall back to the client IP for the requests without
+// a user. The requests are let through when the limiter fails, e.g. the
+// shared store is unavailable.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, group string, policy ratelimit.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !policy.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := limiter.Allow(r.Context(), group+":"+rateLimitKey(r, policy.Key), policy)
+			if err != nil {
+				clog.ErrorContext(r.Context(), err, "failed to rate limit the request")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", seconds(result.Reset))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s;burst=%d", policy.Rate, seconds(policy.Period), policy.Burst))
+
+			if !result.Allowed {
+				header.Set("Retry-After", seconds(result.RetryAfter))
+				response.Error(w, r, domain.ErrRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey returns what the requests of r are counted by.
+func rateLimitKey(r *http.Request, key string) string {
+	if key == ratelimit.KeyUser {
+		if actor, ok := domain.ActorFromContext(r.Context()); ok && actor.UUID != uuid.Nil {
+			return "user:" + actor.UUID.String()
+		}
+	}
+
+	return "ip:" + clientip.FromContext(r.Context())
+}
+
+// seconds formats d as whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"hexagony/app/domain"
+	"hexagony/libs/clientip"
+	"hexagony/libs/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	policy := ratelimit.Policy{Rate: 2, Period: time.Hour, Burst: 2, Key: ratelimit.KeyUser}
+	handler := RateLimitMiddleware(ratelimit.New(ratelimit.NewMemoryStore()), "albums", policy)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	first, second := uuid.New(), uuid.New()
+
+	send := func(user uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/album", nil)
+		req = req.WithContext(clientip.NewContext(req.Context(), "203.0.113.7"))
+		if user != uuid.Nil {
+			req = req.WithContext(domain.NewActorContext(req.Context(), domain.Actor{UUID: user}))
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	allowed := send(first)
+	assert.Equal(t, http.StatusOK, allowed.Code)
+	assert.Equal(t, "2", allowed.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", allowed.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=3600;burst=2", allowed.Header().Get("RateLimit-Policy"))
+
+	assert.Equal(t, http.StatusOK, send(first).Code)
+
+	denied := send(first)
+	assert.Equal(t, http.StatusTooManyRequests, denied.Code)
+	assert.Equal(t, "1800", denied.Header().Get("Retry-After"))
+	assert.Equal(t, "0", denied.Header().Get("RateLimit-Remaining"))
+	assert.Contains(t, denied.Body.String(), "rate_limited")
+
+	// another user, or the client IP without one, are counted apart
+	assert.Equal(t, http.StatusOK, send(second).Code)
+	assert.Equal(t, http.StatusOK, send(uuid.Nil).Code)
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"hexagony/app/http/response"
+	"hexagony/libs/clog"
+	"net/http"
+	"runtime/debug"
+)
+
+// RecovererMiddleware recovers from panics, logs them with the
+// stack trace and responds with an internal error problem.
+func RecovererMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+
+			// The server relies on this panic to abort the response.
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			clog.FromContext(r.Context()).Error().Ctx(r.Context()).
+				Interface("panic", rvr).
+				Bytes("stack", debug.Stack()).
+				Msg("recovered from panic")
+
+			response.StatusProblem(w, r, http.StatusInternalServerError, "internal_error")
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
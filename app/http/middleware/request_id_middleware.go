@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"hexagony/libs/clog"
+	"hexagony/libs/requestid"
+	"net/http"
+)
+
+// RequestIDMiddleware reuses the X-Request-ID header sent by the
+// client, or generates a new ID, and echoes it in the response.
+// The ID is added to every log written with the request context.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+
+		ctx := requestid.NewContext(r.Context(), id)
+		ctx = clog.With(ctx, map[string]interface{}{"request_id": id})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
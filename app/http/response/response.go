@@ -0,0 +1,94 @@
+// Package response maps the domain errors to HTTP problem details.
+// It is the single place deciding which status an error is reported with.
+package response
+
+import (
+	"errors"
+	"net/http"
+
+	"hexagony/app/domain"
+	"hexagony/libs/clog"
+	"hexagony/libs/problem"
+	"hexagony/libs/requestid"
+)
+
+var statuses = map[domain.Kind]int{
+	domain.KindInternal:             http.StatusInternalServerError,
+	domain.KindInvalid:              http.StatusBadRequest,
+	domain.KindUnauthorized:         http.StatusUnauthorized,
+	domain.KindForbidden:            http.StatusForbidden,
+	domain.KindNotFound:             http.StatusNotFound,
+	domain.KindConflict:             http.StatusConflict,
+	domain.KindUnprocessable:        http.StatusUnprocessableEntity,
+	domain.KindUnsupported:          http.StatusUnsupportedMediaType,
+	domain.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	domain.KindPreconditionRequired: http.StatusPreconditionRequired,
+	domain.KindRateLimited:          http.StatusTooManyRequests,
+	domain.KindTooLarge:             http.StatusRequestEntityTooLarge,
+}
+
+// Status returns the HTTP status matching err.
+func Status(err error) int {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		if status, ok := statuses[domainErr.Kind]; ok {
+			return status
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Error logs err and writes it as a problem+json response. Only the
+// message of the domain error is exposed; the wrapped causes are logged.
+// Errors outside the domain are reported as internal errors.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	p := FromError(err)
+
+	if p.Status >= http.StatusInternalServerError {
+		clog.ErrorContext(r.Context(), err, p.Detail)
+	} else {
+		clog.FromContext(r.Context()).Warn().Ctx(r.Context()).Err(err).Msg(p.Detail)
+	}
+
+	write(w, r, p)
+}
+
+// FromError returns the problem err is reported with, e.g. for the
+// items of a bulk response. Only the message of the domain error is
+// exposed, errors outside the domain are reported as internal errors.
+func FromError(err error) *problem.Problem {
+	code, detail := "internal_error", http.StatusText(http.StatusInternalServerError)
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		code, detail = domainErr.Code, domainErr.Message
+	}
+
+	return problem.New(Status(err), code, detail)
+}
+
+// Validation writes a validation problem with the invalid fields.
+func Validation(w http.ResponseWriter, r *http.Request, params []*problem.InvalidParam) {
+	p := problem.New(http.StatusBadRequest, domain.ErrValidation.Code, domain.ErrValidation.Message)
+	p.InvalidParams = params
+
+	write(w, r, p)
+}
+
+// StatusProblem writes a problem for a plain status code,
+// e.g. from the router, without logging it.
+func StatusProblem(w http.ResponseWriter, r *http.Request, status int, code string) {
+	write(w, r, problem.New(status, code, ""))
+}
+
+func write(w http.ResponseWriter, r *http.Request, p *problem.Problem) {
+	p.Instance = r.URL.Path
+	p.RequestID = requestid.FromContext(r.Context())
+
+	problem.Write(w, p)
+}
+
+// Problem is the body of every error response,
+// it is referenced by the API documentation.
+type Problem = problem.Problem
@@ -0,0 +1,68 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/libs/problem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, Status(domain.ErrResourceNotFound))
+	assert.Equal(t, http.StatusConflict, Status(domain.ErrUsersDuplicateEmail))
+	assert.Equal(t, http.StatusTooManyRequests, Status(domain.ErrRateLimited))
+	assert.Equal(t, http.StatusForbidden, Status(domain.ErrAdminRequired))
+	assert.Equal(t, http.StatusUnauthorized, Status(fmt.Errorf("%w: bad hash", domain.ErrAuthPassword)))
+	assert.Equal(t, http.StatusInternalServerError, Status(errors.New("unexpected")))
+}
+
+func TestError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/album/1", nil)
+
+	Error(rec, req, fmt.Errorf("%w: %w", domain.ErrAlbumsFindByID, errors.New("connection refused")))
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "albums_find_failed", p.Code)
+	assert.Equal(t, "urn:hexagony:problem:albums_find_failed", p.Type)
+	assert.Equal(t, domain.ErrAlbumsFindByID.Message, p.Detail)
+	assert.Equal(t, "/album/1", p.Instance)
+}
+
+func TestErrorHidesUnknownErrors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/album", nil)
+
+	Error(rec, req, errors.New("pq: password authentication failed"))
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+
+	assert.Equal(t, "internal_error", p.Code)
+	assert.NotContains(t, p.Detail, "pq:")
+}
+
+func TestValidation(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/album", nil)
+
+	Validation(rec, req, []*problem.InvalidParam{{Name: "name", Reason: "name is a required field"}})
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, domain.ErrValidation.Code, p.Code)
+	assert.Len(t, p.InvalidParams, 1)
+	assert.Equal(t, "name", p.InvalidParams[0].Name)
+}
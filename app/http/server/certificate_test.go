@@ -0,0 +1,104 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCert writes a self-signed certificate for name and its key,
+// modified at modTime.
+func writeCert(t *testing.T, certFile, keyFile, name string, modTime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	for _, file := range []string{certFile, keyFile} {
+		assert.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+}
+
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+
+	return parsed.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	now := time.Now()
+
+	writeCert(t, certFile, keyFile, "first", now.Add(-time.Hour))
+
+	r, err := newCertReloader(certFile, keyFile)
+	assert.NoError(t, err)
+
+	cert, err := r.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, cert))
+
+	t.Run("rotated files are checked at most once per interval", func(t *testing.T) {
+		writeCert(t, certFile, keyFile, "second", now)
+
+		cert, err := r.GetCertificate(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "first", commonName(t, cert))
+	})
+
+	t.Run("rotated files are reloaded", func(t *testing.T) {
+		r.checkedAt = time.Time{}
+
+		cert, err := r.GetCertificate(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "second", commonName(t, cert))
+	})
+
+	t.Run("an invalid rotation keeps the previous certificate", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(certFile, []byte("half written"), 0o600))
+		assert.NoError(t, os.Chtimes(certFile, now.Add(time.Hour), now.Add(time.Hour)))
+		r.checkedAt = time.Time{}
+
+		cert, err := r.GetCertificate(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "second", commonName(t, cert))
+	})
+}
+
+func TestNewCertReloaderInvalid(t *testing.T) {
+	_, err := newCertReloader(filepath.Join(t.TempDir(), "missing.pem"), "missing.key")
+
+	assert.Error(t, err)
+}
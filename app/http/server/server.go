@@ -0,0 +1,121 @@
+// Package server runs the HTTP server with the configured timeouts,
+// optional TLS and HTTP/2, and a bounded graceful shutdown.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"hexagony/config"
+	"hexagony/libs/clog"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Server wraps an http.Server.
+type Server struct {
+	srv             *http.Server
+	shutdownTimeout time.Duration
+	tls             bool
+	onShutdown      []func()
+}
+
+// New creates a server for the given handler using the server configuration.
+func New(cfg config.Server, handler http.Handler) (*Server, error) {
+	s := &Server{
+		shutdownTimeout: cfg.ShutdownTimeout,
+		tls:             cfg.TLSCertFile != "",
+	}
+
+	if cfg.H2C && !s.tls {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: cfg.IdleTimeout})
+	}
+
+	s.srv = &http.Server{
+		Addr:              ":" + cfg.Port,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		Handler:           handler,
+	}
+
+	if s.tls {
+		reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		// ServeTLS enables HTTP/2 on top of this configuration.
+		s.srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	}
+
+	return s, nil
+}
+
+// OnShutdown registers a function to be called as soon as the
+// graceful shutdown starts, before the in-flight requests are drained.
+func (s *Server) OnShutdown(fn func()) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Run serves requests until ctx is done, then stops accepting new
+// connections and waits up to the shutdown timeout for the in-flight
+// requests to finish. Remaining connections are closed after that.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ctx, ln)
+}
+
+func (s *Server) serve(ctx context.Context, ln net.Listener) error {
+	serveErr := make(chan error, 1)
+
+	go func() {
+		if s.tls {
+			serveErr <- s.srv.ServeTLS(ln, "", "")
+			return
+		}
+		serveErr <- s.srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	clog.Info("shutting down the server...")
+
+	for _, fn := range s.onShutdown {
+		fn()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.srv.Shutdown(shutdownCtx); err != nil {
+		clog.Error(err, "server failed to drain the connections in time")
+
+		if err := s.srv.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"hexagony/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	s, err := New(config.Server{ShutdownTimeout: 100 * time.Millisecond}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	assert.NoError(t, err)
+
+	shutdown := make(chan struct{})
+	s.OnShutdown(func() { close(shutdown) })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.serve(ctx, ln)
+	}()
+
+	requested := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+		requested <- err
+	}()
+
+	<-started
+	begin := time.Now()
+	cancel()
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not stop after the shutdown timeout")
+	}
+
+	assert.GreaterOrEqual(t, time.Since(begin), 100*time.Millisecond)
+	assert.Error(t, <-requested) // the stuck request is cut off
+	<-shutdown
+}
+
+func TestServeStopsWhenIdle(t *testing.T) {
+	s, err := New(config.Server{ShutdownTimeout: time.Minute}, http.NotFoundHandler())
+	assert.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.serve(ctx, ln)
+	}()
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	cancel()
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server waited for the shutdown timeout without any request in flight")
+	}
+}
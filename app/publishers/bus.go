@@ -0,0 +1,80 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"sync"
+)
+
+// Handler handles an event published on the bus.
+type Handler func(ctx context.Context, event *domain.Event) error
+
+// Bus publishes the events to the handlers subscribed in the process.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[int]Handler
+	next     int
+}
+
+// NewBus creates a bus without handlers.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[int]Handler)}
+}
+
+// Subscribe adds handler to the bus until unsubscribe is called.
+func (b *Bus) Subscribe(handler Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.handlers[id] = handler
+	b.next++
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.handlers, id)
+	}
+}
+
+// Publish hands event to every handler, one after the other. The event
+// is published again to all of them when one fails, the handlers must
+// tolerate the duplicates and should not block.
+func (b *Bus) Publish(ctx context.Context, event *domain.Event) error {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers))
+	for _, handler := range b.handlers {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type fanout []domain.Publisher
+
+// Fanout creates a publisher publishing the events to every publisher.
+// The event is published again to all of them when one fails.
+func Fanout(publishers ...domain.Publisher) domain.Publisher {
+	return fanout(publishers)
+}
+
+func (f fanout) Publish(ctx context.Context, event *domain.Event) error {
+	var errs []error
+	for _, publisher := range f {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,53 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBus(t *testing.T) {
+	event := &domain.Event{ID: uuid.New(), Type: domain.EventAlbumCreated}
+
+	bus := NewBus()
+
+	var received []*domain.Event
+	unsubscribe := bus.Subscribe(func(ctx context.Context, event *domain.Event) error {
+		received = append(received, event)
+		return nil
+	})
+	bus.Subscribe(func(ctx context.Context, event *domain.Event) error {
+		return errors.New("unexpected error")
+	})
+
+	// the failing handler does not keep the event from the others
+	assert.Error(t, bus.Publish(context.Background(), event))
+	assert.Equal(t, []*domain.Event{event}, received)
+
+	unsubscribe()
+
+	assert.Error(t, bus.Publish(context.Background(), event))
+	assert.Len(t, received, 1)
+}
+
+func TestFanout(t *testing.T) {
+	event := &domain.Event{ID: uuid.New(), Type: domain.EventUserCreated}
+
+	failing := new(mocks.Publisher)
+	failing.On("Publish", mock.Anything, event).Return(errors.New("unexpected error")).Once()
+
+	working := new(mocks.Publisher)
+	working.On("Publish", mock.Anything, event).Return(nil).Once()
+
+	err := Fanout(failing, working).Publish(context.Background(), event)
+
+	assert.Error(t, err)
+	failing.AssertExpectations(t)
+	working.AssertExpectations(t)
+}
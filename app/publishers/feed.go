@@ -0,0 +1,129 @@
+package publisher
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is the number of events a subscriber may lag
+// behind before it is dropped, it then resumes from the replay buffer.
+const subscriberBuffer = 64
+
+// Feed keeps the last events of an aggregate type for the clients
+// streaming them. It is a handler of the bus of the outbox listener,
+// the events read again by the listener are only streamed once.
+type Feed struct {
+	mu            sync.Mutex
+	aggregateType string
+	size          int
+	events        []*domain.Event
+	known         map[uuid.UUID]struct{}
+	subscribers   map[chan *domain.Event]struct{}
+	closed        bool
+}
+
+// NewFeed creates a feed of the events of aggregateType keeping the last size of them.
+func NewFeed(aggregateType string, size int) *Feed {
+	return &Feed{
+		aggregateType: aggregateType,
+		size:          size,
+		known:         make(map[uuid.UUID]struct{}),
+		subscribers:   make(map[chan *domain.Event]struct{}),
+	}
+}
+
+// Publish buffers the event and hands it to the subscribers,
+// those falling behind are dropped.
+func (f *Feed) Publish(ctx context.Context, event *domain.Event) error {
+	if event.AggregateType != f.aggregateType {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.known[event.ID]; ok || f.closed {
+		return nil
+	}
+
+	if len(f.events) == f.size {
+		delete(f.known, f.events[0].ID)
+		f.events = f.events[1:]
+	}
+	f.events = append(f.events, event)
+	f.known[event.ID] = struct{}{}
+
+	for subscriber := range f.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(f.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe streams the next events, after the buffered events
+// following lastEventID when it is set. The events are identified
+// by their sequence, the same on every replica.
+func (f *Feed) Subscribe(lastEventID string) *domain.EventSubscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events := make(chan *domain.Event, subscriberBuffer)
+	subscription := &domain.EventSubscription{Events: events, Resumed: true}
+
+	if lastEventID != "" {
+		subscription.Replay, subscription.Resumed = f.after(lastEventID)
+	}
+
+	if f.closed {
+		close(events)
+	} else {
+		f.subscribers[events] = struct{}{}
+	}
+
+	subscription.Cancel = func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if _, ok := f.subscribers[events]; ok {
+			delete(f.subscribers, events)
+			close(events)
+		}
+	}
+
+	return subscription
+}
+
+// after returns the buffered events following the event of id,
+// and whether that event is buffered.
+func (f *Feed) after(id string) ([]*domain.Event, bool) {
+	for i, event := range f.events {
+		if strconv.FormatInt(event.Sequence, 10) == id {
+			return append([]*domain.Event(nil), f.events[i+1:]...), true
+		}
+	}
+
+	return nil, false
+}
+
+// Close ends every subscription, for the streams to finish
+// before the server shuts down.
+func (f *Feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+
+	for subscriber := range f.subscribers {
+		delete(f.subscribers, subscriber)
+		close(subscriber)
+	}
+}
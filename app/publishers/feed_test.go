@@ -0,0 +1,82 @@
+package publisher
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// sequence numbers the events as the outbox does.
+var sequence int64
+
+func albumEvent() *domain.Event {
+	sequence++
+	return &domain.Event{ID: uuid.New(), Sequence: sequence, Type: domain.EventAlbumUpdated, AggregateType: "album", AggregateID: uuid.New()}
+}
+
+func TestFeed(t *testing.T) {
+	ctx := context.Background()
+	feed := NewFeed("album", 3)
+
+	first, second := albumEvent(), albumEvent()
+	assert.NoError(t, feed.Publish(ctx, first))
+
+	subscription := feed.Subscribe("")
+	assert.True(t, subscription.Resumed)
+	assert.Empty(t, subscription.Replay)
+
+	// the other aggregates and the events published again are skipped
+	assert.NoError(t, feed.Publish(ctx, &domain.Event{ID: uuid.New(), AggregateType: "user"}))
+	assert.NoError(t, feed.Publish(ctx, first))
+	assert.NoError(t, feed.Publish(ctx, second))
+
+	assert.Equal(t, second, <-subscription.Events)
+	assert.Len(t, subscription.Events, 0)
+
+	subscription.Cancel()
+	_, open := <-subscription.Events
+	assert.False(t, open)
+
+	// a client resumes after the last event it received
+	resumed := feed.Subscribe(strconv.FormatInt(first.Sequence, 10))
+	assert.True(t, resumed.Resumed)
+	assert.Equal(t, []*domain.Event{second}, resumed.Replay)
+	resumed.Cancel()
+
+	// the buffer only keeps the last events
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, feed.Publish(ctx, albumEvent()))
+	}
+
+	lost := feed.Subscribe(strconv.FormatInt(first.Sequence, 10))
+	assert.False(t, lost.Resumed)
+	assert.Empty(t, lost.Replay)
+
+	feed.Close()
+	_, open = <-lost.Events
+	assert.False(t, open)
+}
+
+func TestFeedSlowSubscriber(t *testing.T) {
+	ctx := context.Background()
+	feed := NewFeed("album", 1000)
+
+	subscription := feed.Subscribe("")
+
+	// a subscriber not keeping up is dropped rather than blocking the bus
+	for i := 0; i <= subscriberBuffer; i++ {
+		assert.NoError(t, feed.Publish(ctx, albumEvent()))
+	}
+
+	received := 0
+	for range subscription.Events {
+		received++
+	}
+
+	assert.Equal(t, subscriberBuffer, received)
+	subscription.Cancel()
+}
@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/clog"
+)
+
+type logPublisher struct{}
+
+// NewLogPublisher creates a publisher writing the events to the log.
+func NewLogPublisher() domain.Publisher {
+	return logPublisher{}
+}
+
+func (logPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	clog.CustomContext(ctx, map[string]interface{}{
+		"message":        "event published",
+		"event_id":       event.ID,
+		"event_type":     event.Type,
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateID,
+		"occurred_at":    event.OccurredAt,
+	})
+
+	return nil
+}
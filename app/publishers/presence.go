@@ -0,0 +1,341 @@
+package publisher
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sessionBuffer is the number of messages a session may lag behind
+// before it is dropped, the client then joins again.
+const sessionBuffer = 64
+
+// sweepInterval is how often the expired locks are released.
+const sweepInterval = time.Second
+
+// Hub tracks the editors of the albums in memory. Every change of the
+// editors of an album or of their locks is broadcast to all of them
+// with the list of editors. A lock is held for lockTTL, unless the
+// editor locks the field again before. It is a handler of the bus,
+// forwarding the album events to the editors of the album.
+type Hub struct {
+	mu      sync.Mutex
+	lockTTL time.Duration
+	rooms   map[uuid.UUID]*room
+	closed  bool
+}
+
+// room holds the editors of an album, in the order they joined,
+// and the locks of its fields.
+type room struct {
+	album   uuid.UUID
+	editors []*editor
+	locks   map[string]*fieldLock
+}
+
+type editor struct {
+	id       uuid.UUID
+	actor    domain.Actor
+	state    string
+	messages chan *domain.PresenceMessage
+}
+
+type fieldLock struct {
+	holder  *editor
+	expires time.Time
+}
+
+// NewHub creates a hub whose locks are held for lockTTL.
+func NewHub(lockTTL time.Duration) *Hub {
+	return &Hub{
+		lockTTL: lockTTL,
+		rooms:   make(map[uuid.UUID]*room),
+	}
+}
+
+// Join adds a viewer to the editors of album.
+func (h *Hub) Join(album uuid.UUID, actor domain.Actor) *domain.PresenceSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	messages := make(chan *domain.PresenceMessage, sessionBuffer)
+	session := &domain.PresenceSession{ID: uuid.New(), Album: album, Messages: messages}
+
+	if h.closed {
+		close(messages)
+		return session
+	}
+
+	r, ok := h.rooms[album]
+	if !ok {
+		r = &room{album: album, locks: make(map[string]*fieldLock)}
+		h.rooms[album] = r
+	}
+
+	r.editors = append(r.editors, &editor{
+		id:       session.ID,
+		actor:    actor,
+		state:    domain.PresenceViewing,
+		messages: messages,
+	})
+	h.broadcast(r)
+
+	return session
+}
+
+// Receive applies a message of the editor of session. Locking a field
+// makes the editor editing, viewing releases its locks. A lock held by
+// another editor is denied, the invalid messages are answered with the
+// code of the error.
+func (h *Hub) Receive(session *domain.PresenceSession, message *domain.PresenceMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, e := h.find(session)
+	if e == nil {
+		return
+	}
+
+	switch message.Type {
+	case domain.PresenceState:
+		switch message.State {
+		case domain.PresenceViewing:
+			r.release(e)
+		case domain.PresenceEditing:
+		default:
+			h.reply(r, e, failure(domain.ErrPresenceState))
+			return
+		}
+		e.state = message.State
+
+	case domain.PresenceLock:
+		if !slices.Contains(domain.PresenceFields, message.Field) {
+			h.reply(r, e, failure(domain.ErrPresenceField))
+			return
+		}
+
+		held, ok := r.locks[message.Field]
+		if ok && held.holder != e {
+			h.reply(r, e, &domain.PresenceMessage{Type: domain.PresenceLockDenied, Field: message.Field, Holder: r.view(held.holder)})
+			return
+		}
+
+		r.locks[message.Field] = &fieldLock{holder: e, expires: time.Now().Add(h.lockTTL)}
+		e.state = domain.PresenceEditing
+
+		// a renewal only extends the lock
+		if ok {
+			return
+		}
+
+	case domain.PresenceUnlock:
+		if !slices.Contains(domain.PresenceFields, message.Field) {
+			h.reply(r, e, failure(domain.ErrPresenceField))
+			return
+		}
+
+		if held, ok := r.locks[message.Field]; !ok || held.holder != e {
+			return
+		}
+		delete(r.locks, message.Field)
+
+	default:
+		h.reply(r, e, failure(domain.ErrPresenceMessage))
+		return
+	}
+
+	h.broadcast(r)
+}
+
+// Leave removes the editor of session, releasing its locks.
+func (h *Hub) Leave(session *domain.PresenceSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, e := h.find(session)
+	if e == nil {
+		return
+	}
+
+	h.remove(r, e)
+	h.broadcast(r)
+}
+
+// Publish forwards the album.updated, album.deleted and album.restored
+// events to the editors of the album.
+func (h *Hub) Publish(ctx context.Context, event *domain.Event) error {
+	switch event.Type {
+	case domain.EventAlbumUpdated, domain.EventAlbumDeleted, domain.EventAlbumRestored:
+	default:
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[event.AggregateID]
+	if !ok {
+		return nil
+	}
+
+	if h.deliver(r, &domain.PresenceMessage{Type: event.Type, Event: event}, r.editors...) {
+		h.broadcast(r)
+	}
+
+	return nil
+}
+
+// Run releases the expired locks until ctx is done, then closes the hub.
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.Close()
+			return
+		case now := <-ticker.C:
+			h.expire(now)
+		}
+	}
+}
+
+// Close ends every session, the hijacked connections are not
+// drained by the server on shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for album, r := range h.rooms {
+		for _, e := range r.editors {
+			close(e.messages)
+		}
+		delete(h.rooms, album)
+	}
+}
+
+// expire releases the locks expired at now.
+func (h *Hub) expire(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, r := range h.rooms {
+		expired := false
+
+		for field, held := range r.locks {
+			if now.After(held.expires) {
+				delete(r.locks, field)
+				expired = true
+			}
+		}
+
+		if expired {
+			h.broadcast(r)
+		}
+	}
+}
+
+// find returns the room and the editor of session, if it is still joined.
+func (h *Hub) find(session *domain.PresenceSession) (*room, *editor) {
+	r, ok := h.rooms[session.Album]
+	if !ok {
+		return nil, nil
+	}
+
+	for _, e := range r.editors {
+		if e.id == session.ID {
+			return r, e
+		}
+	}
+
+	return r, nil
+}
+
+// broadcast sends the editors of r to all of them, again
+// as long as some are dropped for falling behind.
+func (h *Hub) broadcast(r *room) {
+	for len(r.editors) > 0 {
+		if !h.deliver(r, &domain.PresenceMessage{Type: domain.PresenceEditors, Editors: r.views()}, r.editors...) {
+			return
+		}
+	}
+}
+
+// reply sends message to e alone.
+func (h *Hub) reply(r *room, e *editor, message *domain.PresenceMessage) {
+	if h.deliver(r, message, e) {
+		h.broadcast(r)
+	}
+}
+
+// deliver queues message for the editors, the ones falling behind are
+// removed. It reports whether any was.
+func (h *Hub) deliver(r *room, message *domain.PresenceMessage, editors ...*editor) bool {
+	dropped := false
+
+	for _, e := range slices.Clone(editors) {
+		select {
+		case e.messages <- message:
+		default:
+			h.remove(r, e)
+			dropped = true
+		}
+	}
+
+	return dropped
+}
+
+// remove removes e from r, and r from the hub once empty.
+func (h *Hub) remove(r *room, e *editor) {
+	r.release(e)
+	r.editors = slices.DeleteFunc(r.editors, func(other *editor) bool { return other == e })
+	close(e.messages)
+
+	if len(r.editors) == 0 {
+		delete(h.rooms, r.album)
+	}
+}
+
+// release releases the locks of e.
+func (r *room) release(e *editor) {
+	for field, held := range r.locks {
+		if held.holder == e {
+			delete(r.locks, field)
+		}
+	}
+}
+
+// views returns the editors of r, with the fields they have locked.
+func (r *room) views() []*domain.Editor {
+	views := make([]*domain.Editor, 0, len(r.editors))
+
+	for _, e := range r.editors {
+		views = append(views, r.view(e))
+	}
+
+	return views
+}
+
+func (r *room) view(e *editor) *domain.Editor {
+	fields := []string{}
+
+	for _, field := range domain.PresenceFields {
+		if held, ok := r.locks[field]; ok && held.holder == e {
+			fields = append(fields, field)
+		}
+	}
+
+	return &domain.Editor{ID: e.id, User: e.actor, State: e.state, Fields: fields}
+}
+
+// failure is the answer to an invalid message.
+func failure(err *domain.Error) *domain.PresenceMessage {
+	return &domain.PresenceMessage{Type: domain.PresenceError, Error: err.Code}
+}
@@ -0,0 +1,149 @@
+package publisher
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// next returns the next message of session, failing if none is queued.
+func next(t *testing.T, session *domain.PresenceSession) *domain.PresenceMessage {
+	select {
+	case message := <-session.Messages:
+		return message
+	default:
+		t.Fatal("no message queued")
+		return nil
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub(time.Minute)
+	album := uuid.New()
+
+	alice := domain.Actor{UUID: uuid.New(), Email: "alice@example.com"}
+	bob := domain.Actor{UUID: uuid.New(), Email: "bob@example.com"}
+
+	first := hub.Join(album, alice)
+	assert.Equal(t, []*domain.Editor{{ID: first.ID, User: alice, State: domain.PresenceViewing, Fields: []string{}}}, next(t, first).Editors)
+
+	second := hub.Join(album, bob)
+	assert.Len(t, next(t, first).Editors, 2)
+	assert.Len(t, next(t, second).Editors, 2)
+
+	// locking a field makes the editor editing
+	hub.Receive(first, &domain.PresenceMessage{Type: domain.PresenceLock, Field: "name"})
+
+	editors := next(t, second).Editors
+	assert.Equal(t, domain.PresenceEditing, editors[0].State)
+	assert.Equal(t, []string{"name"}, editors[0].Fields)
+	next(t, first)
+
+	// a renewal is not broadcast
+	hub.Receive(first, &domain.PresenceMessage{Type: domain.PresenceLock, Field: "name"})
+	assert.Len(t, first.Messages, 0)
+
+	// the field is held by another editor
+	hub.Receive(second, &domain.PresenceMessage{Type: domain.PresenceLock, Field: "name"})
+
+	denied := next(t, second)
+	assert.Equal(t, domain.PresenceLockDenied, denied.Type)
+	assert.Equal(t, first.ID, denied.Holder.ID)
+	assert.Len(t, first.Messages, 0)
+
+	// the invalid messages are answered with the error code
+	hub.Receive(second, &domain.PresenceMessage{Type: domain.PresenceLock, Field: "version"})
+	assert.Equal(t, domain.ErrPresenceField.Code, next(t, second).Error)
+
+	hub.Receive(second, &domain.PresenceMessage{Type: domain.PresenceState, State: "away"})
+	assert.Equal(t, domain.ErrPresenceState.Code, next(t, second).Error)
+
+	hub.Receive(second, &domain.PresenceMessage{Type: "play"})
+	assert.Equal(t, domain.ErrPresenceMessage.Code, next(t, second).Error)
+
+	// the album events are forwarded to its editors
+	event := &domain.Event{ID: uuid.New(), Type: domain.EventAlbumUpdated, AggregateType: "album", AggregateID: album}
+	assert.NoError(t, hub.Publish(context.Background(), event))
+	assert.NoError(t, hub.Publish(context.Background(), &domain.Event{ID: uuid.New(), Type: domain.EventAlbumUpdated, AggregateID: uuid.New()}))
+
+	assert.Equal(t, event, next(t, first).Event)
+	assert.Equal(t, event, next(t, second).Event)
+	assert.Len(t, first.Messages, 0)
+
+	// leaving releases the locks
+	hub.Leave(first)
+
+	_, open := <-first.Messages
+	assert.False(t, open)
+
+	editors = next(t, second).Editors
+	assert.Len(t, editors, 1)
+
+	hub.Receive(second, &domain.PresenceMessage{Type: domain.PresenceLock, Field: "name"})
+	assert.Equal(t, []string{"name"}, next(t, second).Editors[0].Fields)
+
+	// viewing releases the locks
+	hub.Receive(second, &domain.PresenceMessage{Type: domain.PresenceState, State: domain.PresenceViewing})
+	assert.Equal(t, &domain.Editor{ID: second.ID, User: bob, State: domain.PresenceViewing, Fields: []string{}}, next(t, second).Editors[0])
+
+	// the hub ends the sessions on shutdown
+	hub.Close()
+
+	_, open = <-second.Messages
+	assert.False(t, open)
+
+	_, open = <-hub.Join(album, alice).Messages
+	assert.False(t, open)
+}
+
+func TestHubLockExpiry(t *testing.T) {
+	hub := NewHub(time.Minute)
+	album := uuid.New()
+
+	session := hub.Join(album, domain.Actor{UUID: uuid.New()})
+	next(t, session)
+
+	hub.Receive(session, &domain.PresenceMessage{Type: domain.PresenceLock, Field: "length"})
+	next(t, session)
+
+	hub.expire(time.Now())
+	assert.Len(t, session.Messages, 0)
+
+	// the locks not renewed in time are released
+	hub.expire(time.Now().Add(2 * time.Minute))
+	assert.Empty(t, next(t, session).Editors[0].Fields)
+}
+
+func TestHubSlowSession(t *testing.T) {
+	hub := NewHub(time.Minute)
+	album := uuid.New()
+
+	slow := hub.Join(album, domain.Actor{UUID: uuid.New()})
+	fast := hub.Join(album, domain.Actor{UUID: uuid.New()})
+
+	// a session not keeping up is dropped rather than blocking the hub
+	var editors []*domain.Editor
+	for i := 0; i < sessionBuffer; i++ {
+		assert.NoError(t, hub.Publish(context.Background(), &domain.Event{ID: uuid.New(), Type: domain.EventAlbumUpdated, AggregateID: album}))
+
+		for len(fast.Messages) > 0 {
+			if message := <-fast.Messages; message.Type == domain.PresenceEditors {
+				editors = message.Editors
+			}
+		}
+	}
+
+	received := 0
+	for range slow.Messages {
+		received++
+	}
+	assert.Equal(t, sessionBuffer, received)
+
+	// the others are told it left
+	assert.Equal(t, fast.ID, editors[0].ID)
+	assert.Len(t, editors, 1)
+}
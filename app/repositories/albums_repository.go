@@ -3,12 +3,17 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"hexagony/app/domain"
 	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type albumsRepository struct {
@@ -22,6 +27,9 @@ func NewAlbumsRepository(conn *sqlx.DB) domain.AlbumsRepository {
 func (r *albumsRepository) FindAll(
 	ctx context.Context,
 ) ([]*domain.Albums, error) {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.FindAll", "SqlAlbumsFindAll")
+	defer span.End()
+
 	var albums []*domain.Albums
 
 	err := r.conn.SelectContext(
@@ -35,6 +43,7 @@ func (r *albumsRepository) FindAll(
 	}
 
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, domain.ErrAlbumsFindAll
 	}
 
@@ -45,12 +54,23 @@ func (r *albumsRepository) FindByID(
 	ctx context.Context,
 	uuid uuid.UUID,
 ) (*domain.Albums, error) {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.FindByID", "SqlAlbumsFindByID")
+	defer span.End()
+
 	var album domain.Albums
 
-	err := r.conn.GetContext(
+	// in a transaction the row is locked, the write reading it
+	// then changes the version it read
+	query := queries.SqlAlbumsFindByID
+	if _, ok := txFromContext(ctx); ok {
+		query = queries.SqlAlbumsFindByIDForUpdate
+	}
+
+	err := sqlx.GetContext(
 		ctx,
+		executor(ctx, r.conn),
 		&album,
-		queries.SqlAlbumsFindByID,
+		query,
 		uuid,
 	)
 
@@ -60,29 +80,68 @@ func (r *albumsRepository) FindByID(
 	}
 
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, domain.ErrAlbumsFindByID
 	}
 
 	return &album, nil
 }
 
+func (r *albumsRepository) FindByIDs(
+	ctx context.Context,
+	uuids []uuid.UUID,
+) ([]*domain.Albums, error) {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.FindByIDs", "SqlAlbumsFindByIDs")
+	defer span.End()
+
+	var albums []*domain.Albums
+
+	err := sqlx.SelectContext(
+		ctx,
+		executor(ctx, r.conn),
+		&albums,
+		queries.SqlAlbumsFindByIDs,
+		pq.Array(uuidStrings(uuids)),
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrAlbumsFindByID
+	}
+
+	return albums, nil
+}
+
 func (r *albumsRepository) Add(
 	ctx context.Context,
 	album *domain.Albums,
 ) error {
-	if _, err := r.conn.ExecContext(
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Add", "SqlAlbumsAdd")
+	defer span.End()
+
+	return inTx(ctx, r.conn, domain.ErrAlbumsAdd, func(tx *sqlx.Tx) error {
+		return r.add(ctx, tx, album)
+	})
+}
+
+// add inserts the album and its first revision within tx.
+func (r *albumsRepository) add(ctx context.Context, tx *sqlx.Tx, album *domain.Albums) error {
+	if _, err := tx.ExecContext(
 		ctx,
 		queries.SqlAlbumsAdd,
 		album.UUID,
 		album.Name,
 		album.Length,
+		album.Barcode,
 		album.CreatedAt,
 		album.UpdatedAt,
 	); err != nil {
+		tracing.Error(trace.SpanFromContext(ctx), err)
 		return domain.ErrAlbumsAdd
 	}
 
-	return nil
+	album.Version = 1
+
+	return r.addRevision(ctx, tx, domain.RevisionCreated, domain.ErrAlbumsAdd, album.UUID)
 }
 
 func (r *albumsRepository) Update(
@@ -90,51 +149,660 @@ func (r *albumsRepository) Update(
 	uuid uuid.UUID,
 	album *domain.Albums,
 ) error {
-	result, err := r.conn.ExecContext(
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Update", "SqlAlbumsUpdate")
+	defer span.End()
+
+	return inTx(ctx, r.conn, domain.ErrAlbumsUpdate, func(tx *sqlx.Tx) error {
+		return r.update(ctx, tx, uuid, album)
+	})
+}
+
+// update writes the album and its revision within tx.
+func (r *albumsRepository) update(ctx context.Context, tx *sqlx.Tx, uuid uuid.UUID, album *domain.Albums) error {
+	err := tx.QueryRowxContext(
 		ctx,
 		queries.SqlAlbumsUpdate,
 		album.Name,
 		album.Length,
+		album.Barcode,
 		album.UpdatedAt,
 		uuid,
-	)
-	if err != nil {
-		return domain.ErrAlbumsUpdate
+		album.Version,
+	).Scan(&album.Version)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingRow(ctx, tx, queries.SqlAlbumsVersion, uuid, domain.ErrAlbumsUpdate)
 	}
 
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
+		tracing.Error(trace.SpanFromContext(ctx), err)
 		return domain.ErrAlbumsUpdate
 	}
 
-	if rowsAffected == 0 {
-		return domain.ErrResourceNotFound
+	return r.addRevision(ctx, tx, domain.RevisionUpdated, domain.ErrAlbumsUpdate, uuid)
+}
+
+func (r *albumsRepository) Patch(
+	ctx context.Context,
+	uuid uuid.UUID,
+	patch *domain.AlbumsPatch,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Patch", "SqlPatch")
+	defer span.End()
+
+	if patch.Empty() {
+		return nil
 	}
 
-	return nil
+	var (
+		columns []string
+		args    []interface{}
+	)
+
+	if patch.Name != nil {
+		columns, args = append(columns, "name"), append(args, *patch.Name)
+	}
+	if patch.Length != nil {
+		columns, args = append(columns, "length"), append(args, *patch.Length)
+	}
+	if patch.Barcode != nil {
+		columns, args = append(columns, "barcode"), append(args, *patch.Barcode)
+	}
+
+	columns, args = append(columns, "updated_at"), append(args, patch.UpdatedAt, uuid, patch.Version)
+
+	return inTx(ctx, r.conn, domain.ErrAlbumsUpdate, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowxContext(
+			ctx,
+			queries.SqlPatch("albums", columns),
+			args...,
+		).Scan(&patch.Version)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return missingRow(ctx, tx, queries.SqlAlbumsVersion, uuid, domain.ErrAlbumsUpdate)
+		}
+
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAlbumsUpdate
+		}
+
+		return r.addRevision(ctx, tx, domain.RevisionUpdated, domain.ErrAlbumsUpdate, uuid)
+	})
 }
 
 func (r *albumsRepository) Delete(
 	ctx context.Context,
 	uuid uuid.UUID,
+	version int,
 ) error {
-	result, err := r.conn.ExecContext(
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Delete", "SqlAlbumsDelete")
+	defer span.End()
+
+	return inTx(ctx, r.conn, domain.ErrAlbumsDelete, func(tx *sqlx.Tx) error {
+		_, err := r.delete(ctx, tx, uuid, version)
+		return err
+	})
+}
+
+// delete moves the album to the trash and records its revision within
+// tx, returning the new version.
+func (r *albumsRepository) delete(ctx context.Context, tx *sqlx.Tx, uuid uuid.UUID, version int) (int, error) {
+	err := tx.QueryRowxContext(
 		ctx,
 		queries.SqlAlbumsDelete,
+		time.Now(),
 		uuid,
+		version,
+	).Scan(&version)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missingRow(ctx, tx, queries.SqlAlbumsVersion, uuid, domain.ErrAlbumsDelete)
+	}
+
+	if err != nil {
+		tracing.Error(trace.SpanFromContext(ctx), err)
+		return 0, domain.ErrAlbumsDelete
+	}
+
+	return version, r.addRevision(ctx, tx, domain.RevisionDeleted, domain.ErrAlbumsDelete, uuid)
+}
+
+func (r *albumsRepository) FindTrash(
+	ctx context.Context,
+) ([]*domain.Albums, error) {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.FindTrash", "SqlAlbumsFindTrash")
+	defer span.End()
+
+	var albums []*domain.Albums
+
+	err := r.conn.SelectContext(
+		ctx,
+		&albums,
+		queries.SqlAlbumsFindTrash,
+	)
+	noRows := errors.Is(err, sql.ErrNoRows)
+	if noRows {
+		return albums, nil
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrAlbumsFindTrash
+	}
+
+	return albums, nil
+}
+
+func (r *albumsRepository) Restore(
+	ctx context.Context,
+	uuid uuid.UUID,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Restore", "SqlAlbumsRestore")
+	defer span.End()
+
+	return inTx(ctx, r.conn, domain.ErrAlbumsRestore, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(
+			ctx,
+			queries.SqlAlbumsRestore,
+			time.Now(),
+			uuid,
+		)
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAlbumsRestore
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAlbumsRestore
+		}
+
+		if rowsAffected == 0 {
+			return domain.ErrResourceNotFound
+		}
+
+		return r.addRevision(ctx, tx, domain.RevisionRestored, domain.ErrAlbumsRestore, uuid)
+	})
+}
+
+func (r *albumsRepository) Purge(
+	ctx context.Context,
+	before time.Time,
+) (int64, error) {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Purge", "SqlAlbumsPurge")
+	defer span.End()
+
+	result, err := executor(ctx, r.conn).ExecContext(
+		ctx,
+		queries.SqlAlbumsPurge,
+		before,
 	)
 	if err != nil {
-		return domain.ErrAlbumsDelete
+		tracing.Error(span, err)
+		return 0, domain.ErrAlbumsPurge
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return domain.ErrAlbumsDelete
+		tracing.Error(span, err)
+		return 0, domain.ErrAlbumsPurge
+	}
+
+	return rowsAffected, nil
+}
+
+// albumRevision is a row of album_revisions.
+type albumRevision struct {
+	AlbumUUID  uuid.UUID  `db:"album_uuid"`
+	Revision   int        `db:"revision"`
+	Action     string     `db:"action"`
+	Snapshot   []byte     `db:"snapshot"`
+	ActorUUID  *uuid.UUID `db:"actor_uuid"`
+	ActorEmail string     `db:"actor_email"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+func (r *albumsRepository) FindRevisions(
+	ctx context.Context,
+	uuid uuid.UUID,
+) ([]*domain.AlbumRevision, error) {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.FindRevisions", "SqlAlbumsFindRevisions")
+	defer span.End()
+
+	var rows []albumRevision
+
+	err := r.conn.SelectContext(
+		ctx,
+		&rows,
+		queries.SqlAlbumsFindRevisions,
+		uuid,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tracing.Error(span, err)
+		return nil, domain.ErrAlbumsFindRevisions
+	}
+
+	revisions := make([]*domain.AlbumRevision, 0, len(rows))
+
+	for _, row := range rows {
+		revision := domain.AlbumRevision{
+			AlbumUUID:  row.AlbumUUID,
+			Revision:   row.Revision,
+			Action:     row.Action,
+			ActorUUID:  row.ActorUUID,
+			ActorEmail: row.ActorEmail,
+			CreatedAt:  row.CreatedAt,
+		}
+
+		if err := json.Unmarshal(row.Snapshot, &revision.Snapshot); err != nil {
+			tracing.Error(span, err)
+			return nil, domain.ErrAlbumsFindRevisions
+		}
+
+		revisions = append(revisions, &revision)
+	}
+
+	return revisions, nil
+}
+
+func (r *albumsRepository) RestoreRevision(
+	ctx context.Context,
+	uuid uuid.UUID,
+	revision int,
+	version int,
+) (int, error) {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.RestoreRevision", "SqlAlbumsFindRevision")
+	defer span.End()
+
+	err := inTx(ctx, r.conn, domain.ErrAlbumsRestoreRevision, func(tx *sqlx.Tx) error {
+		var snapshot []byte
+
+		err := tx.GetContext(ctx, &snapshot, queries.SqlAlbumsFindRevision, uuid, revision)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrResourceNotFound
+		}
+
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAlbumsRestoreRevision
+		}
+
+		var album domain.Albums
+
+		if err := json.Unmarshal(snapshot, &album); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAlbumsRestoreRevision
+		}
+
+		err = tx.QueryRowxContext(
+			ctx,
+			queries.SqlAlbumsUpdate,
+			album.Name,
+			album.Length,
+			album.Barcode,
+			time.Now(),
+			uuid,
+			version,
+		).Scan(&version)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return missingRow(ctx, tx, queries.SqlAlbumsVersion, uuid, domain.ErrAlbumsRestoreRevision)
+		}
+
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAlbumsRestoreRevision
+		}
+
+		return r.addRevision(ctx, tx, domain.RevisionReverted, domain.ErrAlbumsRestoreRevision, uuid)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// addRevision records the current state of the albums, within the
+// transaction of the write, along with the actor of the request.
+func (r *albumsRepository) addRevision(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	action string,
+	fallback error,
+	albumUUIDs ...uuid.UUID,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.addRevision", "SqlAlbumsAddRevision")
+	defer span.End()
+
+	var (
+		actorUUID  *uuid.UUID
+		actorEmail string
+	)
+
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		actorUUID, actorEmail = &actor.UUID, actor.Email
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		queries.SqlAlbumsAddRevision,
+		pq.Array(uuidStrings(albumUUIDs)),
+		action,
+		actorUUID,
+		actorEmail,
+		time.Now(),
+	); err != nil {
+		tracing.Error(span, err)
+		return fallback
+	}
+
+	return nil
+}
+
+// uuidStrings converts the UUIDs for a postgres array parameter.
+func uuidStrings(uuids []uuid.UUID) []string {
+	values := make([]string, len(uuids))
+	for i, id := range uuids {
+		values[i] = id.String()
+	}
+
+	return values
+}
+
+func (r *albumsRepository) Bulk(
+	ctx context.Context,
+	operations []*domain.AlbumsBulkOperation,
+	atomic bool,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Bulk", "SqlAlbumsCopy")
+	defer span.End()
+
+	var creates, pending []*domain.AlbumsBulkOperation
+
+	for _, operation := range operations {
+		if operation.Op == domain.BulkCreate {
+			creates = append(creates, operation)
+		} else {
+			pending = append(pending, operation)
+		}
+	}
+
+	return inTx(ctx, r.conn, domain.ErrAlbumsBulk, func(tx *sqlx.Tx) error {
+		if len(creates) > 0 {
+			copied, err := r.copy(ctx, tx, creates)
+			if err != nil {
+				return err
+			}
+
+			// the albums are inserted one by one to find the failed ones
+			if !copied {
+				pending = append(creates, pending...)
+			}
+		}
+
+		for _, operation := range pending {
+			if err := r.bulkWrite(ctx, tx, operation, atomic); err != nil {
+				return err
+			}
+
+			if atomic && operation.Err != nil {
+				return domain.ErrAlbumsBulkAborted
+			}
+		}
+
+		return nil
+	})
+}
+
+// copy inserts the albums with COPY, along with their revisions. It
+// reports false, leaving tx as it was, when the copy failed.
+func (r *albumsRepository) copy(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	operations []*domain.AlbumsBulkOperation,
+) (bool, error) {
+	if err := savepoint(ctx, tx, domain.ErrAlbumsBulk, queries.SqlSavepoint); err != nil {
+		return false, err
+	}
+
+	if err := r.copyIn(ctx, tx, operations); err != nil {
+		return false, savepoint(ctx, tx, domain.ErrAlbumsBulk, queries.SqlRollbackToSavepoint, queries.SqlReleaseSavepoint)
+	}
+
+	for _, operation := range operations {
+		operation.Album.Version = 1
+	}
+
+	return true, savepoint(ctx, tx, domain.ErrAlbumsBulk, queries.SqlReleaseSavepoint)
+}
+
+func (r *albumsRepository) copyIn(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	operations []*domain.AlbumsBulkOperation,
+) error {
+	span := trace.SpanFromContext(ctx)
+
+	stmt, err := tx.PrepareContext(ctx, queries.SqlAlbumsCopy)
+	if err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	defer stmt.Close()
+
+	uuids := make([]uuid.UUID, len(operations))
+
+	for i, operation := range operations {
+		album := &operation.Album
+		uuids[i] = album.UUID
+
+		if _, err := stmt.ExecContext(
+			ctx,
+			album.UUID.String(),
+			album.Name,
+			album.Length,
+			album.Barcode,
+			album.CreatedAt,
+			album.UpdatedAt,
+		); err != nil {
+			tracing.Error(span, err)
+			return err
+		}
+	}
+
+	// flushes the rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+
+	return r.addRevision(ctx, tx, domain.RevisionCreated, domain.ErrAlbumsAdd, uuids...)
+}
+
+// bulkWrite applies an operation of a bulk, within a savepoint unless
+// the bulk is atomic so that a failed operation is undone alone. The
+// failure of the operation is set on it, the error returned is the
+// failure of the transaction itself.
+func (r *albumsRepository) bulkWrite(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	operation *domain.AlbumsBulkOperation,
+	atomic bool,
+) error {
+	if !atomic {
+		if err := savepoint(ctx, tx, domain.ErrAlbumsBulk, queries.SqlSavepoint); err != nil {
+			return err
+		}
+	}
+
+	album := &operation.Album
+
+	switch operation.Op {
+	case domain.BulkCreate:
+		operation.Err = r.add(ctx, tx, album)
+	case domain.BulkUpdate:
+		operation.Err = r.update(ctx, tx, album.UUID, album)
+	case domain.BulkDelete:
+		album.Version, operation.Err = r.delete(ctx, tx, album.UUID, album.Version)
+	}
+
+	if atomic {
+		return nil
+	}
+
+	if operation.Err != nil {
+		return savepoint(ctx, tx, domain.ErrAlbumsBulk, queries.SqlRollbackToSavepoint, queries.SqlReleaseSavepoint)
+	}
+
+	return savepoint(ctx, tx, domain.ErrAlbumsBulk, queries.SqlReleaseSavepoint)
+}
+
+func (r *albumsRepository) Export(
+	ctx context.Context,
+	fn func(*domain.Albums) error,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Export", "SqlAlbumsExport")
+	defer span.End()
+
+	rows, err := r.conn.QueryxContext(ctx, queries.SqlAlbumsExport)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrAlbumsExport
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var album domain.Albums
+
+		if err := rows.StructScan(&album); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAlbumsExport
+		}
+
+		if err := fn(&album); err != nil {
+			return err
+		}
 	}
 
-	if rowsAffected == 0 {
-		return domain.ErrResourceNotFound
+	if err := rows.Err(); err != nil {
+		tracing.Error(span, err)
+		return domain.ErrAlbumsExport
 	}
 
 	return nil
 }
+
+// errDryRun rolls back the transaction of a dry run, which
+// must not join a transaction it could not roll back.
+var errDryRun = errors.New("dry run")
+
+func (r *albumsRepository) Import(
+	ctx context.Context,
+	rows []*domain.AlbumsImportRow,
+	dryRun bool,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "albumsRepository.Import", "SqlAlbumsUpsert")
+	defer span.End()
+
+	err := inTx(ctx, r.conn, domain.ErrAlbumsImport, func(tx *sqlx.Tx) error {
+		for _, row := range rows {
+			if err := savepoint(ctx, tx, domain.ErrAlbumsImport, queries.SqlSavepoint); err != nil {
+				return err
+			}
+
+			row.Outcome, row.Err = r.upsert(ctx, tx, &row.Album, row.RequireVersion)
+
+			statements := []string{queries.SqlReleaseSavepoint}
+			if row.Err != nil {
+				statements = []string{queries.SqlRollbackToSavepoint, queries.SqlReleaseSavepoint}
+			}
+
+			if err := savepoint(ctx, tx, domain.ErrAlbumsImport, statements...); err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+
+	return err
+}
+
+// upsert writes the album of an imported row within tx, matching it by
+// its UUID or else by its barcode, and returns the outcome. The update
+// of a matched album is conditioned on the version of album, which
+// requireVersion makes mandatory.
+func (r *albumsRepository) upsert(ctx context.Context, tx *sqlx.Tx, album *domain.Albums, requireVersion bool) (string, error) {
+	span := trace.SpanFromContext(ctx)
+
+	if album.UUID == uuid.Nil && album.Barcode != "" {
+		var matches []uuid.UUID
+
+		if err := tx.SelectContext(ctx, &matches, queries.SqlAlbumsFindByBarcode, album.Barcode); err != nil {
+			tracing.Error(span, err)
+			return "", domain.ErrAlbumsImport
+		}
+
+		switch len(matches) {
+		case 0:
+		case 1:
+			album.UUID = matches[0]
+		default:
+			return "", domain.ErrAlbumsImportAmbiguous
+		}
+	}
+
+	if album.UUID == uuid.Nil {
+		album.UUID = uuid.New()
+	}
+
+	err := tx.QueryRowxContext(
+		ctx,
+		queries.SqlAlbumsUpsert,
+		album.UUID,
+		album.Name,
+		album.Length,
+		album.Barcode,
+		album.CreatedAt,
+		album.UpdatedAt,
+		album.Version,
+		requireVersion,
+	).Scan(&album.Version)
+
+	// the matched album is in the trash or not at the version
+	if errors.Is(err, sql.ErrNoRows) {
+		err = missingRow(ctx, tx, queries.SqlAlbumsVersion, album.UUID, domain.ErrAlbumsImport)
+
+		switch {
+		case errors.Is(err, domain.ErrResourceNotFound):
+			return "", domain.ErrAlbumsImportTrashed
+		case errors.Is(err, domain.ErrPreconditionFailed) && album.Version == 0:
+			return "", domain.ErrAlbumsImportVersion
+		}
+
+		return "", err
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return "", domain.ErrAlbumsImport
+	}
+
+	// an inserted album starts at the first version
+	if album.Version == 1 {
+		return domain.ImportCreated, r.addRevision(ctx, tx, domain.RevisionCreated, domain.ErrAlbumsImport, album.UUID)
+	}
+
+	return domain.ImportUpdated, r.addRevision(ctx, tx, domain.RevisionUpdated, domain.ErrAlbumsImport, album.UUID)
+}
@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type auditLogRepository struct {
+	conn *sqlx.DB
+}
+
+func NewAuditLogRepository(conn *sqlx.DB) domain.AuditLog {
+	return &auditLogRepository{conn}
+}
+
+// auditRow is a row of audit_log.
+type auditRow struct {
+	ID           int64      `db:"id"`
+	ActorUUID    *uuid.UUID `db:"actor_uuid"`
+	ActorEmail   string     `db:"actor_email"`
+	Action       string     `db:"action"`
+	Outcome      string     `db:"outcome"`
+	ResourceType string     `db:"resource_type"`
+	ResourceID   string     `db:"resource_id"`
+	IP           string     `db:"ip"`
+	RequestID    string     `db:"request_id"`
+	Changes      []byte     `db:"changes"`
+	CreatedAt    time.Time  `db:"created_at"`
+	PrevHash     string     `db:"prev_hash"`
+	Hash         string     `db:"hash"`
+}
+
+func (row *auditRow) entry() (*domain.AuditEntry, error) {
+	entry := domain.AuditEntry{
+		ID:           row.ID,
+		ActorUUID:    row.ActorUUID,
+		ActorEmail:   row.ActorEmail,
+		Action:       row.Action,
+		Outcome:      row.Outcome,
+		ResourceType: row.ResourceType,
+		ResourceID:   row.ResourceID,
+		IP:           row.IP,
+		RequestID:    row.RequestID,
+		CreatedAt:    row.CreatedAt,
+		PrevHash:     row.PrevHash,
+		Hash:         row.Hash,
+	}
+
+	if err := json.Unmarshal(row.Changes, &entry.Changes); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *auditLogRepository) Record(
+	ctx context.Context,
+	entries ...*domain.AuditEntry,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "auditLogRepository.Record", "SqlAuditAdd")
+	defer span.End()
+
+	changes := make([][]byte, len(entries))
+
+	for i, entry := range entries {
+		// postgres keeps microseconds, the hash must cover the stored time
+		entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+
+		if entry.Changes == nil {
+			entry.Changes = []domain.FieldChange{}
+		}
+
+		var err error
+		if changes[i], err = json.Marshal(entry.Changes); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAuditRecord
+		}
+
+		// the changes are hashed as they are read back from the database
+		if err := json.Unmarshal(changes[i], &entry.Changes); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAuditRecord
+		}
+	}
+
+	// the chain is locked once for every entry, until the
+	// transaction of the write they record commits
+	return inTx(ctx, r.conn, domain.ErrAuditRecord, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, queries.SqlAuditLock); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrAuditRecord
+		}
+
+		var prevHash string
+
+		err := tx.GetContext(ctx, &prevHash, queries.SqlAuditLastHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			tracing.Error(span, err)
+			return domain.ErrAuditRecord
+		}
+
+		for i, entry := range entries {
+			entry.PrevHash = prevHash
+			entry.Hash = entry.ComputeHash()
+
+			err = tx.QueryRowxContext(
+				ctx,
+				queries.SqlAuditAdd,
+				entry.ActorUUID,
+				entry.ActorEmail,
+				entry.Action,
+				entry.Outcome,
+				entry.ResourceType,
+				entry.ResourceID,
+				entry.IP,
+				entry.RequestID,
+				changes[i],
+				entry.CreatedAt,
+				entry.PrevHash,
+				entry.Hash,
+			).Scan(&entry.ID)
+			if err != nil {
+				tracing.Error(span, err)
+				return domain.ErrAuditRecord
+			}
+
+			prevHash = entry.Hash
+		}
+
+		return nil
+	})
+}
+
+func (r *auditLogRepository) Find(
+	ctx context.Context,
+	filter domain.AuditFilter,
+) ([]*domain.AuditEntry, error) {
+	ctx, span := tracing.StartQuery(ctx, "auditLogRepository.Find", "SqlAuditFind")
+	defer span.End()
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	where := func(column, operator string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s %s $%d", column, operator, len(args)))
+	}
+
+	if filter.ActorUUID != nil {
+		where("actor_uuid", "=", filter.ActorUUID)
+	}
+	if filter.ActorEmail != "" {
+		where("actor_email", "=", filter.ActorEmail)
+	}
+	if filter.Action != "" {
+		where("action", "=", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		where("resource_type", "=", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		where("resource_id", "=", filter.ResourceID)
+	}
+	if filter.From != nil {
+		where("created_at", ">=", *filter.From)
+	}
+	if filter.To != nil {
+		where("created_at", "<", *filter.To)
+	}
+	if filter.Before > 0 {
+		where("id", "<", filter.Before)
+	}
+
+	var rows []auditRow
+
+	err := r.conn.SelectContext(
+		ctx,
+		&rows,
+		queries.SqlAuditFind(conditions),
+		append(args, filter.Limit)...,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tracing.Error(span, err)
+		return nil, domain.ErrAuditFind
+	}
+
+	entries := make([]*domain.AuditEntry, 0, len(rows))
+
+	for i := range rows {
+		entry, err := rows[i].entry()
+		if err != nil {
+			tracing.Error(span, err)
+			return nil, domain.ErrAuditFind
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (r *auditLogRepository) Verify(
+	ctx context.Context,
+) (*domain.AuditVerification, error) {
+	ctx, span := tracing.StartQuery(ctx, "auditLogRepository.Verify", "SqlAuditVerify")
+	defer span.End()
+
+	rows, err := r.conn.QueryxContext(ctx, queries.SqlAuditVerify)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrAuditVerify
+	}
+	defer rows.Close()
+
+	verification := domain.AuditVerification{Valid: true}
+	prevHash := ""
+
+	for rows.Next() {
+		var row auditRow
+
+		if err := rows.StructScan(&row); err != nil {
+			tracing.Error(span, err)
+			return nil, domain.ErrAuditVerify
+		}
+
+		entry, err := row.entry()
+		if err != nil {
+			tracing.Error(span, err)
+			return nil, domain.ErrAuditVerify
+		}
+
+		verification.Entries++
+
+		if entry.PrevHash != prevHash || entry.Hash != entry.ComputeHash() {
+			verification.Valid = false
+			verification.BrokenAt = &entry.ID
+			return &verification, nil
+		}
+
+		prevHash = entry.Hash
+	}
+
+	if err := rows.Err(); err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrAuditVerify
+	}
+
+	return &verification, nil
+}
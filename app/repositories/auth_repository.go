@@ -6,6 +6,7 @@ import (
 	"errors"
 	"hexagony/app/domain"
 	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -19,6 +20,9 @@ func NewAuthRepository(Conn *sqlx.DB) domain.AuthRepository {
 }
 
 func (p *authRepository) Authenticate(ctx context.Context, email string) (*domain.Users, error) {
+	ctx, span := tracing.StartQuery(ctx, "authRepository.Authenticate", "SqlAuthGetUser")
+	defer span.End()
+
 	var user domain.Users
 
 	err := p.Conn.GetContext(ctx, &user, queries.SqlAuthGetUser, email)
@@ -29,6 +33,7 @@ func (p *authRepository) Authenticate(ctx context.Context, email string) (*domai
 	}
 
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, domain.ErrAuth
 	}
 
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type idempotencyRepository struct {
+	conn *sqlx.DB
+	now  func() time.Time
+}
+
+// NewIdempotencyRepository creates an idempotency store shared
+// by every replica of the application.
+func NewIdempotencyRepository(conn *sqlx.DB) domain.IdempotencyStore {
+	return &idempotencyRepository{conn: conn, now: time.Now}
+}
+
+// idempotencyRow is a row of idempotency_keys.
+type idempotencyRow struct {
+	Scope       string    `db:"scope"`
+	Key         string    `db:"key"`
+	Fingerprint string    `db:"fingerprint"`
+	Status      int       `db:"status"`
+	Header      []byte    `db:"header"`
+	Body        []byte    `db:"body"`
+	CreatedAt   time.Time `db:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
+
+func (r *idempotencyRepository) Reserve(
+	ctx context.Context,
+	record *domain.IdempotencyRecord,
+) (*domain.IdempotencyRecord, error) {
+	ctx, span := tracing.StartQuery(ctx, "idempotencyRepository.Reserve", "SqlIdempotencyReserve")
+	defer span.End()
+
+	// the expired keys are forgotten before they can be reused
+	if _, err := r.conn.ExecContext(ctx, queries.SqlIdempotencyExpire, r.now()); err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrIdempotencyStore
+	}
+
+	result, err := r.conn.ExecContext(
+		ctx,
+		queries.SqlIdempotencyReserve,
+		record.Scope,
+		record.Key,
+		record.Fingerprint,
+		record.CreatedAt,
+		record.ExpiresAt,
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrIdempotencyStore
+	}
+
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrIdempotencyStore
+	}
+
+	if reserved == 1 {
+		return nil, nil
+	}
+
+	var row idempotencyRow
+
+	err = r.conn.GetContext(ctx, &row, queries.SqlIdempotencyFind, record.Scope, record.Key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// released by the request holding it since the insert
+		return nil, domain.ErrIdempotencyInProgress
+	}
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrIdempotencyStore
+	}
+
+	existing := domain.IdempotencyRecord{
+		Scope:       row.Scope,
+		Key:         row.Key,
+		Fingerprint: row.Fingerprint,
+		Status:      row.Status,
+		Body:        row.Body,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+	}
+
+	if row.Header != nil {
+		if err := json.Unmarshal(row.Header, &existing.Header); err != nil {
+			tracing.Error(span, err)
+			return nil, domain.ErrIdempotencyStore
+		}
+	}
+
+	return &existing, nil
+}
+
+func (r *idempotencyRepository) Complete(
+	ctx context.Context,
+	record *domain.IdempotencyRecord,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "idempotencyRepository.Complete", "SqlIdempotencyComplete")
+	defer span.End()
+
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrIdempotencyStore
+	}
+
+	_, err = r.conn.ExecContext(
+		ctx,
+		queries.SqlIdempotencyComplete,
+		record.Scope,
+		record.Key,
+		record.Status,
+		header,
+		record.Body,
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrIdempotencyStore
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	ctx, span := tracing.StartQuery(ctx, "idempotencyRepository.Release", "SqlIdempotencyRelease")
+	defer span.End()
+
+	if _, err := r.conn.ExecContext(ctx, queries.SqlIdempotencyRelease, scope, key); err != nil {
+		tracing.Error(span, err)
+		return domain.ErrIdempotencyStore
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hexagony/app/domain"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instanceLockCheck is the interval at which the connection holding
+// a lock is checked, the lock is lost along with it.
+const instanceLockCheck = 10 * time.Second
+
+type instanceLock struct {
+	conn *sqlx.DB
+	name string
+
+	// held is the connection of the session holding the lock.
+	held *sql.Conn
+}
+
+// NewInstanceLock creates the lock named name, held on a connection
+// of conn taken out of the pool as long as the lock is held.
+func NewInstanceLock(conn *sqlx.DB, name string) domain.InstanceLock {
+	return &instanceLock{conn: conn, name: name}
+}
+
+func (l *instanceLock) Acquire(ctx context.Context) error {
+	held, err := l.conn.Conn(ctx)
+	if err != nil {
+		tracing.Error(trace.SpanFromContext(ctx), err)
+		return domain.ErrInstanceLock
+	}
+
+	var acquired bool
+	if err := held.QueryRowContext(ctx, queries.SqlInstanceLockAcquire, l.name).Scan(&acquired); err != nil {
+		_ = held.Close()
+		tracing.Error(trace.SpanFromContext(ctx), err)
+		return domain.ErrInstanceLock
+	}
+
+	if !acquired {
+		_ = held.Close()
+		return domain.ErrInstanceLocked
+	}
+
+	l.held = held
+	return nil
+}
+
+func (l *instanceLock) Hold(ctx context.Context) error {
+	ticker := time.NewTicker(instanceLockCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return l.release()
+		case <-ticker.C:
+			if err := l.held.PingContext(ctx); err != nil && ctx.Err() == nil {
+				l.discard()
+				return domain.ErrInstanceLockLost
+			}
+		}
+	}
+}
+
+// release unlocks the session before its connection is handed back
+// to the pool, where the lock would otherwise outlive the instance.
+func (l *instanceLock) release() error {
+	if _, err := l.held.ExecContext(context.Background(), queries.SqlInstanceLockRelease, l.name); err != nil {
+		l.discard()
+		return domain.ErrInstanceLockLost
+	}
+
+	return l.held.Close()
+}
+
+// discard closes the connection holding the lock instead of handing
+// it back to the pool, its session ends and the lock with it.
+func (l *instanceLock) discard() {
+	_ = l.held.Raw(func(any) error { return driver.ErrBadConn })
+	_ = l.held.Close()
+}
@@ -0,0 +1,344 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// uniqueViolation is the SQLSTATE of a duplicate key.
+const uniqueViolation = "23505"
+
+type jobsRepository struct {
+	conn *sqlx.DB
+	now  func() time.Time
+}
+
+func NewJobsRepository(conn *sqlx.DB) domain.JobsRepository {
+	return &jobsRepository{conn: conn, now: time.Now}
+}
+
+// jobRow is a row of jobs.
+type jobRow struct {
+	UUID        uuid.UUID      `db:"uuid"`
+	Kind        string         `db:"kind"`
+	Payload     []byte         `db:"payload"`
+	UniqueKey   sql.NullString `db:"unique_key"`
+	Status      string         `db:"status"`
+	Attempts    int            `db:"attempts"`
+	MaxAttempts int            `db:"max_attempts"`
+	RunAt       time.Time      `db:"run_at"`
+	LockID      uuid.NullUUID  `db:"lock_id"`
+	LastError   string         `db:"last_error"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+	FinishedAt  *time.Time     `db:"finished_at"`
+}
+
+func (row *jobRow) job() *domain.Job {
+	return &domain.Job{
+		UUID:        row.UUID,
+		Kind:        row.Kind,
+		Payload:     row.Payload,
+		UniqueKey:   row.UniqueKey.String,
+		Status:      row.Status,
+		Attempts:    row.Attempts,
+		MaxAttempts: row.MaxAttempts,
+		RunAt:       row.RunAt,
+		LockID:      row.LockID.UUID,
+		LastError:   row.LastError,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		FinishedAt:  row.FinishedAt,
+	}
+}
+
+func (r *jobsRepository) FindAll(
+	ctx context.Context,
+	filter domain.JobFilter,
+) ([]*domain.Job, error) {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.FindAll", "SqlJobsFindAll")
+	defer span.End()
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	where := func(column, operator string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s %s $%d", column, operator, len(args)))
+	}
+
+	if filter.Status != "" {
+		where("status", "=", filter.Status)
+	}
+	if filter.Kind != "" {
+		where("kind", "=", filter.Kind)
+	}
+	if filter.Before != nil {
+		where("created_at", "<", *filter.Before)
+	}
+
+	var rows []jobRow
+
+	err := r.conn.SelectContext(ctx, &rows, queries.SqlJobsFindAll(conditions), append(args, filter.Limit)...)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tracing.Error(span, err)
+		return nil, domain.ErrJobsFindAll
+	}
+
+	jobs := make([]*domain.Job, 0, len(rows))
+	for i := range rows {
+		jobs = append(jobs, rows[i].job())
+	}
+
+	return jobs, nil
+}
+
+func (r *jobsRepository) FindByID(
+	ctx context.Context,
+	uuid uuid.UUID,
+) (*domain.Job, error) {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.FindByID", "SqlJobsFindByID")
+	defer span.End()
+
+	var row jobRow
+
+	err := r.conn.GetContext(ctx, &row, queries.SqlJobsFindByID, uuid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrResourceNotFound
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrJobsFindByID
+	}
+
+	return row.job(), nil
+}
+
+// Enqueue joins the transaction carried by ctx, if any, so that
+// the job is only enqueued if the work asking for it is committed.
+func (r *jobsRepository) Enqueue(
+	ctx context.Context,
+	job *domain.Job,
+) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.Enqueue", "SqlJobsEnqueue")
+	defer span.End()
+
+	return enqueueJob(ctx, span, executor(ctx, r.conn), job)
+}
+
+func (r *jobsRepository) Claim(
+	ctx context.Context,
+	limit int,
+	until time.Time,
+) ([]*domain.Job, error) {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.Claim", "SqlJobsClaim")
+	defer span.End()
+
+	var rows []jobRow
+
+	err := r.conn.SelectContext(ctx, &rows, queries.SqlJobsClaim, uuid.New(), until, r.now(), limit)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tracing.Error(span, err)
+		return nil, domain.ErrJobsRun
+	}
+
+	jobs := make([]*domain.Job, 0, len(rows))
+	for i := range rows {
+		jobs = append(jobs, rows[i].job())
+	}
+
+	return jobs, nil
+}
+
+func (r *jobsRepository) Record(
+	ctx context.Context,
+	job *domain.Job,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.Record", "SqlJobsRecord")
+	defer span.End()
+
+	if _, err := r.conn.ExecContext(
+		ctx,
+		queries.SqlJobsRecord,
+		job.Status,
+		job.Attempts,
+		job.RunAt,
+		job.LastError,
+		job.FinishedAt,
+		r.now(),
+		job.UUID,
+		job.LockID,
+	); err != nil {
+		tracing.Error(span, err)
+		return domain.ErrJobsRun
+	}
+
+	return nil
+}
+
+func (r *jobsRepository) Schedule(
+	ctx context.Context,
+	name string,
+	due time.Time,
+	next time.Time,
+	job *domain.Job,
+) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.Schedule", "SqlJobsScheduleAdvance")
+	defer span.End()
+
+	enqueued := false
+
+	err := inTx(ctx, r.conn, domain.ErrJobsEnqueue, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, queries.SqlJobsScheduleAdd, name, due); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrJobsEnqueue
+		}
+
+		result, err := tx.ExecContext(ctx, queries.SqlJobsScheduleAdvance, next, name, due)
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrJobsEnqueue
+		}
+
+		advanced, err := result.RowsAffected()
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrJobsEnqueue
+		}
+
+		// another runner has enqueued this run
+		if advanced == 0 {
+			return nil
+		}
+
+		enqueued, err = enqueueJob(ctx, span, tx, job)
+		return err
+	})
+
+	return enqueued, err
+}
+
+func (r *jobsRepository) Retry(
+	ctx context.Context,
+	uuid uuid.UUID,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.Retry", "SqlJobsRetry")
+	defer span.End()
+
+	result, err := r.conn.ExecContext(ctx, queries.SqlJobsRetry, r.now(), uuid)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return domain.ErrJobsDuplicate
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrJobsRetry
+	}
+
+	return r.transitioned(ctx, span, result, uuid, domain.ErrJobsRetry)
+}
+
+func (r *jobsRepository) Cancel(
+	ctx context.Context,
+	uuid uuid.UUID,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.Cancel", "SqlJobsCancel")
+	defer span.End()
+
+	result, err := r.conn.ExecContext(ctx, queries.SqlJobsCancel, r.now(), uuid)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrJobsCancel
+	}
+
+	return r.transitioned(ctx, span, result, uuid, domain.ErrJobsCancel)
+}
+
+func (r *jobsRepository) Purge(
+	ctx context.Context,
+	before time.Time,
+) (int64, error) {
+	ctx, span := tracing.StartQuery(ctx, "jobsRepository.Purge", "SqlJobsPurge")
+	defer span.End()
+
+	result, err := r.conn.ExecContext(ctx, queries.SqlJobsPurge, before)
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrJobsPurge
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrJobsPurge
+	}
+
+	return purged, nil
+}
+
+// transitioned tells apart, when a change of status matched no row,
+// a missing job from a job whose status does not allow the change.
+func (r *jobsRepository) transitioned(
+	ctx context.Context,
+	span trace.Span,
+	result sql.Result,
+	uuid uuid.UUID,
+	fallback error,
+) error {
+	err := affected(span, result, fallback)
+	if !errors.Is(err, domain.ErrResourceNotFound) {
+		return err
+	}
+
+	if _, err := r.FindByID(ctx, uuid); err != nil {
+		return err
+	}
+
+	return domain.ErrJobsState
+}
+
+// enqueueJob inserts job with ext, reporting false when
+// another job of its unique key is pending or running.
+func enqueueJob(ctx context.Context, span trace.Span, ext sqlx.ExtContext, job *domain.Job) (bool, error) {
+	uniqueKey := sql.NullString{String: job.UniqueKey, Valid: job.UniqueKey != ""}
+
+	result, err := ext.ExecContext(
+		ctx,
+		queries.SqlJobsEnqueue,
+		job.UUID,
+		job.Kind,
+		[]byte(job.Payload),
+		uniqueKey,
+		job.MaxAttempts,
+		job.RunAt,
+		job.CreatedAt,
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return false, domain.ErrJobsEnqueue
+	}
+
+	enqueued, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return false, domain.ErrJobsEnqueue
+	}
+
+	return enqueued == 1, nil
+}
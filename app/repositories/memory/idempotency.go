@@ -0,0 +1,89 @@
+// Package memory keeps the stores of a single replica in memory,
+// in place of their Postgres repositories.
+package memory
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"sync"
+	"time"
+)
+
+// idempotencySweepInterval is the least time between
+// two removals of every expired key from the memory.
+const idempotencySweepInterval = time.Minute
+
+type idempotencyMemory struct {
+	mu        sync.Mutex
+	records   map[[2]string]domain.IdempotencyRecord
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewIdempotencyStore creates an idempotency store kept in memory.
+// The keys are not shared between replicas and are lost on restart,
+// it suits a single replica and the tests.
+func NewIdempotencyStore() domain.IdempotencyStore {
+	return &idempotencyMemory{
+		records: make(map[[2]string]domain.IdempotencyRecord),
+		now:     time.Now,
+	}
+}
+
+func (m *idempotencyMemory) Reserve(
+	_ context.Context,
+	record *domain.IdempotencyRecord,
+) (*domain.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	if now.Sub(m.lastSweep) >= idempotencySweepInterval {
+		for id, existing := range m.records {
+			if !existing.ExpiresAt.After(now) {
+				delete(m.records, id)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	id := [2]string{record.Scope, record.Key}
+
+	if existing, ok := m.records[id]; ok && existing.ExpiresAt.After(now) {
+		return &existing, nil
+	}
+
+	m.records[id] = *record
+
+	return nil, nil
+}
+
+func (m *idempotencyMemory) Complete(_ context.Context, record *domain.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := [2]string{record.Scope, record.Key}
+
+	if existing, ok := m.records[id]; ok {
+		existing.Status = record.Status
+		existing.Header = record.Header
+		existing.Body = record.Body
+		m.records[id] = existing
+	}
+
+	return nil
+}
+
+func (m *idempotencyMemory) Release(_ context.Context, scope, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := [2]string{scope, key}
+
+	if existing, ok := m.records[id]; ok && !existing.Completed() {
+		delete(m.records, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// outboxReconnectMin and outboxReconnectMax bound the wait
+	// before the listener reconnects to the database.
+	outboxReconnectMin = time.Second
+	outboxReconnectMax = time.Minute
+
+	// outboxCatchUpMargin covers the clocks of the replicas setting
+	// the publication times, the listener reads back the events
+	// published that long before the last one it handed.
+	outboxCatchUpMargin = 5 * time.Second
+)
+
+type outboxListener struct {
+	conn *sqlx.DB
+	dsn  string
+	now  func() time.Time
+}
+
+// NewOutboxListener creates a listener of the events published from
+// the outbox. It holds its own connection to dsn, the events are read
+// with conn.
+func NewOutboxListener(conn *sqlx.DB, dsn string) domain.OutboxListener {
+	return &outboxListener{conn: conn, dsn: dsn, now: time.Now}
+}
+
+func (l *outboxListener) Listen(ctx context.Context, fn func(ctx context.Context, event *domain.Event)) error {
+	listener := pq.NewListener(l.dsn, outboxReconnectMin, outboxReconnectMax, nil)
+	defer listener.Close()
+
+	if err := listener.Listen(queries.OutboxChannel); err != nil {
+		tracing.Error(trace.SpanFromContext(ctx), err)
+		return domain.ErrOutboxListen
+	}
+
+	// since is the publication time of the last event handed, the
+	// events are read back from it when some may have been missed
+	since, missed := l.now(), false
+
+	for {
+		var notification *pq.Notification
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification = <-listener.Notify:
+		}
+
+		ids, reconnected := drain(notification, listener.Notify)
+		missed = missed || reconnected
+
+		var (
+			rows []*outboxRow
+			err  error
+		)
+
+		if missed {
+			rows, err = l.publishedSince(ctx, since.Add(-outboxCatchUpMargin))
+		} else {
+			rows, err = l.published(ctx, ids)
+		}
+
+		// the events are read back with the next notification
+		if err != nil {
+			missed = true
+			continue
+		}
+
+		missed = false
+
+		for _, row := range rows {
+			if row.PublishedAt != nil && row.PublishedAt.After(since) {
+				since = *row.PublishedAt
+			}
+
+			fn(ctx, row.event())
+		}
+	}
+}
+
+// drain returns the ids notified by first and by the notifications
+// waiting after it, and whether one of them tells that the connection
+// was lost and is back.
+func drain(first *pq.Notification, notify <-chan *pq.Notification) (ids []int64, reconnected bool) {
+	for notification := first; ; {
+		if notification == nil {
+			reconnected = true
+		} else if id, err := strconv.ParseInt(notification.Extra, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+
+		select {
+		case notification = <-notify:
+		default:
+			return ids, reconnected
+		}
+	}
+}
+
+// published reads the rows of ids, in the order of ids.
+func (l *outboxListener) published(ctx context.Context, ids []int64) ([]*outboxRow, error) {
+	ctx, span := tracing.StartQuery(ctx, "outboxListener.published", "SqlOutboxFindPublished")
+	defer span.End()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var rows []*outboxRow
+
+	if err := l.conn.SelectContext(ctx, &rows, queries.SqlOutboxFindPublished, pq.Array(ids)); err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrOutboxListen
+	}
+
+	byID := make(map[int64]*outboxRow, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+
+	ordered := make([]*outboxRow, 0, len(rows))
+	for _, id := range ids {
+		if row, ok := byID[id]; ok {
+			ordered = append(ordered, row)
+		}
+	}
+
+	return ordered, nil
+}
+
+// publishedSince reads the rows published from since on.
+func (l *outboxListener) publishedSince(ctx context.Context, since time.Time) ([]*outboxRow, error) {
+	ctx, span := tracing.StartQuery(ctx, "outboxListener.publishedSince", "SqlOutboxPublishedSince")
+	defer span.End()
+
+	var rows []*outboxRow
+
+	if err := l.conn.SelectContext(ctx, &rows, queries.SqlOutboxPublishedSince, since); err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrOutboxListen
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"hexagony/app/domain"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type outboxRepository struct {
+	conn *sqlx.DB
+	now  func() time.Time
+}
+
+func NewOutboxRepository(conn *sqlx.DB) domain.Outbox {
+	return &outboxRepository{conn: conn, now: time.Now}
+}
+
+// outboxRow is a claimed or a published row of outbox.
+type outboxRow struct {
+	ID            int64      `db:"id"`
+	EventID       uuid.UUID  `db:"event_id"`
+	EventType     string     `db:"event_type"`
+	AggregateType string     `db:"aggregate_type"`
+	AggregateID   uuid.UUID  `db:"aggregate_id"`
+	ActorUUID     *uuid.UUID `db:"actor_uuid"`
+	Payload       []byte     `db:"payload"`
+	OccurredAt    time.Time  `db:"occurred_at"`
+	Attempts      int        `db:"attempts"`
+	PublishedAt   *time.Time `db:"published_at"`
+}
+
+// event returns the event stored in the row.
+func (row *outboxRow) event() *domain.Event {
+	return &domain.Event{
+		ID:            row.EventID,
+		Sequence:      row.ID,
+		Type:          row.EventType,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		ActorUUID:     row.ActorUUID,
+		Payload:       row.Payload,
+		OccurredAt:    row.OccurredAt,
+	}
+}
+
+func (r *outboxRepository) Within(
+	ctx context.Context,
+	fn func(ctx context.Context) ([]*domain.Event, error),
+) error {
+	ctx, span := tracing.StartQuery(ctx, "outboxRepository.Within", "SqlOutboxAdd")
+	defer span.End()
+
+	return inTx(ctx, r.conn, domain.ErrOutboxAdd, func(tx *sqlx.Tx) error {
+		events, err := fn(withTx(ctx, tx))
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		var (
+			ids            = make([]string, len(events))
+			types          = make([]string, len(events))
+			aggregateTypes = make([]string, len(events))
+			aggregateIDs   = make([]string, len(events))
+			actors         = make([]sql.NullString, len(events))
+			payloads       = make([]string, len(events))
+			occurredAt     = make([]string, len(events))
+		)
+
+		for i, event := range events {
+			ids[i] = event.ID.String()
+			types[i] = event.Type
+			aggregateTypes[i] = event.AggregateType
+			aggregateIDs[i] = event.AggregateID.String()
+			payloads[i] = string(event.Payload)
+			occurredAt[i] = event.OccurredAt.Format(time.RFC3339Nano)
+
+			if event.ActorUUID != nil {
+				actors[i] = sql.NullString{String: event.ActorUUID.String(), Valid: true}
+			}
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			queries.SqlOutboxAdd,
+			pq.Array(ids),
+			pq.Array(types),
+			pq.Array(aggregateTypes),
+			pq.Array(aggregateIDs),
+			pq.Array(actors),
+			pq.Array(payloads),
+			pq.Array(occurredAt),
+		); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrOutboxAdd
+		}
+
+		return nil
+	})
+}
+
+func (r *outboxRepository) Dispatch(
+	ctx context.Context,
+	limit int,
+	backoff func(attempts int) time.Duration,
+	fn func(ctx context.Context, event *domain.Event) error,
+) (int, error) {
+	ctx, span := tracing.StartQuery(ctx, "outboxRepository.Dispatch", "SqlOutboxClaim")
+	defer span.End()
+
+	var claimed int
+
+	// the claimed rows stay locked until their outcome is written,
+	// the other replicas skip them and the events following them
+	err := inTx(ctx, r.conn, domain.ErrOutboxDispatch, func(tx *sqlx.Tx) error {
+		var rows []*outboxRow
+
+		if err := tx.SelectContext(ctx, &rows, queries.SqlOutboxClaim, r.now(), limit); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrOutboxDispatch
+		}
+
+		claimed = len(rows)
+
+		for _, row := range rows {
+			var err error
+			if failure := fn(ctx, row.event()); failure == nil {
+				_, err = tx.ExecContext(ctx, queries.SqlOutboxPublished, row.ID, r.now())
+			} else {
+				retryAt := r.now().Add(backoff(row.Attempts + 1))
+				_, err = tx.ExecContext(ctx, queries.SqlOutboxFailed, row.ID, retryAt, failure.Error())
+			}
+
+			if err != nil {
+				tracing.Error(span, err)
+				return domain.ErrOutboxDispatch
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return claimed, nil
+}
+
+func (r *outboxRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.StartQuery(ctx, "outboxRepository.Purge", "SqlOutboxPurge")
+	defer span.End()
+
+	result, err := r.conn.ExecContext(ctx, queries.SqlOutboxPurge, before)
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrOutboxPurge
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrOutboxPurge
+	}
+
+	return purged, nil
+}
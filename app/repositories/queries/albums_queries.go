@@ -1,21 +1,81 @@
 package queries
 
 const (
-	SqlAlbumsFindAll = "SELECT * FROM albums ORDER BY updated_at DESC LIMIT 10"
+	SqlAlbumsFindAll = "SELECT * FROM albums WHERE deleted_at IS NULL ORDER BY updated_at DESC LIMIT 10"
 
-	SqlAlbumsFindByID = "SELECT * FROM albums WHERE uuid=$1"
+	SqlAlbumsFindByID = "SELECT * FROM albums WHERE uuid=$1 AND deleted_at IS NULL"
+
+	// the album read by a write is locked until the write commits
+	SqlAlbumsFindByIDForUpdate = SqlAlbumsFindByID + " FOR UPDATE"
+
+	SqlAlbumsFindByIDs = "SELECT * FROM albums WHERE uuid = ANY($1) AND deleted_at IS NULL"
 
 	SqlAlbumsAdd = `
 	INSERT INTO 
-	albums (uuid, name, length, created_at, updated_at) 
-	VALUES ($1, $2, $3, $4, $5)
+	albums (uuid, name, length, barcode, created_at, updated_at) 
+	VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	// bulk inserts, the rows are streamed by the driver
+	SqlAlbumsCopy = "COPY albums (uuid, name, length, barcode, created_at, updated_at) FROM STDIN"
+
+	SqlAlbumsExport = "SELECT * FROM albums WHERE deleted_at IS NULL ORDER BY created_at, uuid"
+
+	SqlAlbumsFindByBarcode = "SELECT uuid FROM albums WHERE barcode=$1 AND deleted_at IS NULL LIMIT 2"
+
+	// the albums in the trash are left as they are, returning no row
+	SqlAlbumsUpsert = `
+	INSERT INTO 
+	albums (uuid, name, length, barcode, created_at, updated_at) 
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (uuid) DO UPDATE
+	SET name=EXCLUDED.name, length=EXCLUDED.length, barcode=EXCLUDED.barcode,
+	updated_at=EXCLUDED.updated_at, version=albums.version+1
+	WHERE albums.deleted_at IS NULL AND (albums.version=$7 OR ($7=0 AND NOT $8))
+	RETURNING version
 	`
 
 	SqlAlbumsUpdate = `
 	UPDATE albums 
-	SET name=$1, length=$2, updated_at=$3
-	WHERE uuid=$4
+	SET name=$1, length=$2, barcode=$3, updated_at=$4, version=version+1
+	WHERE uuid=$5 AND deleted_at IS NULL AND ($6=0 OR version=$6)
+	RETURNING version
+	`
+
+	SqlAlbumsDelete = `
+	UPDATE albums
+	SET deleted_at=$1, version=version+1
+	WHERE uuid=$2 AND deleted_at IS NULL AND ($3=0 OR version=$3)
+	RETURNING version
+	`
+
+	SqlAlbumsVersion = "SELECT version FROM albums WHERE uuid=$1 AND deleted_at IS NULL"
+
+	SqlAlbumsFindTrash = "SELECT * FROM albums WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+
+	SqlAlbumsRestore = `
+	UPDATE albums
+	SET deleted_at=NULL, updated_at=$1, version=version+1
+	WHERE uuid=$2 AND deleted_at IS NOT NULL
+	`
+
+	SqlAlbumsPurge = "DELETE FROM albums WHERE deleted_at < $1"
+
+	// the snapshot uses the JSON names of domain.Albums
+	SqlAlbumsAddRevision = `
+	INSERT INTO 
+	album_revisions (album_uuid, revision, action, snapshot, actor_uuid, actor_email, created_at)
+	SELECT uuid, version, $2, jsonb_build_object(
+		'id', uuid, 'name', name, 'length', length, 'barcode', barcode, 'version', version,
+		'created_at', created_at, 'updated_at', updated_at, 'deleted_at', deleted_at
+	), $3, $4, $5
+	FROM albums WHERE uuid = ANY($1)
+	`
+
+	SqlAlbumsFindRevisions = `
+	SELECT album_uuid, revision, action, snapshot, actor_uuid, actor_email, created_at
+	FROM album_revisions WHERE album_uuid=$1 ORDER BY revision
 	`
 
-	SqlAlbumsDelete = "DELETE FROM albums WHERE uuid=$1"
+	SqlAlbumsFindRevision = "SELECT snapshot FROM album_revisions WHERE album_uuid=$1 AND revision=$2"
 )
@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// SqlAuditLock serializes the writers of the chain until the end of the transaction.
+	SqlAuditLock = "SELECT pg_advisory_xact_lock(hashtext('audit_log'))"
+
+	SqlAuditLastHash = "SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1"
+
+	SqlAuditAdd = `
+	INSERT INTO 
+	audit_log (
+		actor_uuid, actor_email, action, outcome, resource_type, resource_id,
+		ip, request_id, changes, created_at, prev_hash, hash
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING id
+	`
+
+	sqlAuditColumns = `
+	SELECT id, actor_uuid, actor_email, action, outcome, resource_type, resource_id,
+	ip, request_id, changes, created_at, prev_hash, hash
+	FROM audit_log
+	`
+
+	SqlAuditVerify = sqlAuditColumns + "ORDER BY id"
+)
+
+// SqlAuditFind builds the listing of the audit entries matching every
+// condition, newest first. The conditions bind their values from $1,
+// the limit is bound after them.
+func SqlAuditFind(conditions []string) string {
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ") + " "
+	}
+
+	return fmt.Sprintf("%s%sORDER BY id DESC LIMIT $%d", sqlAuditColumns, where, len(conditions)+1)
+}
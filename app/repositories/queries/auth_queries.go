@@ -1,3 +1,3 @@
 package queries
 
-const SqlAuthGetUser = "SELECT * from users WHERE email = $1"
+const SqlAuthGetUser = "SELECT * from users WHERE email = $1 AND deleted_at IS NULL"
@@ -0,0 +1,26 @@
+package queries
+
+const (
+	SqlIdempotencyExpire = "DELETE FROM idempotency_keys WHERE expires_at <= $1"
+
+	SqlIdempotencyReserve = `
+	INSERT INTO 
+	idempotency_keys (scope, key, fingerprint, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (scope, key) DO NOTHING
+	`
+
+	SqlIdempotencyFind = `
+	SELECT scope, key, fingerprint, status, header, body, created_at, expires_at
+	FROM idempotency_keys
+	WHERE scope = $1 AND key = $2
+	`
+
+	SqlIdempotencyComplete = `
+	UPDATE idempotency_keys 
+	SET status = $3, header = $4, body = $5
+	WHERE scope = $1 AND key = $2
+	`
+
+	SqlIdempotencyRelease = "DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status = 0"
+)
@@ -0,0 +1,9 @@
+package queries
+
+// The instance locks are session advisory locks, keyed by the hash of
+// their name and released with the session holding them.
+const (
+	SqlInstanceLockAcquire = "SELECT pg_try_advisory_lock(hashtext($1))"
+
+	SqlInstanceLockRelease = "SELECT pg_advisory_unlock(hashtext($1))"
+)
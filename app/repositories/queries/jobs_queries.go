@@ -0,0 +1,89 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	sqlJobsColumns = `
+	SELECT uuid, kind, payload, unique_key, status, attempts, max_attempts, run_at,
+	lock_id, last_error, created_at, updated_at, finished_at
+	FROM jobs
+	`
+
+	SqlJobsFindByID = sqlJobsColumns + "WHERE uuid = $1"
+
+	// SqlJobsEnqueue skips the job when another job of its
+	// unique key is pending or running.
+	SqlJobsEnqueue = `
+	INSERT INTO
+	jobs (uuid, kind, payload, unique_key, status, max_attempts, run_at, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, 'pending', $5, $6, $7, $7)
+	ON CONFLICT (unique_key) WHERE status IN ('pending', 'running') DO NOTHING
+	`
+
+	// SqlJobsClaim marks the due jobs, and the running jobs whose claim
+	// has expired, as running under the claim $1 until $2.
+	SqlJobsClaim = `
+	WITH due AS (
+		SELECT uuid FROM jobs
+		WHERE (status = 'pending' AND run_at <= $3) OR (status = 'running' AND locked_until <= $3)
+		ORDER BY run_at
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE jobs j
+	SET status = 'running', attempts = j.attempts + 1, lock_id = $1, locked_until = $2, updated_at = $3
+	FROM due
+	WHERE j.uuid = due.uuid
+	RETURNING j.uuid, j.kind, j.payload, j.unique_key, j.status, j.attempts, j.max_attempts, j.run_at,
+	j.lock_id, j.last_error, j.created_at, j.updated_at, j.finished_at
+	`
+
+	// SqlJobsRecord releases the claim of the job, unless it was lost.
+	SqlJobsRecord = `
+	UPDATE jobs
+	SET status = $1, attempts = $2, run_at = $3, last_error = $4, finished_at = $5, updated_at = $6,
+	lock_id = NULL, locked_until = NULL
+	WHERE uuid = $7 AND lock_id = $8
+	`
+
+	// SqlJobsScheduleAdd starts a schedule at its first run.
+	SqlJobsScheduleAdd = `
+	INSERT INTO job_schedules (name, next_run_at) VALUES ($1, $2)
+	ON CONFLICT (name) DO NOTHING
+	`
+
+	// SqlJobsScheduleAdvance moves a due schedule to its next run,
+	// the runner that moves it enqueues its job.
+	SqlJobsScheduleAdvance = `
+	UPDATE job_schedules SET next_run_at = $1
+	WHERE name = $2 AND next_run_at <= $3
+	`
+
+	SqlJobsRetry = `
+	UPDATE jobs
+	SET status = 'pending', attempts = 0, run_at = $1, last_error = '', finished_at = NULL, updated_at = $1
+	WHERE uuid = $2 AND status IN ('failed', 'canceled')
+	`
+
+	SqlJobsCancel = `
+	UPDATE jobs
+	SET status = 'canceled', finished_at = $1, updated_at = $1, lock_id = NULL, locked_until = NULL
+	WHERE uuid = $2 AND status IN ('pending', 'running')
+	`
+
+	SqlJobsPurge = "DELETE FROM jobs WHERE finished_at < $1"
+)
+
+// SqlJobsFindAll builds the listing of the jobs matching every
+// condition, newest first. The limit is bound after the conditions.
+func SqlJobsFindAll(conditions []string) string {
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ") + " "
+	}
+
+	return fmt.Sprintf("%s%sORDER BY created_at DESC LIMIT $%d", sqlJobsColumns, where, len(conditions)+1)
+}
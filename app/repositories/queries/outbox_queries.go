@@ -0,0 +1,64 @@
+package queries
+
+const (
+	// OutboxChannel is the channel notified of the published events.
+	OutboxChannel = "outbox_published"
+
+	// SqlOutboxAdd inserts the events given as arrays of their fields.
+	SqlOutboxAdd = `
+	INSERT INTO 
+	outbox (event_id, event_type, aggregate_type, aggregate_id, actor_uuid, payload, occurred_at)
+	SELECT * FROM unnest(
+		$1::varchar[], $2::varchar[], $3::varchar[], $4::varchar[], $5::varchar[], $6::jsonb[], $7::timestamptz[]
+	)
+	`
+
+	// SqlOutboxClaim locks the oldest pending event of each aggregate, the
+	// next events of an aggregate are claimed once it has been published.
+	SqlOutboxClaim = `
+	SELECT id, event_id, event_type, aggregate_type, aggregate_id, actor_uuid, payload, occurred_at, attempts
+	FROM outbox o
+	WHERE published_at IS NULL AND next_attempt_at <= $1
+	AND NOT EXISTS (
+		SELECT 1 FROM outbox p
+		WHERE p.aggregate_type = o.aggregate_type AND p.aggregate_id = o.aggregate_id
+		AND p.published_at IS NULL AND p.id < o.id
+	)
+	ORDER BY id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+	`
+
+	// SqlOutboxPublished marks an event published and notifies its id to
+	// the listeners of every replica once the transaction is committed.
+	SqlOutboxPublished = `
+	WITH published AS (
+		UPDATE outbox SET published_at = $2, attempts = attempts + 1 WHERE id = $1 RETURNING id
+	)
+	SELECT pg_notify('` + OutboxChannel + `', id::text) FROM published
+	`
+
+	// SqlOutboxFindPublished reads the published events notified by their id.
+	SqlOutboxFindPublished = `
+	SELECT id, event_id, event_type, aggregate_type, aggregate_id, actor_uuid, payload, occurred_at, attempts, published_at
+	FROM outbox
+	WHERE id = ANY($1) AND published_at IS NOT NULL
+	`
+
+	// SqlOutboxPublishedSince reads the events published from a time on,
+	// for a listener catching up after it reconnected.
+	SqlOutboxPublishedSince = `
+	SELECT id, event_id, event_type, aggregate_type, aggregate_id, actor_uuid, payload, occurred_at, attempts, published_at
+	FROM outbox
+	WHERE published_at >= $1
+	ORDER BY published_at, id
+	`
+
+	SqlOutboxFailed = `
+	UPDATE outbox 
+	SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+	WHERE id = $1
+	`
+
+	SqlOutboxPurge = "DELETE FROM outbox WHERE published_at < $1"
+)
@@ -0,0 +1,25 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SqlPatch builds an UPDATE of the given columns only, increasing the
+// row version. The deleted rows are never updated. The values are bound in the order of the columns,
+// followed by the uuid and the expected version, zero matching any.
+func SqlPatch(table string, columns []string) string {
+	set := make([]string, len(columns))
+	for i, column := range columns {
+		set[i] = fmt.Sprintf("%s=$%d", column, i+1)
+	}
+
+	return fmt.Sprintf(
+		"UPDATE %s SET %s, version=version+1 WHERE uuid=$%d AND deleted_at IS NULL AND ($%d=0 OR version=$%d) RETURNING version",
+		table,
+		strings.Join(set, ", "),
+		len(columns)+1,
+		len(columns)+2,
+		len(columns)+2,
+	)
+}
@@ -0,0 +1,17 @@
+package queries
+
+// The TATs are stored as Unix nanoseconds, compared for equality.
+const (
+	SqlRateLimitGet = "SELECT tat FROM rate_limits WHERE key = $1"
+
+	SqlRateLimitAdd = `
+	INSERT INTO 
+	rate_limits (key, tat)
+	VALUES ($1, $2)
+	ON CONFLICT (key) DO NOTHING
+	`
+
+	SqlRateLimitSwap = "UPDATE rate_limits SET tat = $3 WHERE key = $1 AND tat = $2"
+
+	SqlRateLimitExpire = "DELETE FROM rate_limits WHERE tat < $1"
+)
@@ -0,0 +1,9 @@
+package queries
+
+const (
+	SqlSavepoint = "SAVEPOINT bulk"
+
+	SqlRollbackToSavepoint = "ROLLBACK TO SAVEPOINT bulk"
+
+	SqlReleaseSavepoint = "RELEASE SAVEPOINT bulk"
+)
@@ -1,9 +1,23 @@
 package queries
 
 const (
-	SqlUsersFindAll = "SELECT uuid,name,email,created_at,updated_at FROM users ORDER BY updated_at DESC LIMIT 10"
+	SqlUsersFindAll = `
+	SELECT uuid,name,email,version,created_at,updated_at,deleted_at 
+	FROM users WHERE deleted_at IS NULL ORDER BY updated_at DESC LIMIT 10
+	`
+
+	SqlUsersFindByID = `
+	SELECT uuid,name,email,version,created_at,updated_at,deleted_at 
+	FROM users WHERE uuid=$1 AND deleted_at IS NULL
+	`
 
-	SqlUsersFindByID = "SELECT uuid,name,email,created_at,updated_at FROM users WHERE uuid=$1"
+	// the user read by a write is locked until the write commits
+	SqlUsersFindByIDForUpdate = SqlUsersFindByID + "FOR UPDATE"
+
+	SqlUsersFindByIDs = `
+	SELECT uuid,name,email,version,created_at,updated_at,deleted_at 
+	FROM users WHERE uuid = ANY($1) AND deleted_at IS NULL
+	`
 
 	SqlUsersAdd = `
 	INSERT INTO 
@@ -13,11 +27,32 @@ const (
 
 	SqlUsersUpdate = `
 	UPDATE users 
-	SET name=$1, email=$2, updated_at=$3
-	WHERE uuid=$4
+	SET name=$1, email=$2, updated_at=$3, version=version+1
+	WHERE uuid=$4 AND deleted_at IS NULL AND ($5=0 OR version=$5)
+	RETURNING version
+	`
+
+	SqlUsersDelete = `
+	UPDATE users
+	SET deleted_at=$1, version=version+1
+	WHERE uuid=$2 AND deleted_at IS NULL AND ($3=0 OR version=$3)
+	`
+
+	SqlUsersVersion = "SELECT version FROM users WHERE uuid=$1 AND deleted_at IS NULL"
+
+	SqlUsersFindTrash = `
+	SELECT uuid,name,email,version,created_at,updated_at,deleted_at 
+	FROM users WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC
+	`
+
+	SqlUsersRestore = `
+	UPDATE users
+	SET deleted_at=NULL, updated_at=$1, version=version+1
+	WHERE uuid=$2 AND deleted_at IS NOT NULL
 	`
 
-	SqlUsersDelete = "DELETE FROM users WHERE uuid=$1"
+	SqlUsersPurge = "DELETE FROM users WHERE deleted_at < $1"
 
+	// the deleted users keep their email until they are purged
 	SqlUsersCheckDuplicate = "SELECT email FROM users WHERE email=$1"
 )
@@ -0,0 +1,106 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	sqlWebhooksColumns = "SELECT uuid, url, event_types, secret, active, created_at, updated_at FROM webhooks "
+
+	SqlWebhooksFindAll = sqlWebhooksColumns + "ORDER BY created_at"
+
+	SqlWebhooksFindByID = sqlWebhooksColumns + "WHERE uuid = $1"
+
+	SqlWebhooksAdd = `
+	INSERT INTO
+	webhooks (uuid, url, event_types, secret, active, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	SqlWebhooksUpdate = `
+	UPDATE webhooks
+	SET url = $1, event_types = $2, active = $3, updated_at = $4
+	WHERE uuid = $5
+	`
+
+	SqlWebhooksDelete = "DELETE FROM webhooks WHERE uuid = $1"
+
+	// SqlWebhooksEnqueue creates a delivery of the event for every active
+	// webhook subscribed to its type, an event enqueued again is skipped.
+	SqlWebhooksEnqueue = `
+	INSERT INTO
+	webhook_deliveries (uuid, webhook_uuid, event_id, event_type, payload, next_attempt_at, created_at)
+	SELECT gen_random_uuid()::varchar, uuid, $1, $2, $3, $4, $4
+	FROM webhooks
+	WHERE active AND $2 = ANY(event_types)
+	ON CONFLICT (webhook_uuid, event_id) DO NOTHING
+	`
+
+	sqlDeliveriesColumns = `
+	SELECT d.uuid, d.webhook_uuid, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.response_status, d.last_error, d.created_at, d.delivered_at
+	`
+
+	// SqlWebhooksClaim postpones the due deliveries to $1 and returns
+	// them along with the URL and the secret of their webhook.
+	SqlWebhooksClaim = `
+	WITH due AS (
+		SELECT uuid FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= $2
+		ORDER BY next_attempt_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE webhook_deliveries d SET next_attempt_at = $1
+	FROM due, webhooks w
+	WHERE d.uuid = due.uuid AND w.uuid = d.webhook_uuid
+	RETURNING d.uuid, d.webhook_uuid, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.response_status, d.last_error, d.created_at, d.delivered_at,
+	w.url, w.secret
+	`
+
+	SqlWebhooksAttemptAdd = `
+	INSERT INTO
+	webhook_attempts (delivery_uuid, status_code, error, duration_ms, attempted_at)
+	VALUES ($1, $2, $3, $4, $5)
+	`
+
+	SqlWebhooksAttempted = `
+	UPDATE webhook_deliveries
+	SET status = $1, attempts = attempts + 1, next_attempt_at = $2, response_status = $3,
+	last_error = $4, delivered_at = $5
+	WHERE uuid = $6
+	`
+
+	SqlWebhooksFindDelivery = sqlDeliveriesColumns + `
+	FROM webhook_deliveries d
+	WHERE d.webhook_uuid = $1 AND d.uuid = $2
+	`
+
+	SqlWebhooksFindAttempts = `
+	SELECT status_code, error, duration_ms, attempted_at
+	FROM webhook_attempts
+	WHERE delivery_uuid = $1
+	ORDER BY id
+	`
+
+	// SqlWebhooksRedeliver makes a delivery due again, with all its attempts.
+	SqlWebhooksRedeliver = `
+	UPDATE webhook_deliveries
+	SET status = 'pending', attempts = 0, next_attempt_at = $1
+	WHERE webhook_uuid = $2 AND uuid = $3
+	`
+)
+
+// SqlWebhooksFindDeliveries builds the listing of the deliveries of the
+// webhook $1 matching every condition, newest first. The conditions bind
+// their values from $2, the limit is bound after them.
+func SqlWebhooksFindDeliveries(conditions []string) string {
+	where := "WHERE " + strings.Join(append([]string{"d.webhook_uuid = $1"}, conditions...), " AND ") + " "
+
+	return fmt.Sprintf(
+		"%sFROM webhook_deliveries d %sORDER BY d.created_at DESC LIMIT $%d",
+		sqlDeliveriesColumns, where, len(conditions)+2,
+	)
+}
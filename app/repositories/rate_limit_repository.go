@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/ratelimit"
+	"hexagony/libs/tracing"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// rateLimitSweepInterval is the least time between two
+// removals of every past TAT by the same replica.
+const rateLimitSweepInterval = time.Minute
+
+type rateLimitRepository struct {
+	conn *sqlx.DB
+	now  func() time.Time
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewRateLimitRepository creates a rate limit store
+// shared by every replica of the application.
+func NewRateLimitRepository(conn *sqlx.DB) ratelimit.Store {
+	return &rateLimitRepository{conn: conn, now: time.Now}
+}
+
+func (r *rateLimitRepository) Get(ctx context.Context, key string) (time.Time, error) {
+	ctx, span := tracing.StartQuery(ctx, "rateLimitRepository.Get", "SqlRateLimitGet")
+	defer span.End()
+
+	var tat int64
+
+	err := r.conn.GetContext(ctx, &tat, queries.SqlRateLimitGet, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		tracing.Error(span, err)
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, tat), nil
+}
+
+func (r *rateLimitRepository) CompareAndSwap(
+	ctx context.Context,
+	key string,
+	old, next time.Time,
+) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "rateLimitRepository.CompareAndSwap", "SqlRateLimitSwap")
+	defer span.End()
+
+	if err := r.sweep(ctx); err != nil {
+		tracing.Error(span, err)
+		return false, err
+	}
+
+	var (
+		result sql.Result
+		err    error
+	)
+
+	if old.IsZero() {
+		result, err = r.conn.ExecContext(ctx, queries.SqlRateLimitAdd, key, next.UnixNano())
+	} else {
+		result, err = r.conn.ExecContext(ctx, queries.SqlRateLimitSwap, key, old.UnixNano(), next.UnixNano())
+	}
+	if err != nil {
+		tracing.Error(span, err)
+		return false, err
+	}
+
+	swapped, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return false, err
+	}
+
+	return swapped == 1, nil
+}
+
+// sweep removes the past TATs, at most once per interval.
+func (r *rateLimitRepository) sweep(ctx context.Context) error {
+	r.mu.Lock()
+	now := r.now()
+	due := now.Sub(r.lastSweep) >= rateLimitSweepInterval
+	if due {
+		r.lastSweep = now
+	}
+	r.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+
+	_, err := r.conn.ExecContext(ctx, queries.SqlRateLimitExpire, now.UnixNano())
+	return err
+}
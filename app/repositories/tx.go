@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"hexagony/libs/tracing"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type txKey struct{}
+
+// withTx returns a copy of ctx carrying tx, the repositories
+// called with it join tx instead of using their connection.
+func withTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// txFromContext returns the transaction carried by ctx, if any.
+func txFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// executor returns the transaction carried by ctx, or else conn.
+func executor(ctx context.Context, conn *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+
+	return conn
+}
+
+// inTx runs fn in a transaction, committed only if fn succeeds. The
+// errors of the transaction itself are recorded on the current span
+// and reported as fallback, the errors of fn are returned as is.
+//
+// When ctx already carries a transaction fn runs in it, the caller
+// owning the transaction commits or rolls it back.
+func inTx(
+	ctx context.Context,
+	conn *sqlx.DB,
+	fallback error,
+	fn func(tx *sqlx.Tx) error,
+) error {
+	if tx, ok := txFromContext(ctx); ok {
+		return fn(tx)
+	}
+
+	span := trace.SpanFromContext(ctx)
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		tracing.Error(span, err)
+		return fallback
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tracing.Error(span, err)
+		return fallback
+	}
+
+	return nil
+}
+
+// savepoint runs the savepoint statements in tx, their errors are
+// recorded on the current span and reported as fallback.
+func savepoint(ctx context.Context, tx *sqlx.Tx, fallback error, statements ...string) error {
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			tracing.Error(trace.SpanFromContext(ctx), err)
+			return fallback
+		}
+	}
+
+	return nil
+}
@@ -6,9 +6,12 @@ import (
 	"errors"
 	"hexagony/app/domain"
 	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type usersRepository struct {
@@ -22,6 +25,9 @@ func NewUsersRepository(conn *sqlx.DB) domain.UsersRepository {
 func (r *usersRepository) FindAll(
 	ctx context.Context,
 ) ([]*domain.UsersList, error) {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.FindAll", "SqlUsersFindAll")
+	defer span.End()
+
 	var users []*domain.UsersList
 
 	err := r.conn.SelectContext(
@@ -35,6 +41,7 @@ func (r *usersRepository) FindAll(
 	}
 
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, domain.ErrUsersFindAll
 	}
 
@@ -45,12 +52,23 @@ func (r *usersRepository) FindByID(
 	ctx context.Context,
 	uuid uuid.UUID,
 ) (*domain.UsersList, error) {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.FindByID", "SqlUsersFindByID")
+	defer span.End()
+
 	var user domain.UsersList
 
-	err := r.conn.GetContext(
+	// in a transaction the row is locked, the write reading it
+	// then changes the version it read
+	query := queries.SqlUsersFindByID
+	if _, ok := txFromContext(ctx); ok {
+		query = queries.SqlUsersFindByIDForUpdate
+	}
+
+	err := sqlx.GetContext(
 		ctx,
+		executor(ctx, r.conn),
 		&user,
-		queries.SqlUsersFindByID,
+		query,
 		uuid,
 	)
 	noRows := errors.Is(err, sql.ErrNoRows)
@@ -59,18 +77,47 @@ func (r *usersRepository) FindByID(
 	}
 
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, domain.ErrUsersFindByID
 	}
 
 	return &user, nil
 }
 
+func (r *usersRepository) FindByIDs(
+	ctx context.Context,
+	uuids []uuid.UUID,
+) ([]*domain.UsersList, error) {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.FindByIDs", "SqlUsersFindByIDs")
+	defer span.End()
+
+	var users []*domain.UsersList
+
+	err := sqlx.SelectContext(
+		ctx,
+		executor(ctx, r.conn),
+		&users,
+		queries.SqlUsersFindByIDs,
+		pq.Array(uuidStrings(uuids)),
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrUsersFindByID
+	}
+
+	return users, nil
+}
+
 func (r *usersRepository) Add(
 	ctx context.Context,
 	user *domain.Users,
 ) error {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.Add", "SqlUsersAdd")
+	defer span.End()
+
 	exists, err := r.checkDuplicate(ctx, user.Email)
 	if err != nil {
+		tracing.Error(span, err)
 		return err
 	}
 
@@ -78,7 +125,7 @@ func (r *usersRepository) Add(
 		return domain.ErrUsersDuplicateEmail
 	}
 
-	if _, err := r.conn.ExecContext(
+	if _, err := executor(ctx, r.conn).ExecContext(
 		ctx,
 		queries.SqlUsersAdd,
 		user.UUID,
@@ -88,6 +135,7 @@ func (r *usersRepository) Add(
 		user.CreatedAt,
 		user.UpdatedAt,
 	); err != nil {
+		tracing.Error(span, err)
 		return domain.ErrUsersAdd
 	}
 
@@ -99,25 +147,84 @@ func (r *usersRepository) Update(
 	uuid uuid.UUID,
 	user *domain.Users,
 ) error {
-	result, err := r.conn.ExecContext(
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.Update", "SqlUsersUpdate")
+	defer span.End()
+
+	db := executor(ctx, r.conn)
+
+	err := db.QueryRowxContext(
 		ctx,
 		queries.SqlUsersUpdate,
 		user.Name,
 		user.Email,
 		user.UpdatedAt,
 		uuid,
-	)
-	if err != nil {
-		return domain.ErrUsersUpdate
+		user.Version,
+	).Scan(&user.Version)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingRow(ctx, db, queries.SqlUsersVersion, uuid, domain.ErrUsersUpdate)
 	}
 
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
+		tracing.Error(span, err)
 		return domain.ErrUsersUpdate
 	}
 
-	if rowsAffected == 0 {
-		return domain.ErrResourceNotFound
+	return nil
+}
+
+func (r *usersRepository) Patch(
+	ctx context.Context,
+	uuid uuid.UUID,
+	patch *domain.UsersPatch,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.Patch", "SqlPatch")
+	defer span.End()
+
+	db := executor(ctx, r.conn)
+
+	if patch.Empty() {
+		return nil
+	}
+
+	var (
+		columns []string
+		args    []interface{}
+	)
+
+	if patch.Name != nil {
+		columns, args = append(columns, "name"), append(args, *patch.Name)
+	}
+	if patch.Email != nil {
+		exists, err := r.checkDuplicate(ctx, *patch.Email)
+		if err != nil {
+			tracing.Error(span, err)
+			return domain.ErrUsersUpdate
+		}
+
+		if exists {
+			return domain.ErrUsersDuplicateEmail
+		}
+
+		columns, args = append(columns, "email"), append(args, *patch.Email)
+	}
+
+	columns, args = append(columns, "updated_at"), append(args, patch.UpdatedAt, uuid, patch.Version)
+
+	err := db.QueryRowxContext(
+		ctx,
+		queries.SqlPatch("users", columns),
+		args...,
+	).Scan(&patch.Version)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingRow(ctx, db, queries.SqlUsersVersion, uuid, domain.ErrUsersUpdate)
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrUsersUpdate
 	}
 
 	return nil
@@ -126,39 +233,53 @@ func (r *usersRepository) Update(
 func (r *usersRepository) Delete(
 	ctx context.Context,
 	uuid uuid.UUID,
+	version int,
 ) error {
-	result, err := r.conn.ExecContext(
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.Delete", "SqlUsersDelete")
+	defer span.End()
+
+	db := executor(ctx, r.conn)
+
+	result, err := db.ExecContext(
 		ctx,
 		queries.SqlUsersDelete,
+		time.Now(),
 		uuid,
+		version,
 	)
 	if err != nil {
+		tracing.Error(span, err)
 		return domain.ErrUsersDelete
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
+		tracing.Error(span, err)
 		return domain.ErrUsersDelete
 	}
 
 	if rowsAffected == 0 {
-		return domain.ErrResourceNotFound
+		return missingRow(ctx, db, queries.SqlUsersVersion, uuid, domain.ErrUsersDelete)
 	}
 
 	return nil
 }
 
 func (r *usersRepository) checkDuplicate(ctx context.Context, email string) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.checkDuplicate", "SqlUsersCheckDuplicate")
+	defer span.End()
+
 	var userEmail string
 	exists := false
 
-	err := r.conn.GetContext(ctx, &userEmail, queries.SqlUsersCheckDuplicate, email)
+	err := sqlx.GetContext(ctx, executor(ctx, r.conn), &userEmail, queries.SqlUsersCheckDuplicate, email)
 	noRows := errors.Is(err, sql.ErrNoRows)
 	if noRows {
 		return false, nil
 	}
 
 	if err != nil {
+		tracing.Error(span, err)
 		return false, err
 	}
 
@@ -168,3 +289,86 @@ func (r *usersRepository) checkDuplicate(ctx context.Context, email string) (boo
 
 	return exists, nil
 }
+
+func (r *usersRepository) FindTrash(
+	ctx context.Context,
+) ([]*domain.UsersList, error) {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.FindTrash", "SqlUsersFindTrash")
+	defer span.End()
+
+	var users []*domain.UsersList
+
+	err := r.conn.SelectContext(
+		ctx,
+		&users,
+		queries.SqlUsersFindTrash,
+	)
+	noRows := errors.Is(err, sql.ErrNoRows)
+	if noRows {
+		return users, nil
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrUsersFindTrash
+	}
+
+	return users, nil
+}
+
+func (r *usersRepository) Restore(
+	ctx context.Context,
+	uuid uuid.UUID,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.Restore", "SqlUsersRestore")
+	defer span.End()
+
+	result, err := executor(ctx, r.conn).ExecContext(
+		ctx,
+		queries.SqlUsersRestore,
+		time.Now(),
+		uuid,
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrUsersRestore
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrUsersRestore
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+func (r *usersRepository) Purge(
+	ctx context.Context,
+	before time.Time,
+) (int64, error) {
+	ctx, span := tracing.StartQuery(ctx, "usersRepository.Purge", "SqlUsersPurge")
+	defer span.End()
+
+	result, err := executor(ctx, r.conn).ExecContext(
+		ctx,
+		queries.SqlUsersPurge,
+		before,
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrUsersPurge
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrUsersPurge
+	}
+
+	return rowsAffected, nil
+}
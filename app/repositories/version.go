@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hexagony/app/domain"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// missingRow tells apart, once a conditional write matched no row, a
+// row that does not exist from a row whose version did not match. The
+// query selects the version by uuid, fallback is returned if it fails.
+func missingRow(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	query string,
+	uuid uuid.UUID,
+	fallback error,
+) error {
+	var version int
+
+	err := sqlx.GetContext(ctx, q, &version, query, uuid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrResourceNotFound
+	}
+
+	if err != nil {
+		return fallback
+	}
+
+	return domain.ErrPreconditionFailed
+}
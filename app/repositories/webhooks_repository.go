@@ -0,0 +1,414 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/app/repositories/queries"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type webhooksRepository struct {
+	conn *sqlx.DB
+	now  func() time.Time
+}
+
+func NewWebhooksRepository(conn *sqlx.DB) domain.WebhooksRepository {
+	return &webhooksRepository{conn: conn, now: time.Now}
+}
+
+// webhookRow is a row of webhooks.
+type webhookRow struct {
+	UUID       uuid.UUID      `db:"uuid"`
+	URL        string         `db:"url"`
+	EventTypes pq.StringArray `db:"event_types"`
+	Secret     string         `db:"secret"`
+	Active     bool           `db:"active"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"`
+}
+
+func (row *webhookRow) webhook() *domain.Webhook {
+	return &domain.Webhook{
+		UUID:       row.UUID,
+		URL:        row.URL,
+		EventTypes: row.EventTypes,
+		Secret:     row.Secret,
+		Active:     row.Active,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}
+}
+
+// deliveryRow is a row of webhook_deliveries, the URL and the
+// secret of the webhook are only read by the claims.
+type deliveryRow struct {
+	UUID           uuid.UUID  `db:"uuid"`
+	WebhookUUID    uuid.UUID  `db:"webhook_uuid"`
+	EventID        uuid.UUID  `db:"event_id"`
+	EventType      string     `db:"event_type"`
+	Payload        []byte     `db:"payload"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	NextAttemptAt  *time.Time `db:"next_attempt_at"`
+	ResponseStatus int        `db:"response_status"`
+	LastError      string     `db:"last_error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+	URL            string     `db:"url"`
+	Secret         string     `db:"secret"`
+}
+
+func (row *deliveryRow) delivery() *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		UUID:           row.UUID,
+		WebhookUUID:    row.WebhookUUID,
+		EventID:        row.EventID,
+		EventType:      row.EventType,
+		Payload:        row.Payload,
+		Status:         row.Status,
+		Attempts:       row.Attempts,
+		NextAttemptAt:  row.NextAttemptAt,
+		ResponseStatus: row.ResponseStatus,
+		LastError:      row.LastError,
+		CreatedAt:      row.CreatedAt,
+		DeliveredAt:    row.DeliveredAt,
+	}
+}
+
+func (r *webhooksRepository) FindAll(
+	ctx context.Context,
+) ([]*domain.Webhook, error) {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.FindAll", "SqlWebhooksFindAll")
+	defer span.End()
+
+	var rows []webhookRow
+
+	err := r.conn.SelectContext(ctx, &rows, queries.SqlWebhooksFindAll)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tracing.Error(span, err)
+		return nil, domain.ErrWebhooksFindAll
+	}
+
+	webhooks := make([]*domain.Webhook, 0, len(rows))
+	for i := range rows {
+		webhooks = append(webhooks, rows[i].webhook())
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhooksRepository) FindByID(
+	ctx context.Context,
+	uuid uuid.UUID,
+) (*domain.Webhook, error) {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.FindByID", "SqlWebhooksFindByID")
+	defer span.End()
+
+	var row webhookRow
+
+	err := r.conn.GetContext(ctx, &row, queries.SqlWebhooksFindByID, uuid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrResourceNotFound
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrWebhooksFindByID
+	}
+
+	return row.webhook(), nil
+}
+
+func (r *webhooksRepository) Add(
+	ctx context.Context,
+	webhook *domain.Webhook,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.Add", "SqlWebhooksAdd")
+	defer span.End()
+
+	if _, err := r.conn.ExecContext(
+		ctx,
+		queries.SqlWebhooksAdd,
+		webhook.UUID,
+		webhook.URL,
+		pq.StringArray(webhook.EventTypes),
+		webhook.Secret,
+		webhook.Active,
+		webhook.CreatedAt,
+		webhook.UpdatedAt,
+	); err != nil {
+		tracing.Error(span, err)
+		return domain.ErrWebhooksAdd
+	}
+
+	return nil
+}
+
+func (r *webhooksRepository) Update(
+	ctx context.Context,
+	uuid uuid.UUID,
+	webhook *domain.Webhook,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.Update", "SqlWebhooksUpdate")
+	defer span.End()
+
+	result, err := r.conn.ExecContext(
+		ctx,
+		queries.SqlWebhooksUpdate,
+		webhook.URL,
+		pq.StringArray(webhook.EventTypes),
+		webhook.Active,
+		webhook.UpdatedAt,
+		uuid,
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrWebhooksUpdate
+	}
+
+	return affected(span, result, domain.ErrWebhooksUpdate)
+}
+
+func (r *webhooksRepository) Delete(
+	ctx context.Context,
+	uuid uuid.UUID,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.Delete", "SqlWebhooksDelete")
+	defer span.End()
+
+	result, err := r.conn.ExecContext(ctx, queries.SqlWebhooksDelete, uuid)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrWebhooksDelete
+	}
+
+	return affected(span, result, domain.ErrWebhooksDelete)
+}
+
+func (r *webhooksRepository) Enqueue(
+	ctx context.Context,
+	event *domain.Event,
+) (int64, error) {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.Enqueue", "SqlWebhooksEnqueue")
+	defer span.End()
+
+	// the whole event is posted to the webhooks
+	payload, err := json.Marshal(event)
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrWebhooksEnqueue
+	}
+
+	result, err := r.conn.ExecContext(
+		ctx,
+		queries.SqlWebhooksEnqueue,
+		event.ID,
+		event.Type,
+		payload,
+		r.now(),
+	)
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrWebhooksEnqueue
+	}
+
+	enqueued, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, domain.ErrWebhooksEnqueue
+	}
+
+	return enqueued, nil
+}
+
+func (r *webhooksRepository) Claim(
+	ctx context.Context,
+	limit int,
+	until time.Time,
+) ([]*domain.WebhookDelivery, error) {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.Claim", "SqlWebhooksClaim")
+	defer span.End()
+
+	var rows []deliveryRow
+
+	err := r.conn.SelectContext(ctx, &rows, queries.SqlWebhooksClaim, until, r.now(), limit)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tracing.Error(span, err)
+		return nil, domain.ErrWebhooksDeliver
+	}
+
+	deliveries := make([]*domain.WebhookDelivery, 0, len(rows))
+	for i := range rows {
+		delivery := rows[i].delivery()
+		delivery.Webhook = &domain.Webhook{UUID: rows[i].WebhookUUID, URL: rows[i].URL, Secret: rows[i].Secret}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhooksRepository) Record(
+	ctx context.Context,
+	delivery *domain.WebhookDelivery,
+	attempt *domain.WebhookAttempt,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.Record", "SqlWebhooksAttemptAdd")
+	defer span.End()
+
+	var deliveredAt *time.Time
+	if attempt.Status == domain.DeliverySucceeded {
+		deliveredAt = &attempt.AttemptedAt
+	}
+
+	return inTx(ctx, r.conn, domain.ErrWebhooksDeliver, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(
+			ctx,
+			queries.SqlWebhooksAttemptAdd,
+			delivery.UUID,
+			attempt.StatusCode,
+			attempt.Error,
+			attempt.Duration,
+			attempt.AttemptedAt,
+		); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrWebhooksDeliver
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			queries.SqlWebhooksAttempted,
+			attempt.Status,
+			attempt.NextAttemptAt,
+			attempt.StatusCode,
+			attempt.Error,
+			deliveredAt,
+			delivery.UUID,
+		); err != nil {
+			tracing.Error(span, err)
+			return domain.ErrWebhooksDeliver
+		}
+
+		return nil
+	})
+}
+
+func (r *webhooksRepository) FindDeliveries(
+	ctx context.Context,
+	webhook uuid.UUID,
+	filter domain.WebhookDeliveryFilter,
+) ([]*domain.WebhookDelivery, error) {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.FindDeliveries", "SqlWebhooksFindDeliveries")
+	defer span.End()
+
+	var (
+		conditions []string
+		args       = []interface{}{webhook}
+	)
+
+	where := func(column, operator string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s %s $%d", column, operator, len(args)))
+	}
+
+	if filter.Status != "" {
+		where("d.status", "=", filter.Status)
+	}
+	if filter.Before != nil {
+		where("d.created_at", "<", *filter.Before)
+	}
+
+	var rows []deliveryRow
+
+	err := r.conn.SelectContext(
+		ctx,
+		&rows,
+		queries.SqlWebhooksFindDeliveries(conditions),
+		append(args, filter.Limit)...,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tracing.Error(span, err)
+		return nil, domain.ErrWebhooksFindDeliveries
+	}
+
+	deliveries := make([]*domain.WebhookDelivery, 0, len(rows))
+	for i := range rows {
+		deliveries = append(deliveries, rows[i].delivery())
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhooksRepository) FindDelivery(
+	ctx context.Context,
+	webhook uuid.UUID,
+	delivery uuid.UUID,
+) (*domain.WebhookDelivery, error) {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.FindDelivery", "SqlWebhooksFindDelivery")
+	defer span.End()
+
+	var row deliveryRow
+
+	err := r.conn.GetContext(ctx, &row, queries.SqlWebhooksFindDelivery, webhook, delivery)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrResourceNotFound
+	}
+
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrWebhooksFindDeliveries
+	}
+
+	found := row.delivery()
+	found.Log = []*domain.WebhookAttempt{}
+
+	if err := r.conn.SelectContext(ctx, &found.Log, queries.SqlWebhooksFindAttempts, delivery); err != nil {
+		tracing.Error(span, err)
+		return nil, domain.ErrWebhooksFindDeliveries
+	}
+
+	return found, nil
+}
+
+func (r *webhooksRepository) Redeliver(
+	ctx context.Context,
+	webhook uuid.UUID,
+	delivery uuid.UUID,
+) error {
+	ctx, span := tracing.StartQuery(ctx, "webhooksRepository.Redeliver", "SqlWebhooksRedeliver")
+	defer span.End()
+
+	result, err := r.conn.ExecContext(ctx, queries.SqlWebhooksRedeliver, r.now(), webhook, delivery)
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrWebhooksRedeliver
+	}
+
+	return affected(span, result, domain.ErrWebhooksRedeliver)
+}
+
+// affected reports a write that matched no row as not found,
+// fallback is returned if the count of rows cannot be read.
+func affected(span trace.Span, result sql.Result, fallback error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tracing.Error(span, err)
+		return fallback
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrResourceNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// albumsCache decorates an albums use case with a read-through cache
+// of FindByID and FindByIDs. The other reads go to the decorated use case, the
+// writes invalidate the albums they touch once they return.
+type albumsCache struct {
+	domain.AlbumsUseCase
+	albums *readThrough[domain.Albums]
+}
+
+// NewAlbumsCache caches up to size albums found by ID for ttl. The
+// cache is local, the writes of the other replicas are only seen
+// once the entries expire.
+func NewAlbumsCache(next domain.AlbumsUseCase, size int, ttl time.Duration) domain.AlbumsUseCase {
+	return &albumsCache{AlbumsUseCase: next, albums: newReadThrough[domain.Albums]("albums", size, ttl)}
+}
+
+func (c *albumsCache) FindByID(ctx context.Context, uuid uuid.UUID) (*domain.Albums, error) {
+	ctx, span := tracing.Start(ctx, "albumsCache.FindByID")
+	defer span.End()
+
+	album, err := c.albums.get(ctx, uuid, func(ctx context.Context) (*domain.Albums, error) {
+		return c.AlbumsUseCase.FindByID(ctx, uuid)
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	return album, nil
+}
+
+func (c *albumsCache) FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]*domain.Albums, error) {
+	ctx, span := tracing.Start(ctx, "albumsCache.FindByIDs")
+	defer span.End()
+
+	albums, err := c.albums.getMany(ctx, uuids, albumUUID, c.AlbumsUseCase.FindByIDs)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	return albums, nil
+}
+
+func albumUUID(album *domain.Albums) uuid.UUID {
+	return album.UUID
+}
+
+func (c *albumsCache) Update(ctx context.Context, uuid uuid.UUID, album *domain.Albums) error {
+	defer c.albums.invalidate(uuid)
+	return c.AlbumsUseCase.Update(ctx, uuid, album)
+}
+
+func (c *albumsCache) Patch(ctx context.Context, uuid uuid.UUID, patch *domain.AlbumsPatch) error {
+	defer c.albums.invalidate(uuid)
+	return c.AlbumsUseCase.Patch(ctx, uuid, patch)
+}
+
+func (c *albumsCache) Delete(ctx context.Context, uuid uuid.UUID, version int) error {
+	defer c.albums.invalidate(uuid)
+	return c.AlbumsUseCase.Delete(ctx, uuid, version)
+}
+
+func (c *albumsCache) Restore(ctx context.Context, uuid uuid.UUID) error {
+	defer c.albums.invalidate(uuid)
+	return c.AlbumsUseCase.Restore(ctx, uuid)
+}
+
+func (c *albumsCache) RestoreRevision(ctx context.Context, uuid uuid.UUID, revision int, version int) (int, error) {
+	defer c.albums.invalidate(uuid)
+	return c.AlbumsUseCase.RestoreRevision(ctx, uuid, revision, version)
+}
+
+func (c *albumsCache) Bulk(ctx context.Context, operations []*domain.AlbumsBulkOperation, atomic bool) error {
+	defer func() {
+		uuids := make([]uuid.UUID, 0, len(operations))
+		for _, operation := range operations {
+			uuids = append(uuids, operation.Album.UUID)
+		}
+		c.albums.invalidate(uuids...)
+	}()
+
+	return c.AlbumsUseCase.Bulk(ctx, operations, atomic)
+}
+
+func (c *albumsCache) Import(ctx context.Context, rows []*domain.AlbumsImportRow, dryRun bool) error {
+	// the albums matched by barcode are only known once imported
+	defer func() {
+		uuids := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			uuids = append(uuids, row.Album.UUID)
+		}
+		c.albums.invalidate(uuids...)
+	}()
+
+	return c.AlbumsUseCase.Import(ctx, rows, dryRun)
+}
@@ -3,51 +3,455 @@ package usecase
 import (
 	"context"
 	"hexagony/app/domain"
+	"hexagony/libs/tracing"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type albumsUseCase struct {
 	albumRepository domain.AlbumsRepository
+	auditLog        domain.AuditLog
+	outbox          domain.Outbox
 }
 
-func NewAlbumsUseCase(ar domain.AlbumsRepository) domain.AlbumsUseCase {
-	return &albumsUseCase{albumRepository: ar}
+func NewAlbumsUseCase(ar domain.AlbumsRepository, al domain.AuditLog, ob domain.Outbox) domain.AlbumsUseCase {
+	return &albumsUseCase{albumRepository: ar, auditLog: al, outbox: ob}
+}
+
+// entry returns the audit entry of an action of the album with the changes from before to after.
+func (s *albumsUseCase) entry(ctx context.Context, action string, uuid uuid.UUID, before, after *domain.Albums) *domain.AuditEntry {
+	entry := domain.AuditEntry{Action: action, ResourceType: "album", ResourceID: uuid.String()}
+
+	if before != nil && after != nil {
+		entry.Changes = before.Diff(after)
+	}
+
+	return auditEntry(ctx, entry)
 }
 
 func (s *albumsUseCase) FindAll(ctx context.Context) ([]*domain.Albums, error) {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.FindAll")
+	defer span.End()
+
 	album, err := s.albumRepository.FindAll(ctx)
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, err
 	}
 	return album, nil
 }
 
 func (s *albumsUseCase) FindByID(ctx context.Context, uuid uuid.UUID) (*domain.Albums, error) {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.FindByID")
+	defer span.End()
+
 	album, err := s.albumRepository.FindByID(ctx, uuid)
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, err
 	}
 	return album, nil
 }
 
+func (s *albumsUseCase) FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]*domain.Albums, error) {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.FindByIDs")
+	defer span.End()
+
+	albums, err := s.albumRepository.FindByIDs(ctx, uuids)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return albums, nil
+}
+
 func (s *albumsUseCase) Add(ctx context.Context, album *domain.Albums) error {
-	if err := s.albumRepository.Add(ctx, album); err != nil {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Add")
+	defer span.End()
+
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		if err := s.albumRepository.Add(ctx, album); err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, s.auditLog, s.entry(ctx, domain.AuditCreate, album.UUID, &domain.Albums{}, album)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewAlbumEvent(ctx, domain.EventAlbumCreated, album)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, s.auditLog, failed(s.entry(ctx, domain.AuditCreate, album.UUID, nil, nil), err))
 		return err
 	}
+
 	return nil
 }
 
 func (s *albumsUseCase) Update(ctx context.Context, uuid uuid.UUID, album *domain.Albums) error {
-	if err := s.albumRepository.Update(ctx, uuid, album); err != nil {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Update")
+	defer span.End()
+
+	// the album is locked from its read to the write, the written
+	// one is read back for the event
+	var before, after *domain.Albums
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if before, err = s.albumRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := s.albumRepository.Update(ctx, uuid, album); err != nil {
+			return nil, err
+		}
+
+		if after, err = s.albumRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, s.auditLog, s.entry(ctx, domain.AuditUpdate, uuid, before, after)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewAlbumEvent(ctx, domain.EventAlbumUpdated, after)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, s.auditLog, failed(s.entry(ctx, domain.AuditUpdate, uuid, nil, nil), err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *albumsUseCase) Patch(ctx context.Context, uuid uuid.UUID, patch *domain.AlbumsPatch) error {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Patch")
+	defer span.End()
+
+	var before, after *domain.Albums
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if before, err = s.albumRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := s.albumRepository.Patch(ctx, uuid, patch); err != nil {
+			return nil, err
+		}
+
+		if after, err = s.albumRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, s.auditLog, s.entry(ctx, domain.AuditPatch, uuid, before, after)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewAlbumEvent(ctx, domain.EventAlbumUpdated, after)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, s.auditLog, failed(s.entry(ctx, domain.AuditPatch, uuid, nil, nil), err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *albumsUseCase) Delete(ctx context.Context, uuid uuid.UUID, version int) error {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Delete")
+	defer span.End()
+
+	// the deleted album is no longer found, the locked one
+	// is only moved to the trash with the next version
+	var before, after *domain.Albums
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if before, err = s.albumRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := s.albumRepository.Delete(ctx, uuid, version); err != nil {
+			return nil, err
+		}
+
+		deleted := *before
+		deletedAt := time.Now()
+		deleted.DeletedAt, deleted.Version, after = &deletedAt, before.Version+1, &deleted
+
+		if err := audit(ctx, s.auditLog, s.entry(ctx, domain.AuditDelete, uuid, before, after)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewAlbumEvent(ctx, domain.EventAlbumDeleted, after)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, s.auditLog, failed(s.entry(ctx, domain.AuditDelete, uuid, nil, nil), err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *albumsUseCase) FindTrash(ctx context.Context) ([]*domain.Albums, error) {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.FindTrash")
+	defer span.End()
+
+	trash, err := s.albumRepository.FindTrash(ctx)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return trash, nil
+}
+
+func (s *albumsUseCase) Restore(ctx context.Context, uuid uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Restore")
+	defer span.End()
+
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		if err := s.albumRepository.Restore(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		// the restored album is read back in the transaction
+		album, err := s.albumRepository.FindByID(ctx, uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, s.auditLog, s.entry(ctx, domain.AuditRestore, uuid, nil, nil)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewAlbumEvent(ctx, domain.EventAlbumRestored, album)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, s.auditLog, failed(s.entry(ctx, domain.AuditRestore, uuid, nil, nil), err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *albumsUseCase) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Purge")
+	defer span.End()
+
+	entry := domain.AuditEntry{Action: domain.AuditPurge, ResourceType: "album"}
+
+	// the purge raises no event, the outbox only runs it
+	// in a transaction along with its audit entry
+	var purged int64
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if purged, err = s.albumRepository.Purge(ctx, before); err != nil || purged == 0 {
+			return nil, err
+		}
+
+		entry.Changes = []domain.FieldChange{{Field: "purged", From: 0, To: purged}}
+		return nil, audit(ctx, s.auditLog, auditEntry(ctx, entry))
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, s.auditLog, failed(auditEntry(ctx, entry), err))
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (s *albumsUseCase) FindRevisions(ctx context.Context, uuid uuid.UUID) ([]*domain.AlbumRevision, error) {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.FindRevisions")
+	defer span.End()
+
+	revisions, err := s.albumRepository.FindRevisions(ctx, uuid)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	// each revision is compared with the previous one,
+	// the first one with an empty album
+	previous := &domain.Albums{}
+	for _, revision := range revisions {
+		revision.Changes = previous.Diff(&revision.Snapshot)
+		previous = &revision.Snapshot
+	}
+
+	return revisions, nil
+}
+
+func (s *albumsUseCase) RestoreRevision(ctx context.Context, uuid uuid.UUID, revision int, version int) (int, error) {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.RestoreRevision")
+	defer span.End()
+
+	// the fields written back are only known once restored
+	var before, after *domain.Albums
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if before, err = s.albumRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		version, err = s.albumRepository.RestoreRevision(ctx, uuid, revision, version)
+		if err != nil {
+			return nil, err
+		}
+
+		if after, err = s.albumRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, s.auditLog, s.entry(ctx, domain.AuditRestoreRevision, uuid, before, after)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewAlbumEvent(ctx, domain.EventAlbumUpdated, after)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, s.auditLog, failed(s.entry(ctx, domain.AuditRestoreRevision, uuid, nil, nil), err))
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (s *albumsUseCase) Bulk(ctx context.Context, operations []*domain.AlbumsBulkOperation, atomic bool) error {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Bulk")
+	defer span.End()
+
+	types := map[string]string{
+		domain.BulkCreate: domain.EventAlbumCreated,
+		domain.BulkUpdate: domain.EventAlbumUpdated,
+		domain.BulkDelete: domain.EventAlbumDeleted,
+	}
+
+	actions := map[string]string{
+		domain.BulkCreate: domain.AuditCreate,
+		domain.BulkUpdate: domain.AuditUpdate,
+		domain.BulkDelete: domain.AuditDelete,
+	}
+
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		if err := s.albumRepository.Bulk(ctx, operations, atomic); err != nil {
+			return nil, err
+		}
+
+		// the previous state is not read back for the bulk updates and deletes,
+		// their changes are found in the album revisions
+		events := []*domain.Event{}
+		entries := make([]*domain.AuditEntry, 0, len(operations))
+
+		for _, operation := range operations {
+			if operation.Err != nil {
+				entries = append(entries, failed(s.entry(ctx, actions[operation.Op], operation.Album.UUID, nil, nil), operation.Err))
+				continue
+			}
+
+			var before, after *domain.Albums
+			if operation.Op == domain.BulkCreate {
+				before, after = &domain.Albums{}, &operation.Album
+			}
+
+			entries = append(entries, s.entry(ctx, actions[operation.Op], operation.Album.UUID, before, after))
+			events = append(events, domain.NewAlbumEvent(ctx, types[operation.Op], &operation.Album))
+		}
+
+		if err := audit(ctx, s.auditLog, entries...); err != nil {
+			return nil, err
+		}
+		return events, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+
+		// nothing was written, every operation is recorded as failed
+		entries := make([]*domain.AuditEntry, 0, len(operations))
+		for _, operation := range operations {
+			cause := operation.Err
+			if cause == nil {
+				cause = err
+			}
+			entries = append(entries, failed(s.entry(ctx, actions[operation.Op], operation.Album.UUID, nil, nil), cause))
+		}
+		auditDetached(ctx, s.auditLog, entries...)
+
 		return err
 	}
+
 	return nil
 }
 
-func (s *albumsUseCase) Delete(ctx context.Context, uuid uuid.UUID) error {
-	if err := s.albumRepository.Delete(ctx, uuid); err != nil {
+func (s *albumsUseCase) Export(ctx context.Context, fn func(*domain.Albums) error) error {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Export")
+	defer span.End()
+
+	if err := s.albumRepository.Export(ctx, fn); err != nil {
+		tracing.Error(span, err)
 		return err
 	}
 	return nil
 }
+
+func (s *albumsUseCase) Import(ctx context.Context, rows []*domain.AlbumsImportRow, dryRun bool) error {
+	ctx, span := tracing.Start(ctx, "albumsUseCase.Import")
+	defer span.End()
+
+	// a dry run is rolled back by the repository, it raises no event
+	if dryRun {
+		if err := s.albumRepository.Import(ctx, rows, true); err != nil {
+			tracing.Error(span, err)
+			return err
+		}
+		return nil
+	}
+
+	// the rows that failed are recorded as updates, their outcome is not known
+	action := func(row *domain.AlbumsImportRow) string {
+		if row.Outcome == domain.ImportCreated {
+			return domain.AuditCreate
+		}
+		return domain.AuditUpdate
+	}
+
+	err := s.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		if err := s.albumRepository.Import(ctx, rows, false); err != nil {
+			return nil, err
+		}
+
+		events := []*domain.Event{}
+		entries := make([]*domain.AuditEntry, 0, len(rows))
+
+		// as for the bulk writes, the changes of the updates are found in the album revisions
+		for _, row := range rows {
+			switch {
+			case row.Err != nil:
+				entries = append(entries, failed(s.entry(ctx, action(row), row.Album.UUID, nil, nil), row.Err))
+			case row.Outcome == domain.ImportCreated:
+				entries = append(entries, s.entry(ctx, domain.AuditCreate, row.Album.UUID, &domain.Albums{}, &row.Album))
+				events = append(events, domain.NewAlbumEvent(ctx, domain.EventAlbumCreated, &row.Album))
+			default:
+				entries = append(entries, s.entry(ctx, domain.AuditUpdate, row.Album.UUID, nil, nil))
+				events = append(events, domain.NewAlbumEvent(ctx, domain.EventAlbumUpdated, &row.Album))
+			}
+		}
+
+		if err := audit(ctx, s.auditLog, entries...); err != nil {
+			return nil, err
+		}
+		return events, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+
+		entries := make([]*domain.AuditEntry, 0, len(rows))
+		for _, row := range rows {
+			entries = append(entries, failed(s.entry(ctx, action(row), row.Album.UUID, nil, nil), err))
+		}
+		auditDetached(ctx, s.auditLog, entries...)
+
+		return err
+	}
+
+	return nil
+}
@@ -28,10 +28,10 @@ func TestAlbumsFindAll(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockAlbumRepo.On("FindAll",
-			mock.AnythingOfType("context.todoCtx")).
+			mock.Anything).
 			Return(mockListAlbum, nil).Once()
 
-		a := NewAlbumsUseCase(mockAlbumRepo)
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		list, err := a.FindAll(context.TODO())
 
 		assert.Equal(t, "St. Anger", list[0].Name)
@@ -43,10 +43,10 @@ func TestAlbumsFindAll(t *testing.T) {
 
 	t.Run("error-failed", func(t *testing.T) {
 		mockAlbumRepo.On("FindAll",
-			mock.AnythingOfType("context.todoCtx")).
+			mock.Anything).
 			Return(nil, errors.New("Unexpected error")).Once()
 
-		a := NewAlbumsUseCase(mockAlbumRepo)
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		_, err := a.FindAll(context.TODO())
 
 		assert.NotNil(t, err)
@@ -68,11 +68,11 @@ func TestAlbumsFindByID(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockAlbumRepo.On("FindByID",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID")).
 			Return(mockAlbum, nil).Once()
 
-		a := NewAlbumsUseCase(mockAlbumRepo)
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		album, err := a.FindByID(context.TODO(), newUUID)
 
 		assert.Equal(t, "St. Anger", album.Name)
@@ -82,11 +82,11 @@ func TestAlbumsFindByID(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockAlbumRepo.On("FindByID",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID")).
 			Return(nil, errors.New("Unexpected error")).Once()
 
-		a := NewAlbumsUseCase(mockAlbumRepo)
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		_, err := a.FindByID(context.TODO(), newUUID)
 
 		assert.NotNil(t, err)
@@ -107,11 +107,11 @@ func TestAlbumsAdd(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockAlbumRepo.On("Add",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("*domain.Albums")).
 			Return(nil).Once()
 
-		u := NewAlbumsUseCase(mockAlbumRepo)
+		u := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		err := u.Add(context.TODO(), mockAlbum)
 
 		assert.NoError(t, err)
@@ -120,11 +120,11 @@ func TestAlbumsAdd(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockAlbumRepo.On("Add",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("*domain.Albums")).
 			Return(errors.New("Unexpected error")).Once()
 
-		u := NewAlbumsUseCase(mockAlbumRepo)
+		u := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		err := u.Add(context.TODO(), mockAlbum)
 
 		assert.NotNil(t, err)
@@ -136,6 +136,7 @@ func TestAlbumsAdd(t *testing.T) {
 func TestAlbumsUpdate(t *testing.T) {
 	newUUID := uuid.New()
 	mockAlbumRepo := new(mocks.AlbumRepository)
+	mockAlbumRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}, nil)
 	mockAlbum := &domain.Albums{
 		UUID:      newUUID,
 		Name:      "St. Anger",
@@ -146,12 +147,12 @@ func TestAlbumsUpdate(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockAlbumRepo.On("Update",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID"),
 			mock.AnythingOfType("*domain.Albums")).
 			Return(nil).Once()
 
-		a := NewAlbumsUseCase(mockAlbumRepo)
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		err := a.Update(context.TODO(), newUUID, mockAlbum)
 
 		assert.NoError(t, err)
@@ -160,12 +161,12 @@ func TestAlbumsUpdate(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockAlbumRepo.On("Update",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID"),
 			mock.AnythingOfType("*domain.Albums")).
 			Return(errors.New("Unexpected error")).Once()
 
-		a := NewAlbumsUseCase(mockAlbumRepo)
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
 		err := a.Update(context.TODO(), newUUID, mockAlbum)
 
 		assert.NotNil(t, err)
@@ -174,18 +175,57 @@ func TestAlbumsUpdate(t *testing.T) {
 	})
 }
 
+func TestAlbumsPatch(t *testing.T) {
+	newUUID := uuid.New()
+	mockAlbumRepo := new(mocks.AlbumRepository)
+	mockAlbumRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}, nil)
+	name := "Load"
+	mockPatch := &domain.AlbumsPatch{Name: &name, UpdatedAt: time.Now()}
+
+	t.Run("success", func(t *testing.T) {
+		mockAlbumRepo.On("Patch",
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.AnythingOfType("*domain.AlbumsPatch")).
+			Return(nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		err := a.Patch(context.TODO(), newUUID, mockPatch)
+
+		assert.NoError(t, err)
+		mockAlbumRepo.AssertExpectations(t)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockAlbumRepo.On("Patch",
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.AnythingOfType("*domain.AlbumsPatch")).
+			Return(errors.New("Unexpected error")).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		err := a.Patch(context.TODO(), newUUID, mockPatch)
+
+		assert.NotNil(t, err)
+
+		mockAlbumRepo.AssertExpectations(t)
+	})
+}
+
 func TestAlbumsDelete(t *testing.T) {
 	newUUID := uuid.New()
 	mockAlbumRepo := new(mocks.AlbumRepository)
+	mockAlbumRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}, nil)
 
 	t.Run("success", func(t *testing.T) {
 		mockAlbumRepo.On("Delete",
-			mock.AnythingOfType("context.todoCtx"),
-			mock.AnythingOfType("uuid.UUID")).
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.AnythingOfType("int")).
 			Return(nil).Once()
 
-		u := NewAlbumsUseCase(mockAlbumRepo)
-		err := u.Delete(context.TODO(), newUUID)
+		u := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		err := u.Delete(context.TODO(), newUUID, 0)
 
 		assert.NoError(t, err)
 		mockAlbumRepo.AssertExpectations(t)
@@ -193,15 +233,188 @@ func TestAlbumsDelete(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockAlbumRepo.On("Delete",
-			mock.AnythingOfType("context.todoCtx"),
-			mock.AnythingOfType("uuid.UUID")).
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.AnythingOfType("int")).
 			Return(errors.New("Unexpected error")).Once()
 
-		a := NewAlbumsUseCase(mockAlbumRepo)
-		err := a.Delete(context.TODO(), newUUID)
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		err := a.Delete(context.TODO(), newUUID, 0)
 
 		assert.NotNil(t, err)
 
 		mockAlbumRepo.AssertExpectations(t)
 	})
 }
+
+func TestAlbumsTrash(t *testing.T) {
+	newUUID := uuid.New()
+	mockAlbumRepo := new(mocks.AlbumRepository)
+	deletedAt := time.Now()
+	mockTrash := []*domain.Albums{{UUID: newUUID, Name: "St. Anger", Length: 75, DeletedAt: &deletedAt}}
+
+	t.Run("find", func(t *testing.T) {
+		mockAlbumRepo.On("FindTrash", mock.Anything).Return(mockTrash, nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		trash, err := a.FindTrash(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockTrash, trash)
+		mockAlbumRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		mockAlbumRepo.On("Restore", mock.Anything, newUUID).Return(domain.ErrResourceNotFound).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		err := a.Restore(context.TODO(), newUUID)
+
+		assert.ErrorIs(t, err, domain.ErrResourceNotFound)
+		mockAlbumRepo.AssertExpectations(t)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		mockAlbumRepo.On("Purge", mock.Anything, deletedAt).Return(int64(1), nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		purged, err := a.Purge(context.TODO(), deletedAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		mockAlbumRepo.AssertExpectations(t)
+	})
+}
+
+func TestAlbumsRevisions(t *testing.T) {
+	newUUID := uuid.New()
+	mockAlbumRepo := new(mocks.AlbumRepository)
+	mockAlbumRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}, nil).Maybe()
+	deletedAt := time.Now()
+
+	t.Run("find", func(t *testing.T) {
+		mockRevisions := []*domain.AlbumRevision{
+			{AlbumUUID: newUUID, Revision: 1, Action: domain.RevisionCreated, Snapshot: domain.Albums{Name: "St. Anger", Length: 75}},
+			{AlbumUUID: newUUID, Revision: 2, Action: domain.RevisionUpdated, Snapshot: domain.Albums{Name: "St. Anger", Length: 76}},
+			{AlbumUUID: newUUID, Revision: 3, Action: domain.RevisionDeleted, Snapshot: domain.Albums{Name: "St. Anger", Length: 76, DeletedAt: &deletedAt}},
+		}
+
+		mockAlbumRepo.On("FindRevisions", mock.Anything, newUUID).Return(mockRevisions, nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		revisions, err := a.FindRevisions(context.TODO(), newUUID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.FieldChange{
+			{Field: "name", From: "", To: "St. Anger"},
+			{Field: "length", From: 0, To: 75},
+		}, revisions[0].Changes)
+		assert.Equal(t, []domain.FieldChange{{Field: "length", From: 75, To: 76}}, revisions[1].Changes)
+		assert.Len(t, revisions[2].Changes, 1)
+		assert.Equal(t, "deleted_at", revisions[2].Changes[0].Field)
+		mockAlbumRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		mockAlbumRepo.On("RestoreRevision", mock.Anything, newUUID, 1, 3).Return(4, nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		version, err := a.RestoreRevision(context.TODO(), newUUID, 1, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, version)
+		mockAlbumRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore error", func(t *testing.T) {
+		mockAlbumRepo.On("RestoreRevision", mock.Anything, newUUID, 9, 0).Return(0, domain.ErrResourceNotFound).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), newOutbox())
+		_, err := a.RestoreRevision(context.TODO(), newUUID, 9, 0)
+
+		assert.ErrorIs(t, err, domain.ErrResourceNotFound)
+		mockAlbumRepo.AssertExpectations(t)
+	})
+}
+
+func TestAlbumsBulk(t *testing.T) {
+	mockAlbumRepo := new(mocks.AlbumRepository)
+	auditLog, recorded := recordedEntries()
+
+	operations := []*domain.AlbumsBulkOperation{
+		{Op: domain.BulkCreate, Album: domain.Albums{UUID: uuid.New(), Name: "St. Anger", Length: 75}},
+		{Op: domain.BulkDelete, Album: domain.Albums{UUID: uuid.New()}, Err: domain.ErrResourceNotFound},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockAlbumRepo.On("Bulk", mock.Anything, operations, false).Return(nil).Once()
+
+		err := NewAlbumsUseCase(mockAlbumRepo, auditLog, newOutbox()).Bulk(context.TODO(), operations, false)
+
+		assert.NoError(t, err)
+		mockAlbumRepo.AssertExpectations(t)
+
+		// every operation is audited at once, the failed ones as failures
+		auditLog.AssertNumberOfCalls(t, "Record", 1)
+		if assert.Len(t, *recorded, 2) {
+			assert.Equal(t, domain.AuditCreate, (*recorded)[0].Action)
+			assert.Equal(t, domain.AuditSuccess, (*recorded)[0].Outcome)
+			assert.Len(t, (*recorded)[0].Changes, 2)
+			assert.Equal(t, domain.AuditDelete, (*recorded)[1].Action)
+			assert.Equal(t, domain.AuditFailure, (*recorded)[1].Outcome)
+		}
+	})
+
+	t.Run("aborted", func(t *testing.T) {
+		mockAlbumRepo.On("Bulk", mock.Anything, operations, true).Return(domain.ErrAlbumsBulkAborted).Once()
+
+		err := NewAlbumsUseCase(mockAlbumRepo, auditLog, newOutbox()).Bulk(context.TODO(), operations, true)
+
+		assert.ErrorIs(t, err, domain.ErrAlbumsBulkAborted)
+		mockAlbumRepo.AssertExpectations(t)
+
+		// nothing was written, both operations are failures
+		auditLog.AssertNumberOfCalls(t, "Record", 2)
+		if assert.Len(t, *recorded, 2) {
+			assert.Equal(t, domain.AuditFailure, (*recorded)[0].Outcome)
+			assert.Equal(t, domain.AuditFailure, (*recorded)[1].Outcome)
+		}
+	})
+}
+
+func TestAlbumsImport(t *testing.T) {
+	mockAlbumRepo := new(mocks.AlbumRepository)
+	auditLog, recorded := recordedEntries()
+
+	rows := []*domain.AlbumsImportRow{
+		{Line: 2, Album: domain.Albums{UUID: uuid.New(), Name: "St. Anger", Length: 75}, Outcome: domain.ImportCreated},
+		{Line: 3, Album: domain.Albums{UUID: uuid.New(), Name: "Load", Length: 79}, Err: domain.ErrAlbumsImportTrashed},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		mockAlbumRepo.On("Import", mock.Anything, rows, true).Return(nil).Once()
+
+		err := NewAlbumsUseCase(mockAlbumRepo, auditLog, newOutbox()).Import(context.TODO(), rows, true)
+
+		assert.NoError(t, err)
+		mockAlbumRepo.AssertExpectations(t)
+		auditLog.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockAlbumRepo.On("Import", mock.Anything, rows, false).Return(nil).Once()
+
+		err := NewAlbumsUseCase(mockAlbumRepo, auditLog, newOutbox()).Import(context.TODO(), rows, false)
+
+		assert.NoError(t, err)
+		mockAlbumRepo.AssertExpectations(t)
+
+		// the failed rows are audited as failures, in the same call
+		auditLog.AssertNumberOfCalls(t, "Record", 1)
+		if assert.Len(t, *recorded, 2) {
+			assert.Equal(t, domain.AuditCreate, (*recorded)[0].Action)
+			assert.Equal(t, domain.AuditSuccess, (*recorded)[0].Outcome)
+			assert.Equal(t, domain.AuditFailure, (*recorded)[1].Outcome)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/libs/clientip"
+	"hexagony/libs/clog"
+	"hexagony/libs/requestid"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// auditEntry completes entry with the actor, the client IP and
+// the request ID carried by ctx.
+func auditEntry(ctx context.Context, entry domain.AuditEntry) *domain.AuditEntry {
+	if actor, ok := domain.ActorFromContext(ctx); ok {
+		entry.ActorEmail = actor.Email
+
+		if actor.UUID != uuid.Nil {
+			entry.ActorUUID = &actor.UUID
+		}
+	}
+
+	if entry.Outcome == "" {
+		entry.Outcome = domain.AuditSuccess
+	}
+
+	entry.IP = clientip.FromContext(ctx)
+	entry.RequestID = requestid.FromContext(ctx)
+	entry.CreatedAt = time.Now()
+
+	return &entry
+}
+
+// failed marks entry as the record of an action that failed with err,
+// or that was denied when err refuses it.
+func failed(entry *domain.AuditEntry, err error) *domain.AuditEntry {
+	entry.Outcome = domain.AuditFailure
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		switch domainErr.Kind {
+		case domain.KindUnauthorized, domain.KindForbidden, domain.KindPreconditionFailed, domain.KindPreconditionRequired:
+			entry.Outcome = domain.AuditDenied
+		}
+	}
+
+	return entry
+}
+
+// audit records the entries of a write in its transaction, carried
+// by ctx. The write must fail with them, so the error is returned.
+func audit(ctx context.Context, log domain.AuditLog, entries ...*domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := log.Record(ctx, entries...); err != nil {
+		tracing.Error(trace.SpanFromContext(ctx), err)
+		return err
+	}
+
+	return nil
+}
+
+// auditDetached records the entries of an action that wrote nothing,
+// a failed write or a login. A failure to record them is logged.
+func auditDetached(ctx context.Context, log domain.AuditLog, entries ...*domain.AuditEntry) {
+	if err := audit(ctx, log, entries...); err != nil {
+		clog.ErrorContext(ctx, err, "failed to record the audit entry")
+	}
+}
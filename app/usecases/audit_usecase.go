@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/tracing"
+)
+
+type auditUseCase struct {
+	auditLog domain.AuditLog
+}
+
+func NewAuditUseCase(al domain.AuditLog) domain.AuditUseCase {
+	return &auditUseCase{auditLog: al}
+}
+
+func (a *auditUseCase) Find(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "auditUseCase.Find")
+	defer span.End()
+
+	entries, err := a.auditLog.Find(ctx, filter)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (a *auditUseCase) Verify(ctx context.Context) (*domain.AuditVerification, error) {
+	ctx, span := tracing.Start(ctx, "auditUseCase.Verify")
+	defer span.End()
+
+	verification, err := a.auditLog.Verify(ctx)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return verification, nil
+}
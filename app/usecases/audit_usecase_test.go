@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"hexagony/libs/clientip"
+	"hexagony/libs/requestid"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newAuditLog returns an audit log accepting any entry.
+func newAuditLog() *mocks.AuditLog {
+	auditLog := new(mocks.AuditLog)
+	auditLog.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return auditLog
+}
+
+// recordedEntry returns an audit log keeping the last entry recorded.
+func recordedEntry() (*mocks.AuditLog, *domain.AuditEntry) {
+	var recorded domain.AuditEntry
+
+	auditLog := new(mocks.AuditLog)
+	auditLog.On("Record", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			entries := args.Get(1).([]*domain.AuditEntry)
+			recorded = *entries[len(entries)-1]
+		}).
+		Return(nil)
+
+	return auditLog, &recorded
+}
+
+// recordedEntries returns an audit log keeping the entries of the last call.
+func recordedEntries() (*mocks.AuditLog, *[]*domain.AuditEntry) {
+	var recorded []*domain.AuditEntry
+
+	auditLog := new(mocks.AuditLog)
+	auditLog.On("Record", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).([]*domain.AuditEntry) }).
+		Return(nil)
+
+	return auditLog, &recorded
+}
+
+func TestAuditAlbums(t *testing.T) {
+	newUUID := uuid.New()
+	actor := domain.Actor{UUID: uuid.New(), Email: "john@doe.com"}
+
+	ctx := domain.NewActorContext(context.TODO(), actor)
+	ctx = clientip.NewContext(ctx, "127.0.0.1")
+	ctx = requestid.NewContext(ctx, "request-1")
+
+	mockAlbumRepo := new(mocks.AlbumRepository)
+	mockAlbumRepo.On("FindByID", mock.Anything, newUUID).
+		Return(&domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}, nil).Once()
+	mockAlbumRepo.On("Update", mock.Anything, newUUID, mock.Anything).Return(nil).Once()
+	mockAlbumRepo.On("FindByID", mock.Anything, newUUID).
+		Return(&domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 76}, nil).Once()
+	mockAlbumRepo.On("FindByID", mock.Anything, newUUID).
+		Return(&domain.Albums{UUID: newUUID, Name: "St. Anger", Length: 75}, nil)
+	mockAlbumRepo.On("Delete", mock.Anything, newUUID, 0).Return(domain.ErrResourceNotFound).Once()
+	mockAlbumRepo.On("Delete", mock.Anything, newUUID, 3).Return(domain.ErrPreconditionFailed).Once()
+
+	auditLog, recorded := recordedEntry()
+
+	a := NewAlbumsUseCase(mockAlbumRepo, auditLog, newOutbox())
+
+	t.Run("update", func(t *testing.T) {
+		err := a.Update(ctx, newUUID, &domain.Albums{Name: "St. Anger", Length: 76})
+		assert.NoError(t, err)
+
+		assert.Equal(t, domain.AuditUpdate, recorded.Action)
+		assert.Equal(t, domain.AuditSuccess, recorded.Outcome)
+		assert.Equal(t, "album", recorded.ResourceType)
+		assert.Equal(t, newUUID.String(), recorded.ResourceID)
+		assert.Equal(t, &actor.UUID, recorded.ActorUUID)
+		assert.Equal(t, actor.Email, recorded.ActorEmail)
+		assert.Equal(t, "127.0.0.1", recorded.IP)
+		assert.Equal(t, "request-1", recorded.RequestID)
+		assert.Equal(t, []domain.FieldChange{{Field: "length", From: 75, To: 76}}, recorded.Changes)
+	})
+
+	t.Run("failed writes are recorded", func(t *testing.T) {
+		*recorded = domain.AuditEntry{}
+
+		err := a.Delete(ctx, newUUID, 0)
+		assert.ErrorIs(t, err, domain.ErrResourceNotFound)
+
+		assert.Equal(t, domain.AuditDelete, recorded.Action)
+		assert.Equal(t, domain.AuditFailure, recorded.Outcome)
+		assert.Equal(t, newUUID.String(), recorded.ResourceID)
+		assert.Empty(t, recorded.Changes)
+	})
+
+	t.Run("refused writes are recorded as denied", func(t *testing.T) {
+		err := a.Delete(ctx, newUUID, 3)
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+
+		assert.Equal(t, domain.AuditDelete, recorded.Action)
+		assert.Equal(t, domain.AuditDenied, recorded.Outcome)
+	})
+
+	t.Run("recording failures fail the write", func(t *testing.T) {
+		outbox, raised := raisedEvents()
+
+		// the entry of the write fails, the one of its failure is recorded
+		failing := new(mocks.AuditLog)
+		failing.On("Record", mock.Anything, mock.Anything).Return(domain.ErrAuditRecord).Once()
+		failing.On("Record", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAlbumRepo.On("Update", mock.Anything, newUUID, mock.Anything).Return(nil).Once()
+
+		err := NewAlbumsUseCase(mockAlbumRepo, failing, outbox).Update(ctx, newUUID, &domain.Albums{Name: "Load", Length: 79})
+		assert.ErrorIs(t, err, domain.ErrAuditRecord)
+		assert.Empty(t, *raised)
+
+		failing.AssertExpectations(t)
+	})
+
+	t.Run("the entries are recorded in the transaction of the write", func(t *testing.T) {
+		type txKey struct{}
+
+		// the outbox runs the write with the context of its transaction
+		outbox := new(mocks.Outbox)
+		outbox.On("Within", mock.Anything, mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) ([]*domain.Event, error)) error {
+				_, err := fn(context.WithValue(ctx, txKey{}, true))
+				return err
+			}).
+			Once()
+
+		auditLog := new(mocks.AuditLog)
+		auditLog.On("Record", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) == true }), mock.Anything).
+			Return(nil).
+			Once()
+		mockAlbumRepo.On("Restore", mock.Anything, newUUID).Return(nil).Once()
+
+		err := NewAlbumsUseCase(mockAlbumRepo, auditLog, outbox).Restore(ctx, newUUID)
+		assert.NoError(t, err)
+
+		auditLog.AssertExpectations(t)
+	})
+
+	mockAlbumRepo.AssertExpectations(t)
+}
+
+func TestAuditUsers(t *testing.T) {
+	newUUID := uuid.New()
+
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("FindByID", mock.Anything, newUUID).
+		Return(&domain.UsersList{UUID: newUUID, Name: "John Doe", Email: "john@doe.com"}, nil).Once()
+	mockUserRepo.On("Patch", mock.Anything, newUUID, mock.Anything).Return(nil).Once()
+	mockUserRepo.On("FindByID", mock.Anything, newUUID).
+		Return(&domain.UsersList{UUID: newUUID, Name: "John Doe", Email: "jane@doe.com"}, nil).Once()
+
+	auditLog, recorded := recordedEntry()
+
+	email := "jane@doe.com"
+	err := NewUserUseCase(mockUserRepo, auditLog, newOutbox()).Patch(context.TODO(), newUUID, &domain.UsersPatch{Email: &email})
+	assert.NoError(t, err)
+
+	assert.Equal(t, domain.AuditPatch, recorded.Action)
+	assert.Equal(t, "user", recorded.ResourceType)
+	assert.Nil(t, recorded.ActorUUID)
+	assert.Equal(t, []domain.FieldChange{{Field: "email", From: "john@doe.com", To: email}}, recorded.Changes)
+
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestAuditLogins(t *testing.T) {
+	mockAuthRepo := new(mocks.AuthRepository)
+	jwtConfig := config.JWT{Secret: "secret", TokenTTL: time.Hour}
+
+	mockUser := &domain.Users{
+		UUID:     uuid.New(),
+		Email:    "xorycx@gmail.com",
+		Password: "$2a$10$Vm8jmbPV5NMgoCag3O/iM.LTfMs6rmmwgDwRUw9m8QGFyis7EA/Gy",
+	}
+
+	mockAuthRepo.On("Authenticate", mock.Anything, mockUser.Email).Return(mockUser, nil)
+
+	auditLog, recorded := recordedEntry()
+	a := NewAuthUsecase(mockAuthRepo, auditLog, jwtConfig)
+
+	t.Run("success", func(t *testing.T) {
+		_, err := a.Authenticate(context.TODO(), mockUser.Email, "12345678")
+		assert.NoError(t, err)
+
+		assert.Equal(t, domain.AuditLogin, recorded.Action)
+		assert.Equal(t, domain.AuditSuccess, recorded.Outcome)
+		assert.Equal(t, &mockUser.UUID, recorded.ActorUUID)
+		assert.Equal(t, mockUser.UUID.String(), recorded.ResourceID)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := a.Authenticate(context.TODO(), mockUser.Email, "wrong password")
+		assert.ErrorIs(t, err, domain.ErrAuthPassword)
+
+		assert.Equal(t, domain.AuditFailure, recorded.Outcome)
+		assert.Nil(t, recorded.ActorUUID)
+		assert.Equal(t, mockUser.Email, recorded.ActorEmail)
+		assert.Empty(t, recorded.ResourceID)
+	})
+}
+
+func TestAuditFind(t *testing.T) {
+	mockAuditLog := new(mocks.AuditLog)
+	filter := domain.AuditFilter{Action: domain.AuditLogin, Limit: 10}
+	mockEntries := []*domain.AuditEntry{{ID: 1, Action: domain.AuditLogin}}
+
+	t.Run("success", func(t *testing.T) {
+		mockAuditLog.On("Find", mock.Anything, filter).Return(mockEntries, nil).Once()
+
+		entries, err := NewAuditUseCase(mockAuditLog).Find(context.TODO(), filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockEntries, entries)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockAuditLog.On("Verify", mock.Anything).Return(nil, errors.New("Unexpected error")).Once()
+
+		_, err := NewAuditUseCase(mockAuditLog).Verify(context.TODO())
+
+		assert.Error(t, err)
+		mockAuditLog.AssertExpectations(t)
+	})
+}
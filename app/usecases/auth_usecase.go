@@ -3,8 +3,10 @@ package usecase
 import (
 	"context"
 	"hexagony/app/domain"
+	"hexagony/config"
 	"hexagony/libs/crypto"
-	"os"
+	"hexagony/libs/metrics"
+	"hexagony/libs/tracing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -13,24 +15,56 @@ import (
 
 type authUseCase struct {
 	authRepo domain.AuthRepository
+	auditLog domain.AuditLog
+	jwt      config.JWT
 }
 
-func NewAuthUsecase(auth domain.AuthRepository) domain.AuthUseCase {
+func NewAuthUsecase(auth domain.AuthRepository, al domain.AuditLog, jwt config.JWT) domain.AuthUseCase {
 	return &authUseCase{
 		authRepo: auth,
+		auditLog: al,
+		jwt:      jwt,
 	}
 }
 
 func (a *authUseCase) Authenticate(ctx context.Context, email, password string) (*domain.AuthToken, error) {
-	user, err := a.authRepo.Authenticate(ctx, email)
+	ctx, span := tracing.Start(ctx, "authUseCase.Authenticate")
+	defer span.End()
+
+	// the login is not authenticated yet, the actor is who it claims to be
+	entry := domain.AuditEntry{Action: domain.AuditLogin, ResourceType: "user"}
+
+	token, user, err := a.authenticate(ctx, email, password)
 	if err != nil {
+		tracing.Error(span, err)
+		metrics.AuthLogins.WithLabelValues("failure").Inc()
+
+		entry.Outcome = domain.AuditFailure
+		actorCtx := domain.NewActorContext(ctx, domain.Actor{Email: email})
+		auditDetached(actorCtx, a.auditLog, auditEntry(actorCtx, entry))
+
 		return nil, err
 	}
 
+	metrics.AuthLogins.WithLabelValues("success").Inc()
+
+	entry.ResourceID = user.UUID.String()
+	actorCtx := domain.NewActorContext(ctx, domain.Actor{UUID: user.UUID, Email: user.Email})
+	auditDetached(actorCtx, a.auditLog, auditEntry(actorCtx, entry))
+
+	return token, nil
+}
+
+func (a *authUseCase) authenticate(ctx context.Context, email, password string) (*domain.AuthToken, *domain.Users, error) {
+	user, err := a.authRepo.Authenticate(ctx, email)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	bcrypt := crypto.New()
 
 	if match := bcrypt.CheckPasswordHash(password, user.Password); !match {
-		return nil, domain.ErrAuthPassword
+		return nil, nil, domain.ErrAuthPassword
 	}
 
 	customClaims := &domain.Users{
@@ -39,16 +73,16 @@ func (a *authUseCase) Authenticate(ctx context.Context, email, password string)
 		Email: user.Email,
 	}
 
-	tokenExpiration := time.Now().Add(1 * time.Hour) // 1 hour from now
+	tokenExpiration := time.Now().Add(a.jwt.TokenTTL)
 
 	token, err := a.generateToken("user", customClaims, tokenExpiration)
 	if err != nil {
-		return nil, domain.ErrAuth
+		return nil, nil, domain.ErrAuth
 	}
 
 	authToken := domain.AuthToken{Token: token}
 
-	return &authToken, nil
+	return &authToken, user, nil
 }
 
 func (a *authUseCase) generateToken(
@@ -60,7 +94,7 @@ func (a *authUseCase) generateToken(
 		return "", domain.ErrAuthEmptyClaim
 	}
 
-	signingKey := []byte(os.Getenv("JWT_SECRET"))
+	signingKey := []byte(a.jwt.Secret)
 
 	claims := struct {
 		jwt.RegisteredClaims
@@ -5,16 +5,20 @@ import (
 	"errors"
 	"hexagony/app/domain"
 	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"hexagony/libs/metrics"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestAuthenticate(t *testing.T) {
 	mockAuthRepo := new(mocks.AuthRepository)
+	jwtConfig := config.JWT{Secret: "secret", TokenTTL: time.Hour}
 
 	mockUser := &domain.Users{
 		UUID:      uuid.New(),
@@ -27,12 +31,12 @@ func TestAuthenticate(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockAuthRepo.On("Authenticate",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("string")).
 			Return(mockUser, nil).
 			Once()
 
-		a := NewAuthUsecase(mockAuthRepo)
+		a := NewAuthUsecase(mockAuthRepo, newAuditLog(), jwtConfig)
 		_, err := a.Authenticate(context.TODO(), "xorycx@gmail.com", "12345678")
 
 		assert.NoError(t, err)
@@ -42,13 +46,13 @@ func TestAuthenticate(t *testing.T) {
 
 	t.Run("error-failed", func(t *testing.T) {
 		mockAuthRepo.On("Authenticate",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("string"),
 			mock.AnythingOfType("string")).
 			Return(nil, errors.New("Unexpected error")).
 			Once()
 
-		a := NewAuthUsecase(mockAuthRepo)
+		a := NewAuthUsecase(mockAuthRepo, newAuditLog(), jwtConfig)
 		token, err := a.Authenticate(context.TODO(), "xorycx@gmail.com", "12345678")
 
 		assert.Nil(t, token)
@@ -57,3 +61,26 @@ func TestAuthenticate(t *testing.T) {
 		mockAuthRepo.AssertExpectations(t)
 	})
 }
+
+func TestAuthenticateMetrics(t *testing.T) {
+	mockAuthRepo := new(mocks.AuthRepository)
+	jwtConfig := config.JWT{Secret: "secret", TokenTTL: time.Hour}
+
+	success := testutil.ToFloat64(metrics.AuthLogins.WithLabelValues("success"))
+	failure := testutil.ToFloat64(metrics.AuthLogins.WithLabelValues("failure"))
+
+	mockAuthRepo.On("Authenticate",
+		mock.Anything,
+		mock.AnythingOfType("string")).
+		Return(nil, domain.ErrAuthUserNotFound).
+		Once()
+
+	a := NewAuthUsecase(mockAuthRepo, newAuditLog(), jwtConfig)
+	_, err := a.Authenticate(context.TODO(), "xorycx@gmail.com", "12345678")
+
+	assert.ErrorIs(t, err, domain.ErrAuthUserNotFound)
+	assert.Equal(t, success, testutil.ToFloat64(metrics.AuthLogins.WithLabelValues("success")))
+	assert.Equal(t, failure+1, testutil.ToFloat64(metrics.AuthLogins.WithLabelValues("failure")))
+
+	mockAuthRepo.AssertExpectations(t)
+}
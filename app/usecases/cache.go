@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/libs/cache"
+	"hexagony/libs/metrics"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// readThrough caches the resources found by ID. The concurrent misses
+// of an ID share one load, and a load that started before an
+// invalidation is not cached, it may have read the previous state.
+type readThrough[T any] struct {
+	name       string
+	lru        *cache.LRU[uuid.UUID, T]
+	group      singleflight.Group
+	generation atomic.Uint64
+}
+
+func newReadThrough[T any](name string, size int, ttl time.Duration) *readThrough[T] {
+	return &readThrough[T]{name: name, lru: cache.NewLRU[uuid.UUID, T](size, ttl)}
+}
+
+// get returns the cached resource of id or loads it. Every caller
+// gets its own copy, the errors are not cached.
+func (c *readThrough[T]) get(
+	ctx context.Context,
+	id uuid.UUID,
+	load func(context.Context) (*T, error),
+) (*T, error) {
+	if value, ok := c.lru.Get(id); ok {
+		metrics.CacheRequests.WithLabelValues(c.name, "hit").Inc()
+		return &value, nil
+	}
+
+	metrics.CacheRequests.WithLabelValues(c.name, "miss").Inc()
+
+	// the load is shared, it must outlive the request that started it
+	loaded, err, _ := c.group.Do(id.String(), func() (interface{}, error) {
+		generation := c.generation.Load()
+
+		value, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if c.generation.Load() == generation {
+			c.lru.Set(id, *value)
+		}
+
+		return *value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	value := loaded.(T)
+	return &value, nil
+}
+
+// getMany returns the cached resources of ids and loads the others at
+// once, the ids without a resource being left out. The loaded
+// resources are cached under their key.
+func (c *readThrough[T]) getMany(
+	ctx context.Context,
+	ids []uuid.UUID,
+	key func(*T) uuid.UUID,
+	load func(context.Context, []uuid.UUID) ([]*T, error),
+) ([]*T, error) {
+	found := make([]*T, 0, len(ids))
+	var missing []uuid.UUID
+
+	for _, id := range ids {
+		if value, ok := c.lru.Get(id); ok {
+			metrics.CacheRequests.WithLabelValues(c.name, "hit").Inc()
+			found = append(found, &value)
+			continue
+		}
+
+		metrics.CacheRequests.WithLabelValues(c.name, "miss").Inc()
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	generation := c.generation.Load()
+
+	loaded, err := load(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range loaded {
+		if c.generation.Load() == generation {
+			c.lru.Set(key(value), *value)
+		}
+
+		found = append(found, value)
+	}
+
+	return found, nil
+}
+
+// invalidate forgets the resources of ids, once they have been written.
+func (c *readThrough[T]) invalidate(ids ...uuid.UUID) {
+	c.generation.Add(1)
+
+	for _, id := range ids {
+		c.lru.Delete(id)
+		c.group.Forget(id.String())
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAlbumsCache(t *testing.T) {
+	album := &domain.Albums{UUID: uuid.New(), Name: "St. Anger", Length: 75, Version: 1}
+
+	t.Run("hit", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.On("FindByID", mock.Anything, album.UUID).Return(album, nil).Once()
+
+		cached := NewAlbumsCache(mockAlbumUseCase, 10, time.Minute)
+
+		first, err := cached.FindByID(context.TODO(), album.UUID)
+		assert.NoError(t, err)
+
+		// the callers get their own copy
+		first.Name = "Load"
+
+		second, err := cached.FindByID(context.TODO(), album.UUID)
+		assert.NoError(t, err)
+		assert.Equal(t, "St. Anger", second.Name)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.On("FindByID", mock.Anything, album.UUID).Return(nil, domain.ErrResourceNotFound).Twice()
+
+		cached := NewAlbumsCache(mockAlbumUseCase, 10, time.Minute)
+
+		for i := 0; i < 2; i++ {
+			_, err := cached.FindByID(context.TODO(), album.UUID)
+			assert.ErrorIs(t, err, domain.ErrResourceNotFound)
+		}
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("invalidation", func(t *testing.T) {
+		updated := *album
+		updated.Version = 2
+
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.On("FindByID", mock.Anything, album.UUID).Return(album, nil).Once()
+		mockAlbumUseCase.On("Update", mock.Anything, album.UUID, &updated).Return(nil).Once()
+		mockAlbumUseCase.On("FindByID", mock.Anything, album.UUID).Return(&updated, nil).Once()
+
+		cached := NewAlbumsCache(mockAlbumUseCase, 10, time.Minute)
+
+		_, err := cached.FindByID(context.TODO(), album.UUID)
+		assert.NoError(t, err)
+
+		assert.NoError(t, cached.Update(context.TODO(), album.UUID, &updated))
+
+		found, err := cached.FindByID(context.TODO(), album.UUID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, found.Version)
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("many", func(t *testing.T) {
+		other := &domain.Albums{UUID: uuid.New(), Name: "Load", Length: 79, Version: 1}
+		missing := uuid.New()
+
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.On("FindByID", mock.Anything, album.UUID).Return(album, nil).Once()
+		mockAlbumUseCase.On("FindByIDs", mock.Anything, []uuid.UUID{other.UUID, missing}).
+			Return([]*domain.Albums{other}, nil).Once()
+		mockAlbumUseCase.On("FindByIDs", mock.Anything, []uuid.UUID{missing}).Return(nil, nil).Once()
+
+		cached := NewAlbumsCache(mockAlbumUseCase, 10, time.Minute)
+
+		_, err := cached.FindByID(context.TODO(), album.UUID)
+		assert.NoError(t, err)
+
+		// only the albums that are not cached are found
+		found, err := cached.FindByIDs(context.TODO(), []uuid.UUID{album.UUID, other.UUID, missing})
+		assert.NoError(t, err)
+		assert.Len(t, found, 2)
+
+		// the albums found at once are cached as well
+		found, err = cached.FindByIDs(context.TODO(), []uuid.UUID{other.UUID, missing})
+		assert.NoError(t, err)
+		if assert.Len(t, found, 1) {
+			assert.Equal(t, "Load", found[0].Name)
+		}
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+
+	t.Run("concurrent misses", func(t *testing.T) {
+		release := make(chan struct{})
+
+		mockAlbumUseCase := new(mocks.AlbumUseCase)
+		mockAlbumUseCase.
+			On("FindByID", mock.Anything, album.UUID).
+			Run(func(mock.Arguments) { <-release }).
+			Return(album, nil).Once()
+
+		cached := NewAlbumsCache(mockAlbumUseCase, 10, time.Minute)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				found, err := cached.FindByID(context.TODO(), album.UUID)
+				assert.NoError(t, err)
+				assert.Equal(t, album.Name, found.Name)
+			}()
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		mockAlbumUseCase.AssertExpectations(t)
+	})
+}
+
+func TestUsersCache(t *testing.T) {
+	user := &domain.UsersList{UUID: uuid.New(), Name: "John Doe", Email: "john@doe.com"}
+
+	mockUserUseCase := new(mocks.UserUseCase)
+	mockUserUseCase.On("FindByID", mock.Anything, user.UUID).Return(user, nil).Twice()
+	mockUserUseCase.On("Delete", mock.Anything, user.UUID, 0).Return(nil).Once()
+
+	cached := NewUsersCache(mockUserUseCase, 10, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := cached.FindByID(context.TODO(), user.UUID)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, cached.Delete(context.TODO(), user.UUID, 0))
+
+	_, err := cached.FindByID(context.TODO(), user.UUID)
+	assert.NoError(t, err)
+
+	mockUserUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newOutbox returns an outbox running the writes, its events are dropped.
+func newOutbox() *mocks.Outbox {
+	outbox, _ := raisedEvents()
+	return outbox
+}
+
+// raisedEvents returns an outbox keeping the events of the writes that succeed.
+func raisedEvents() (*mocks.Outbox, *[]*domain.Event) {
+	var raised []*domain.Event
+
+	outbox := new(mocks.Outbox)
+	outbox.On("Within", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) ([]*domain.Event, error)) error {
+			events, err := fn(ctx)
+			if err != nil {
+				return err
+			}
+
+			raised = append(raised, events...)
+			return nil
+		}).
+		Maybe()
+
+	return outbox, &raised
+}
+
+func TestAlbumsEvents(t *testing.T) {
+	newUUID := uuid.New()
+	actor := domain.Actor{UUID: uuid.New(), Email: "john@doe.com"}
+
+	ctx := domain.NewActorContext(context.TODO(), actor)
+
+	t.Run("add", func(t *testing.T) {
+		mockAlbumRepo := new(mocks.AlbumRepository)
+		outbox, raised := raisedEvents()
+
+		album := &domain.Albums{UUID: newUUID, Name: "Load", Length: 79}
+		mockAlbumRepo.On("Add", mock.Anything, album).Return(nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), outbox)
+		assert.NoError(t, a.Add(ctx, album))
+
+		if assert.Len(t, *raised, 1) {
+			event := (*raised)[0]
+
+			assert.Equal(t, domain.EventAlbumCreated, event.Type)
+			assert.Equal(t, "album", event.AggregateType)
+			assert.Equal(t, newUUID, event.AggregateID)
+			assert.Equal(t, &actor.UUID, event.ActorUUID)
+
+			var payload domain.Albums
+			assert.NoError(t, json.Unmarshal(event.Payload, &payload))
+			assert.Equal(t, "Load", payload.Name)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		mockAlbumRepo := new(mocks.AlbumRepository)
+		outbox, raised := raisedEvents()
+
+		// the event is built from the written album read back,
+		// not from the request
+		mockAlbumRepo.On("FindByID", mock.Anything, newUUID).
+			Return(&domain.Albums{UUID: newUUID, Name: "Load", Length: 79, Version: 2}, nil).Once()
+		mockAlbumRepo.On("Update", mock.Anything, newUUID, mock.Anything).Return(nil).Once()
+		mockAlbumRepo.On("FindByID", mock.Anything, newUUID).
+			Return(&domain.Albums{UUID: newUUID, Name: "Reload", Length: 76, Barcode: "5099751076421", Version: 3}, nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), outbox)
+		assert.NoError(t, a.Update(ctx, newUUID, &domain.Albums{Name: "Reload", Length: 76}))
+
+		if assert.Len(t, *raised, 1) {
+			var payload domain.Albums
+			assert.NoError(t, json.Unmarshal((*raised)[0].Payload, &payload))
+
+			assert.Equal(t, domain.EventAlbumUpdated, (*raised)[0].Type)
+			assert.Equal(t, "Reload", payload.Name)
+			assert.Equal(t, "5099751076421", payload.Barcode)
+			assert.Equal(t, 3, payload.Version)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockAlbumRepo := new(mocks.AlbumRepository)
+		outbox, raised := raisedEvents()
+
+		mockAlbumRepo.On("FindByID", mock.Anything, newUUID).
+			Return(&domain.Albums{UUID: newUUID, Name: "Load", Version: 2}, nil).Once()
+		mockAlbumRepo.On("Delete", mock.Anything, newUUID, 2).Return(nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), outbox)
+		assert.NoError(t, a.Delete(ctx, newUUID, 2))
+
+		if assert.Len(t, *raised, 1) {
+			var payload domain.Albums
+			assert.NoError(t, json.Unmarshal((*raised)[0].Payload, &payload))
+
+			assert.Equal(t, domain.EventAlbumDeleted, (*raised)[0].Type)
+			assert.NotNil(t, payload.DeletedAt)
+			assert.Equal(t, 3, payload.Version)
+		}
+	})
+
+	t.Run("failed write", func(t *testing.T) {
+		mockAlbumRepo := new(mocks.AlbumRepository)
+		outbox, raised := raisedEvents()
+
+		mockAlbumRepo.On("Restore", mock.Anything, newUUID).Return(domain.ErrResourceNotFound).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), outbox)
+		assert.ErrorIs(t, a.Restore(ctx, newUUID), domain.ErrResourceNotFound)
+		assert.Empty(t, *raised)
+	})
+
+	t.Run("bulk", func(t *testing.T) {
+		mockAlbumRepo := new(mocks.AlbumRepository)
+		outbox, raised := raisedEvents()
+
+		operations := []*domain.AlbumsBulkOperation{
+			{Op: domain.BulkCreate, Album: domain.Albums{UUID: uuid.New(), Name: "Load"}},
+			{Op: domain.BulkUpdate, Album: domain.Albums{UUID: uuid.New()}, Err: domain.ErrResourceNotFound},
+			{Op: domain.BulkDelete, Album: domain.Albums{UUID: uuid.New()}},
+		}
+		mockAlbumRepo.On("Bulk", mock.Anything, operations, false).Return(nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), outbox)
+		assert.NoError(t, a.Bulk(ctx, operations, false))
+
+		if assert.Len(t, *raised, 2) {
+			assert.Equal(t, domain.EventAlbumCreated, (*raised)[0].Type)
+			assert.Equal(t, operations[0].Album.UUID, (*raised)[0].AggregateID)
+			assert.Equal(t, domain.EventAlbumDeleted, (*raised)[1].Type)
+			assert.Equal(t, operations[2].Album.UUID, (*raised)[1].AggregateID)
+		}
+	})
+
+	t.Run("import dry run", func(t *testing.T) {
+		mockAlbumRepo := new(mocks.AlbumRepository)
+		outbox := new(mocks.Outbox)
+
+		rows := []*domain.AlbumsImportRow{{Line: 2, Album: domain.Albums{Name: "Load"}, Outcome: domain.ImportCreated}}
+		mockAlbumRepo.On("Import", mock.Anything, rows, true).Return(nil).Once()
+
+		a := NewAlbumsUseCase(mockAlbumRepo, newAuditLog(), outbox)
+		assert.NoError(t, a.Import(ctx, rows, true))
+
+		outbox.AssertNotCalled(t, "Within", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUsersEvents(t *testing.T) {
+	newUUID := uuid.New()
+
+	t.Run("add", func(t *testing.T) {
+		mockUserRepo := new(mocks.UserRepository)
+		outbox, raised := raisedEvents()
+
+		user := &domain.Users{UUID: newUUID, Name: "John", Email: "john@doe.com", Password: "secret"}
+		mockUserRepo.On("Add", mock.Anything, user).Return(nil).Once()
+
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), outbox)
+		assert.NoError(t, u.Add(context.TODO(), user))
+
+		if assert.Len(t, *raised, 1) {
+			event := (*raised)[0]
+
+			assert.Equal(t, domain.EventUserCreated, event.Type)
+			assert.Equal(t, "user", event.AggregateType)
+			assert.Nil(t, event.ActorUUID)
+			assert.NotContains(t, string(event.Payload), "secret")
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		mockUserRepo := new(mocks.UserRepository)
+		outbox, raised := raisedEvents()
+
+		mockUserRepo.On("Restore", mock.Anything, newUUID).Return(nil).Once()
+		mockUserRepo.On("FindByID", mock.Anything, newUUID).
+			Return(&domain.UsersList{UUID: newUUID, Name: "John", Version: 4}, nil).Once()
+
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), outbox)
+		assert.NoError(t, u.Restore(context.TODO(), newUUID))
+
+		if assert.Len(t, *raised, 1) {
+			var payload domain.UsersList
+			assert.NoError(t, json.Unmarshal((*raised)[0].Payload, &payload))
+
+			assert.Equal(t, domain.EventUserRestored, (*raised)[0].Type)
+			assert.Equal(t, 4, payload.Version)
+		}
+		mockUserRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type jobsUseCase struct {
+	jobsRepository domain.JobsRepository
+	cfg            config.Jobs
+	now            func() time.Time
+}
+
+func NewJobsUseCase(jr domain.JobsRepository, cfg config.Jobs) domain.JobsUseCase {
+	return &jobsUseCase{jobsRepository: jr, cfg: cfg, now: time.Now}
+}
+
+func (s *jobsUseCase) FindAll(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	ctx, span := tracing.Start(ctx, "jobsUseCase.FindAll")
+	defer span.End()
+
+	jobs, err := s.jobsRepository.FindAll(ctx, filter)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (s *jobsUseCase) FindByID(ctx context.Context, uuid uuid.UUID) (*domain.Job, error) {
+	ctx, span := tracing.Start(ctx, "jobsUseCase.FindByID")
+	defer span.End()
+
+	job, err := s.jobsRepository.FindByID(ctx, uuid)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// Enqueue stores the job, due at once unless RunAt is set. A job of a
+// unique key already pending or running is refused as a duplicate.
+func (s *jobsUseCase) Enqueue(ctx context.Context, job *domain.Job) error {
+	ctx, span := tracing.Start(ctx, "jobsUseCase.Enqueue")
+	defer span.End()
+
+	job.SetDefaults(s.now(), s.cfg.MaxAttempts)
+
+	enqueued, err := s.jobsRepository.Enqueue(ctx, job)
+	if err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+
+	if !enqueued {
+		return domain.ErrJobsDuplicate
+	}
+	return nil
+}
+
+func (s *jobsUseCase) Retry(ctx context.Context, uuid uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "jobsUseCase.Retry")
+	defer span.End()
+
+	if err := s.jobsRepository.Retry(ctx, uuid); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
+
+// Cancel stops a pending job from running. A running job is not
+// interrupted, the outcome of its attempt is discarded.
+func (s *jobsUseCase) Cancel(ctx context.Context, uuid uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "jobsUseCase.Cancel")
+	defer span.End()
+
+	if err := s.jobsRepository.Cancel(ctx, uuid); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestJobsEnqueue(t *testing.T) {
+	now := time.Date(2022, 6, 19, 16, 53, 9, 0, time.UTC)
+
+	mockJobRepo := new(mocks.JobRepository)
+	mockJobRepo.On("Enqueue", mock.Anything, mock.Anything).Return(true, nil).Once()
+
+	s := NewJobsUseCase(mockJobRepo, config.Jobs{MaxAttempts: 5}).(*jobsUseCase)
+	s.now = func() time.Time { return now }
+
+	job := &domain.Job{Kind: "albums.export"}
+	assert.NoError(t, s.Enqueue(context.TODO(), job))
+
+	// the missing fields are filled
+	assert.NotEqual(t, uuid.Nil, job.UUID)
+	assert.Equal(t, domain.JobPending, job.Status)
+	assert.Equal(t, 5, job.MaxAttempts)
+	assert.Equal(t, now, job.RunAt)
+	assert.JSONEq(t, "{}", string(job.Payload))
+	mockJobRepo.AssertExpectations(t)
+}
+
+func TestJobsEnqueueDuplicate(t *testing.T) {
+	mockJobRepo := new(mocks.JobRepository)
+	mockJobRepo.On("Enqueue", mock.Anything, mock.Anything).Return(false, nil).Once()
+
+	s := NewJobsUseCase(mockJobRepo, config.Jobs{MaxAttempts: 5})
+
+	err := s.Enqueue(context.TODO(), &domain.Job{Kind: "albums.export", UniqueKey: "albums"})
+	assert.ErrorIs(t, err, domain.ErrJobsDuplicate)
+	mockJobRepo.AssertExpectations(t)
+}
+
+func TestJobsCancel(t *testing.T) {
+	id := uuid.New()
+
+	mockJobRepo := new(mocks.JobRepository)
+	mockJobRepo.On("Cancel", mock.Anything, id).Return(domain.ErrJobsState).Once()
+
+	s := NewJobsUseCase(mockJobRepo, config.Jobs{})
+
+	assert.ErrorIs(t, s.Cancel(context.TODO(), id), domain.ErrJobsState)
+	mockJobRepo.AssertExpectations(t)
+}
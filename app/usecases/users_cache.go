@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/tracing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// usersCache decorates a users use case with a read-through cache of
+// FindByID and FindByIDs, see albumsCache.
+type usersCache struct {
+	domain.UsersUseCase
+	users *readThrough[domain.UsersList]
+}
+
+// NewUsersCache caches up to size users found by ID for ttl.
+func NewUsersCache(next domain.UsersUseCase, size int, ttl time.Duration) domain.UsersUseCase {
+	return &usersCache{UsersUseCase: next, users: newReadThrough[domain.UsersList]("users", size, ttl)}
+}
+
+func (c *usersCache) FindByID(ctx context.Context, uuid uuid.UUID) (*domain.UsersList, error) {
+	ctx, span := tracing.Start(ctx, "usersCache.FindByID")
+	defer span.End()
+
+	user, err := c.users.get(ctx, uuid, func(ctx context.Context) (*domain.UsersList, error) {
+		return c.UsersUseCase.FindByID(ctx, uuid)
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (c *usersCache) FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]*domain.UsersList, error) {
+	ctx, span := tracing.Start(ctx, "usersCache.FindByIDs")
+	defer span.End()
+
+	users, err := c.users.getMany(ctx, uuids, userUUID, c.UsersUseCase.FindByIDs)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func userUUID(user *domain.UsersList) uuid.UUID {
+	return user.UUID
+}
+
+func (c *usersCache) Update(ctx context.Context, uuid uuid.UUID, user *domain.Users) error {
+	defer c.users.invalidate(uuid)
+	return c.UsersUseCase.Update(ctx, uuid, user)
+}
+
+func (c *usersCache) Patch(ctx context.Context, uuid uuid.UUID, patch *domain.UsersPatch) error {
+	defer c.users.invalidate(uuid)
+	return c.UsersUseCase.Patch(ctx, uuid, patch)
+}
+
+func (c *usersCache) Delete(ctx context.Context, uuid uuid.UUID, version int) error {
+	defer c.users.invalidate(uuid)
+	return c.UsersUseCase.Delete(ctx, uuid, version)
+}
+
+func (c *usersCache) Restore(ctx context.Context, uuid uuid.UUID) error {
+	defer c.users.invalidate(uuid)
+	return c.UsersUseCase.Restore(ctx, uuid)
+}
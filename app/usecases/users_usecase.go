@@ -3,52 +3,277 @@ package usecase
 import (
 	"context"
 	"hexagony/app/domain"
+	"hexagony/libs/tracing"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type usersUseCase struct {
 	usersRepository domain.UsersRepository
+	auditLog        domain.AuditLog
+	outbox          domain.Outbox
 }
 
-func NewUserUseCase(ur domain.UsersRepository) domain.UsersUseCase {
-	return &usersUseCase{usersRepository: ur}
+func NewUserUseCase(ur domain.UsersRepository, al domain.AuditLog, ob domain.Outbox) domain.UsersUseCase {
+	return &usersUseCase{usersRepository: ur, auditLog: al, outbox: ob}
+}
+
+// entry returns the audit entry of an action of the user with the changes from before to after.
+func (u *usersUseCase) entry(ctx context.Context, action string, uuid uuid.UUID, before, after *domain.UsersList) *domain.AuditEntry {
+	entry := domain.AuditEntry{Action: action, ResourceType: "user", ResourceID: uuid.String()}
+
+	if before != nil && after != nil {
+		entry.Changes = before.Diff(after)
+	}
+
+	return auditEntry(ctx, entry)
 }
 
 func (u *usersUseCase) FindAll(ctx context.Context) ([]*domain.UsersList, error) {
+	ctx, span := tracing.Start(ctx, "usersUseCase.FindAll")
+	defer span.End()
+
 	user, err := u.usersRepository.FindAll(ctx)
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, err
 	}
 	return user, nil
 }
 
 func (u *usersUseCase) FindByID(ctx context.Context, uuid uuid.UUID) (*domain.UsersList, error) {
+	ctx, span := tracing.Start(ctx, "usersUseCase.FindByID")
+	defer span.End()
+
 	user, err := u.usersRepository.FindByID(ctx, uuid)
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, err
 	}
 
 	return user, nil
 }
 
+func (u *usersUseCase) FindByIDs(ctx context.Context, uuids []uuid.UUID) ([]*domain.UsersList, error) {
+	ctx, span := tracing.Start(ctx, "usersUseCase.FindByIDs")
+	defer span.End()
+
+	users, err := u.usersRepository.FindByIDs(ctx, uuids)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (u *usersUseCase) Add(ctx context.Context, user *domain.Users) error {
-	if err := u.usersRepository.Add(ctx, user); err != nil {
+	ctx, span := tracing.Start(ctx, "usersUseCase.Add")
+	defer span.End()
+
+	// the password is never part of the events, the users
+	// are inserted with the first version
+	created := &domain.UsersList{
+		UUID:      user.UUID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Version:   1,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+
+	err := u.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		if err := u.usersRepository.Add(ctx, user); err != nil {
+			return nil, err
+		}
+
+		entry := u.entry(ctx, domain.AuditCreate, user.UUID, &domain.UsersList{}, &domain.UsersList{
+			UUID:  user.UUID,
+			Name:  user.Name,
+			Email: user.Email,
+		})
+		if err := audit(ctx, u.auditLog, entry); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewUserEvent(ctx, domain.EventUserCreated, created)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, u.auditLog, failed(u.entry(ctx, domain.AuditCreate, user.UUID, nil, nil), err))
 		return err
 	}
+
 	return nil
 }
 
 func (u *usersUseCase) Update(ctx context.Context, uuid uuid.UUID, user *domain.Users) error {
-	if err := u.usersRepository.Update(ctx, uuid, user); err != nil {
+	ctx, span := tracing.Start(ctx, "usersUseCase.Update")
+	defer span.End()
+
+	// the user is locked from its read to the write, the written
+	// one is read back for the event
+	var before, after *domain.UsersList
+	err := u.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if before, err = u.usersRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := u.usersRepository.Update(ctx, uuid, user); err != nil {
+			return nil, err
+		}
+
+		if after, err = u.usersRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, u.auditLog, u.entry(ctx, domain.AuditUpdate, uuid, before, after)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewUserEvent(ctx, domain.EventUserUpdated, after)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, u.auditLog, failed(u.entry(ctx, domain.AuditUpdate, uuid, nil, nil), err))
 		return err
 	}
+
 	return nil
 }
 
-func (u *usersUseCase) Delete(ctx context.Context, uuid uuid.UUID) error {
-	if err := u.usersRepository.Delete(ctx, uuid); err != nil {
+func (u *usersUseCase) Patch(ctx context.Context, uuid uuid.UUID, patch *domain.UsersPatch) error {
+	ctx, span := tracing.Start(ctx, "usersUseCase.Patch")
+	defer span.End()
+
+	var before, after *domain.UsersList
+	err := u.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if before, err = u.usersRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := u.usersRepository.Patch(ctx, uuid, patch); err != nil {
+			return nil, err
+		}
+
+		if after, err = u.usersRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, u.auditLog, u.entry(ctx, domain.AuditPatch, uuid, before, after)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewUserEvent(ctx, domain.EventUserUpdated, after)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, u.auditLog, failed(u.entry(ctx, domain.AuditPatch, uuid, nil, nil), err))
 		return err
 	}
+
 	return nil
 }
+
+func (u *usersUseCase) Delete(ctx context.Context, uuid uuid.UUID, version int) error {
+	ctx, span := tracing.Start(ctx, "usersUseCase.Delete")
+	defer span.End()
+
+	// the deleted user is no longer found, the locked one
+	// is only moved to the trash with the next version
+	var before, after *domain.UsersList
+	err := u.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if before, err = u.usersRepository.FindByID(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		if err := u.usersRepository.Delete(ctx, uuid, version); err != nil {
+			return nil, err
+		}
+
+		deleted := *before
+		deletedAt := time.Now()
+		deleted.DeletedAt, deleted.Version, after = &deletedAt, before.Version+1, &deleted
+
+		if err := audit(ctx, u.auditLog, u.entry(ctx, domain.AuditDelete, uuid, before, after)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewUserEvent(ctx, domain.EventUserDeleted, after)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, u.auditLog, failed(u.entry(ctx, domain.AuditDelete, uuid, nil, nil), err))
+		return err
+	}
+
+	return nil
+}
+
+func (u *usersUseCase) FindTrash(ctx context.Context) ([]*domain.UsersList, error) {
+	ctx, span := tracing.Start(ctx, "usersUseCase.FindTrash")
+	defer span.End()
+
+	trash, err := u.usersRepository.FindTrash(ctx)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return trash, nil
+}
+
+func (u *usersUseCase) Restore(ctx context.Context, uuid uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "usersUseCase.Restore")
+	defer span.End()
+
+	err := u.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		if err := u.usersRepository.Restore(ctx, uuid); err != nil {
+			return nil, err
+		}
+
+		// the restored user is read back in the transaction
+		user, err := u.usersRepository.FindByID(ctx, uuid)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := audit(ctx, u.auditLog, u.entry(ctx, domain.AuditRestore, uuid, nil, nil)); err != nil {
+			return nil, err
+		}
+		return []*domain.Event{domain.NewUserEvent(ctx, domain.EventUserRestored, user)}, nil
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, u.auditLog, failed(u.entry(ctx, domain.AuditRestore, uuid, nil, nil), err))
+		return err
+	}
+
+	return nil
+}
+
+func (u *usersUseCase) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "usersUseCase.Purge")
+	defer span.End()
+
+	entry := domain.AuditEntry{Action: domain.AuditPurge, ResourceType: "user"}
+
+	// the purge raises no event, the outbox only runs it
+	// in a transaction along with its audit entry
+	var purged int64
+	err := u.outbox.Within(ctx, func(ctx context.Context) ([]*domain.Event, error) {
+		var err error
+		if purged, err = u.usersRepository.Purge(ctx, before); err != nil || purged == 0 {
+			return nil, err
+		}
+
+		entry.Changes = []domain.FieldChange{{Field: "purged", From: 0, To: purged}}
+		return nil, audit(ctx, u.auditLog, auditEntry(ctx, entry))
+	})
+	if err != nil {
+		tracing.Error(span, err)
+		auditDetached(ctx, u.auditLog, failed(auditEntry(ctx, entry), err))
+		return 0, err
+	}
+
+	return purged, nil
+}
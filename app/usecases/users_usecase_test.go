@@ -28,10 +28,10 @@ func TestUsersFindAll(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockUserRepo.On("FindAll",
-			mock.AnythingOfType("context.todoCtx")).
+			mock.Anything).
 			Return(mockListUsers, nil).Once()
 
-		a := NewUserUseCase(mockUserRepo)
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		list, err := a.FindAll(context.TODO())
 
 		assert.Equal(t, "Cyro Dubeux", list[0].Name)
@@ -43,10 +43,10 @@ func TestUsersFindAll(t *testing.T) {
 
 	t.Run("error-failed", func(t *testing.T) {
 		mockUserRepo.On("FindAll",
-			mock.AnythingOfType("context.todoCtx")).
+			mock.Anything).
 			Return(nil, errors.New("Unexpected error")).Once()
 
-		a := NewUserUseCase(mockUserRepo)
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		_, err := a.FindAll(context.TODO())
 
 		assert.NotNil(t, err)
@@ -68,11 +68,11 @@ func TestUsersFindByID(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockUserRepo.On("FindByID",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID")).
 			Return(mockUser, nil).Once()
 
-		a := NewUserUseCase(mockUserRepo)
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		user, err := a.FindByID(context.TODO(), newUUID)
 
 		assert.Equal(t, "Cyro Dubeux", user.Name)
@@ -82,11 +82,11 @@ func TestUsersFindByID(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockUserRepo.On("FindByID",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID")).
 			Return(nil, errors.New("Unexpected error")).Once()
 
-		a := NewUserUseCase(mockUserRepo)
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		_, err := a.FindByID(context.TODO(), newUUID)
 
 		assert.NotNil(t, err)
@@ -108,11 +108,11 @@ func TestUsersAdd(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockUserRepo.On("Add",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("*domain.Users")).
 			Return(nil).Once()
 
-		u := NewUserUseCase(mockUserRepo)
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		err := u.Add(context.TODO(), mockUser)
 
 		assert.NoError(t, err)
@@ -121,11 +121,11 @@ func TestUsersAdd(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockUserRepo.On("Add",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("*domain.Users")).
 			Return(errors.New("Unexpected error")).Once()
 
-		u := NewUserUseCase(mockUserRepo)
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		err := u.Add(context.TODO(), mockUser)
 
 		assert.NotNil(t, err)
@@ -137,6 +137,7 @@ func TestUsersAdd(t *testing.T) {
 func TestUsersUpdate(t *testing.T) {
 	newUUID := uuid.New()
 	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.UsersList{UUID: newUUID, Name: "John Doe", Email: "john@doe.com"}, nil)
 	mockUser := &domain.Users{
 		Name:      "Cyro Dubeux",
 		Email:     "xorycx@gmailcom",
@@ -146,12 +147,12 @@ func TestUsersUpdate(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockUserRepo.On("Update",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID"),
 			mock.Anything).
 			Return(nil).Once()
 
-		a := NewUserUseCase(mockUserRepo)
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		err := a.Update(context.TODO(), newUUID, mockUser)
 
 		assert.NoError(t, err)
@@ -160,12 +161,12 @@ func TestUsersUpdate(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockUserRepo.On("Update",
-			mock.AnythingOfType("context.todoCtx"),
+			mock.Anything,
 			mock.AnythingOfType("uuid.UUID"),
 			mock.Anything).
 			Return(errors.New("Unexpected error")).Once()
 
-		a := NewUserUseCase(mockUserRepo)
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
 		err := a.Update(context.TODO(), newUUID, mockUser)
 
 		assert.NotNil(t, err)
@@ -174,18 +175,57 @@ func TestUsersUpdate(t *testing.T) {
 	})
 }
 
+func TestUsersPatch(t *testing.T) {
+	newUUID := uuid.New()
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.UsersList{UUID: newUUID, Name: "John Doe", Email: "john@doe.com"}, nil)
+	email := "xorycx@gmail.com"
+	mockPatch := &domain.UsersPatch{Email: &email, UpdatedAt: time.Now()}
+
+	t.Run("success", func(t *testing.T) {
+		mockUserRepo.On("Patch",
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.Anything).
+			Return(nil).Once()
+
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
+		err := a.Patch(context.TODO(), newUUID, mockPatch)
+
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockUserRepo.On("Patch",
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.Anything).
+			Return(domain.ErrUsersDuplicateEmail).Once()
+
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
+		err := a.Patch(context.TODO(), newUUID, mockPatch)
+
+		assert.ErrorIs(t, err, domain.ErrUsersDuplicateEmail)
+
+		mockUserRepo.AssertExpectations(t)
+	})
+}
+
 func TestUsersDelete(t *testing.T) {
 	newUUID := uuid.New()
 	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.UsersList{UUID: newUUID, Name: "John Doe", Email: "john@doe.com"}, nil)
 
 	t.Run("success", func(t *testing.T) {
 		mockUserRepo.On("Delete",
-			mock.AnythingOfType("context.todoCtx"),
-			mock.AnythingOfType("uuid.UUID")).
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.AnythingOfType("int")).
 			Return(nil).Once()
 
-		u := NewUserUseCase(mockUserRepo)
-		err := u.Delete(context.TODO(), newUUID)
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
+		err := u.Delete(context.TODO(), newUUID, 0)
 
 		assert.NoError(t, err)
 		mockUserRepo.AssertExpectations(t)
@@ -193,15 +233,55 @@ func TestUsersDelete(t *testing.T) {
 
 	t.Run("failure", func(t *testing.T) {
 		mockUserRepo.On("Delete",
-			mock.AnythingOfType("context.todoCtx"),
-			mock.AnythingOfType("uuid.UUID")).
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			mock.AnythingOfType("int")).
 			Return(errors.New("Unexpected error")).Once()
 
-		a := NewUserUseCase(mockUserRepo)
-		err := a.Delete(context.TODO(), newUUID)
+		a := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
+		err := a.Delete(context.TODO(), newUUID, 0)
 
 		assert.NotNil(t, err)
 
 		mockUserRepo.AssertExpectations(t)
 	})
 }
+
+func TestUsersTrash(t *testing.T) {
+	newUUID := uuid.New()
+	mockUserRepo := new(mocks.UserRepository)
+	deletedAt := time.Now()
+	mockTrash := []*domain.UsersList{{UUID: newUUID, Name: "John Doe", Email: "john@doe.com", DeletedAt: &deletedAt}}
+
+	t.Run("find", func(t *testing.T) {
+		mockUserRepo.On("FindTrash", mock.Anything).Return(mockTrash, nil).Once()
+
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
+		trash, err := u.FindTrash(context.TODO())
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockTrash, trash)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		mockUserRepo.On("Restore", mock.Anything, newUUID).Return(nil).Once()
+		mockUserRepo.On("FindByID", mock.Anything, newUUID).Return(&domain.UsersList{UUID: newUUID}, nil).Once()
+
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
+		err := u.Restore(context.TODO(), newUUID)
+
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		mockUserRepo.On("Purge", mock.Anything, deletedAt).Return(int64(0), domain.ErrUsersPurge).Once()
+
+		u := NewUserUseCase(mockUserRepo, newAuditLog(), newOutbox())
+		_, err := u.Purge(context.TODO(), deletedAt)
+
+		assert.ErrorIs(t, err, domain.ErrUsersPurge)
+		mockUserRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/clog"
+	"hexagony/libs/metrics"
+	"hexagony/libs/tracing"
+	"hexagony/libs/webhook"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+type webhooksUseCase struct {
+	webhooksRepository domain.WebhooksRepository
+	client             *webhook.Client
+	cfg                config.Webhooks
+}
+
+func NewWebhooksUseCase(wr domain.WebhooksRepository, cfg config.Webhooks) domain.WebhooksUseCase {
+	return &webhooksUseCase{webhooksRepository: wr, client: webhook.NewClient(cfg.Timeout, cfg.AllowPrivate), cfg: cfg}
+}
+
+func (s *webhooksUseCase) FindAll(ctx context.Context) ([]*domain.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.FindAll")
+	defer span.End()
+
+	webhooks, err := s.webhooksRepository.FindAll(ctx)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (s *webhooksUseCase) FindByID(ctx context.Context, uuid uuid.UUID) (*domain.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.FindByID")
+	defer span.End()
+
+	webhook, err := s.webhooksRepository.FindByID(ctx, uuid)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// Add stores the webhook with a new secret, set on webhook.
+func (s *webhooksUseCase) Add(ctx context.Context, wh *domain.Webhook) error {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.Add")
+	defer span.End()
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		tracing.Error(span, err)
+		return domain.ErrWebhooksSecret
+	}
+	wh.Secret = secret
+
+	if err := s.webhooksRepository.Add(ctx, wh); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
+
+func (s *webhooksUseCase) Update(ctx context.Context, uuid uuid.UUID, webhook *domain.Webhook) error {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.Update")
+	defer span.End()
+
+	if err := s.webhooksRepository.Update(ctx, uuid, webhook); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
+
+func (s *webhooksUseCase) Delete(ctx context.Context, uuid uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.Delete")
+	defer span.End()
+
+	if err := s.webhooksRepository.Delete(ctx, uuid); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
+
+func (s *webhooksUseCase) FindDeliveries(
+	ctx context.Context,
+	webhook uuid.UUID,
+	filter domain.WebhookDeliveryFilter,
+) ([]*domain.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.FindDeliveries")
+	defer span.End()
+
+	// the deliveries of a missing webhook are not found rather than empty
+	if _, err := s.webhooksRepository.FindByID(ctx, webhook); err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	deliveries, err := s.webhooksRepository.FindDeliveries(ctx, webhook, filter)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *webhooksUseCase) FindDelivery(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) (*domain.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.FindDelivery")
+	defer span.End()
+
+	found, err := s.webhooksRepository.FindDelivery(ctx, webhook, delivery)
+	if err != nil {
+		tracing.Error(span, err)
+		return nil, err
+	}
+	return found, nil
+}
+
+func (s *webhooksUseCase) Redeliver(ctx context.Context, webhook uuid.UUID, delivery uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.Redeliver")
+	defer span.End()
+
+	if err := s.webhooksRepository.Redeliver(ctx, webhook, delivery); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
+
+func (s *webhooksUseCase) Enqueue(ctx context.Context, event *domain.Event) error {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.Enqueue")
+	defer span.End()
+
+	if _, err := s.webhooksRepository.Enqueue(ctx, event); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
+
+// Deliver claims a batch of due deliveries and attempts them, Concurrency
+// at once. The claim outlasts the attempts of the whole batch, the
+// deliveries of a replica stopped in between are attempted again once
+// it expires.
+func (s *webhooksUseCase) Deliver(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "webhooksUseCase.Deliver")
+	defer span.End()
+
+	rounds := (s.cfg.BatchSize + s.cfg.Concurrency - 1) / s.cfg.Concurrency
+	lease := time.Duration(rounds+1) * s.cfg.Timeout
+
+	deliveries, err := s.webhooksRepository.Claim(ctx, s.cfg.BatchSize, time.Now().Add(lease))
+	if err != nil {
+		tracing.Error(span, err)
+		return 0, err
+	}
+
+	var group errgroup.Group
+	group.SetLimit(s.cfg.Concurrency)
+
+	for _, delivery := range deliveries {
+		delivery := delivery
+		group.Go(func() error {
+			s.attempt(ctx, delivery)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery and records the outcome, the failed
+// deliveries are retried until they run out of attempts.
+func (s *webhooksUseCase) attempt(ctx context.Context, delivery *domain.WebhookDelivery) {
+	start := time.Now()
+
+	status, err := s.client.Send(ctx, webhook.Request{
+		URL:      delivery.Webhook.URL,
+		Secret:   delivery.Webhook.Secret,
+		Event:    delivery.EventType,
+		Delivery: delivery.UUID.String(),
+		Body:     delivery.Payload,
+	})
+
+	attempt := &domain.WebhookAttempt{
+		StatusCode:  status,
+		Duration:    time.Since(start).Milliseconds(),
+		AttemptedAt: start,
+		Status:      domain.DeliverySucceeded,
+	}
+
+	switch attempts := delivery.Attempts + 1; {
+	case err == nil:
+		metrics.WebhookDeliveries.WithLabelValues("succeeded").Inc()
+	case attempts >= s.cfg.MaxAttempts:
+		attempt.Error, attempt.Status = err.Error(), domain.DeliveryDead
+		metrics.WebhookDeliveries.WithLabelValues("dead").Inc()
+	default:
+		retryAt := start.Add(s.backoff(attempts))
+		attempt.Error, attempt.Status, attempt.NextAttemptAt = err.Error(), domain.DeliveryPending, &retryAt
+		metrics.WebhookDeliveries.WithLabelValues("retried").Inc()
+	}
+
+	if err := s.webhooksRepository.Record(ctx, delivery, attempt); err != nil {
+		clog.ErrorContext(ctx, err, "failed to record the webhook delivery "+delivery.UUID.String())
+	}
+}
+
+// backoff doubles the wait before a retry at each attempt.
+func (s *webhooksUseCase) backoff(attempts int) time.Duration {
+	wait := s.cfg.RetryMin
+	for i := 1; i < attempts && wait < s.cfg.RetryMax; i++ {
+		wait *= 2
+	}
+
+	return min(wait, s.cfg.RetryMax)
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"hexagony/libs/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// the receivers of the tests listen on the loopback
+var webhooksConfig = config.Webhooks{
+	BatchSize:    10,
+	Concurrency:  2,
+	Timeout:      time.Second,
+	MaxAttempts:  3,
+	RetryMin:     10 * time.Second,
+	RetryMax:     time.Minute,
+	AllowPrivate: true,
+}
+
+func TestWebhooksAdd(t *testing.T) {
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockWebhookRepo.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+
+	wh := &domain.Webhook{UUID: uuid.New(), URL: "https://example.com/hook", EventTypes: []string{domain.EventAlbumCreated}}
+
+	s := NewWebhooksUseCase(mockWebhookRepo, webhooksConfig)
+	assert.NoError(t, s.Add(context.TODO(), wh))
+
+	// every webhook gets its own secret
+	assert.Regexp(t, "^whsec_[0-9a-f]{64}$", wh.Secret)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhooksDeliver(t *testing.T) {
+	secret := "whsec_test"
+	event := &domain.Event{ID: uuid.New(), Type: domain.EventAlbumCreated, AggregateType: "album", AggregateID: uuid.New()}
+	payload, _ := json.Marshal(event)
+
+	// the receiver checks the signature and fails the requests of /fail
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		assert.NoError(t, webhook.Verify(secret, r.Header, body, time.Minute, time.Now()))
+		assert.Equal(t, domain.EventAlbumCreated, r.Header.Get(webhook.EventHeader))
+		assert.JSONEq(t, string(payload), string(body))
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	delivery := func(path string, attempts int) *domain.WebhookDelivery {
+		return &domain.WebhookDelivery{
+			UUID:      uuid.New(),
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+			Status:    domain.DeliveryPending,
+			Attempts:  attempts,
+			Webhook:   &domain.Webhook{URL: receiver.URL + path, Secret: secret},
+		}
+	}
+
+	succeeded, retried, dead := delivery("/ok", 0), delivery("/fail", 1), delivery("/fail", 2)
+
+	var (
+		mu       sync.Mutex
+		recorded = map[uuid.UUID]*domain.WebhookAttempt{}
+	)
+
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockWebhookRepo.On("Claim", mock.Anything, 10, mock.Anything).
+		Return([]*domain.WebhookDelivery{succeeded, retried, dead}, nil).Once()
+	mockWebhookRepo.On("Record", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			recorded[args.Get(1).(*domain.WebhookDelivery).UUID] = args.Get(2).(*domain.WebhookAttempt)
+		}).
+		Return(nil).Times(3)
+
+	s := NewWebhooksUseCase(mockWebhookRepo, webhooksConfig)
+	delivered, err := s.Deliver(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+
+	assert.Equal(t, domain.DeliverySucceeded, recorded[succeeded.UUID].Status)
+	assert.Equal(t, http.StatusOK, recorded[succeeded.UUID].StatusCode)
+	assert.Empty(t, recorded[succeeded.UUID].Error)
+
+	// the second attempt waits twice the first retry
+	attempt := recorded[retried.UUID]
+	assert.Equal(t, domain.DeliveryPending, attempt.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, attempt.StatusCode)
+	assert.NotEmpty(t, attempt.Error)
+	if assert.NotNil(t, attempt.NextAttemptAt) {
+		assert.Equal(t, 20*time.Second, attempt.NextAttemptAt.Sub(attempt.AttemptedAt))
+	}
+
+	// the last attempt moves the delivery to the dead letters
+	assert.Equal(t, domain.DeliveryDead, recorded[dead.UUID].Status)
+	assert.Nil(t, recorded[dead.UUID].NextAttemptAt)
+
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhooksBackoff(t *testing.T) {
+	s := &webhooksUseCase{cfg: webhooksConfig}
+
+	assert.Equal(t, 10*time.Second, s.backoff(1))
+	assert.Equal(t, 40*time.Second, s.backoff(3))
+	assert.Equal(t, time.Minute, s.backoff(4))
+	assert.Equal(t, time.Minute, s.backoff(50))
+}
+
+func TestWebhooksFindDeliveries(t *testing.T) {
+	webhookUUID := uuid.New()
+	filter := domain.WebhookDeliveryFilter{Status: domain.DeliveryDead, Limit: 100}
+
+	t.Run("found", func(t *testing.T) {
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("FindByID", mock.Anything, webhookUUID).Return(&domain.Webhook{UUID: webhookUUID}, nil).Once()
+		mockWebhookRepo.On("FindDeliveries", mock.Anything, webhookUUID, filter).
+			Return([]*domain.WebhookDelivery{{UUID: uuid.New()}}, nil).Once()
+
+		s := NewWebhooksUseCase(mockWebhookRepo, webhooksConfig)
+		deliveries, err := s.FindDeliveries(context.TODO(), webhookUUID, filter)
+
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing webhook", func(t *testing.T) {
+		mockWebhookRepo := new(mocks.WebhookRepository)
+		mockWebhookRepo.On("FindByID", mock.Anything, webhookUUID).Return(nil, domain.ErrResourceNotFound).Once()
+
+		s := NewWebhooksUseCase(mockWebhookRepo, webhooksConfig)
+		_, err := s.FindDeliveries(context.TODO(), webhookUUID, filter)
+
+		assert.ErrorIs(t, err, domain.ErrResourceNotFound)
+		mockWebhookRepo.AssertNotCalled(t, "FindDeliveries", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/libs/clog"
+	"time"
+)
+
+// eventListenerRetry is the wait before listening again
+// once the listener failed.
+const eventListenerRetry = 5 * time.Second
+
+// EventListener hands the events published by every replica to the
+// publisher of this one, e.g. for the clients it streams them to.
+type EventListener struct {
+	listener  domain.OutboxListener
+	publisher domain.Publisher
+	retry     time.Duration
+}
+
+// NewEventListener creates a listener from listener to publisher.
+func NewEventListener(listener domain.OutboxListener, publisher domain.Publisher) *EventListener {
+	return &EventListener{listener: listener, publisher: publisher, retry: eventListenerRetry}
+}
+
+// Run listens to the events and blocks until ctx is canceled,
+// listening again after a wait when the listener fails.
+func (w *EventListener) Run(ctx context.Context) {
+	for {
+		err := w.listener.Listen(ctx, w.publish)
+		if ctx.Err() != nil {
+			return
+		}
+
+		clog.Error(err, "failed to listen to the published events")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.retry):
+		}
+	}
+}
+
+// publish hands event to the publisher, the event is not
+// published again when it fails.
+func (w *EventListener) publish(ctx context.Context, event *domain.Event) {
+	if err := w.publisher.Publish(ctx, event); err != nil {
+		clog.ErrorContext(ctx, err, "failed to publish the event "+event.ID.String())
+	}
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEventListener(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := []*domain.Event{
+		{ID: uuid.New(), Sequence: 1, Type: domain.EventAlbumCreated},
+		{ID: uuid.New(), Sequence: 2, Type: domain.EventAlbumUpdated},
+	}
+
+	// the listener fails once, then hands the events until it is canceled
+	listener := new(mocks.OutboxListener)
+	listener.On("Listen", mock.Anything, mock.Anything).Return(errors.New("unexpected error")).Once()
+	listener.On("Listen", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context, *domain.Event)) error {
+			for _, event := range events {
+				fn(ctx, event)
+			}
+			cancel()
+			return nil
+		}).
+		Once()
+
+	publisher := new(mocks.Publisher)
+	publisher.On("Publish", mock.Anything, events[0]).Return(errors.New("unexpected error")).Once()
+	publisher.On("Publish", mock.Anything, events[1]).Return(nil).Once()
+
+	w := NewEventListener(listener, publisher)
+	w.retry = time.Millisecond
+
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the listener did not stop")
+	}
+
+	listener.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
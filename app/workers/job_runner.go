@@ -0,0 +1,266 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/clog"
+	"hexagony/libs/cron"
+	"hexagony/libs/metrics"
+	"sync"
+	"time"
+)
+
+// JobsPurge is the kind of the job removing the jobs finished before
+// the retention, it is scheduled by every runner.
+const JobsPurge = "jobs.purge"
+
+// jobLeaseMargin is added to the timeout of a job to hold its claim,
+// it is only handed out again once its runner is surely gone.
+const jobLeaseMargin = time.Minute
+
+type jobSchedule struct {
+	name string
+	spec *cron.Schedule
+	job  domain.Job
+	due  time.Time
+}
+
+// JobRunner runs the jobs of the queue in a pool of workers, retries
+// the failed ones with a backoff and enqueues the scheduled ones.
+type JobRunner struct {
+	cfg       config.Jobs
+	jobs      domain.JobsRepository
+	handlers  map[string]domain.JobHandler
+	schedules []*jobSchedule
+	now       func() time.Time
+}
+
+// NewJobRunner creates a runner of the jobs stored in jobs.
+func NewJobRunner(cfg config.Jobs, jobs domain.JobsRepository) *JobRunner {
+	w := &JobRunner{
+		cfg:      cfg,
+		jobs:     jobs,
+		handlers: make(map[string]domain.JobHandler),
+		now:      time.Now,
+	}
+
+	w.Handle(JobsPurge, w.purge)
+	if err := w.Schedule(JobsPurge, "@hourly", domain.Job{Kind: JobsPurge}); err != nil {
+		panic(err)
+	}
+
+	return w
+}
+
+// Handle registers the handler of the jobs of kind, before Run.
+func (w *JobRunner) Handle(kind string, handler domain.JobHandler) {
+	w.handlers[kind] = handler
+}
+
+// Schedule enqueues a copy of job at every time matched by the cron
+// expression spec, before Run. A run is skipped while the previous
+// one is pending or running, unless job has its own unique key.
+func (w *JobRunner) Schedule(name, spec string, job domain.Job) error {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	if job.UniqueKey == "" {
+		job.UniqueKey = "schedule:" + name
+	}
+
+	w.schedules = append(w.schedules, &jobSchedule{
+		name: name,
+		spec: schedule,
+		job:  job,
+		due:  schedule.Next(w.now()),
+	})
+
+	return nil
+}
+
+// Run runs the jobs and blocks until ctx is canceled. It polls the
+// queue at every interval, at once while the pool is kept busy. Once
+// ctx is canceled, the running jobs are given the shutdown timeout to
+// finish before they are canceled and handed back to the queue.
+func (w *JobRunner) Run(ctx context.Context) {
+	jobsCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	pool := make(chan struct{}, w.cfg.Concurrency)
+	var running sync.WaitGroup
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.drain(&running, cancelJobs)
+			return
+		case <-timer.C:
+		}
+
+		wait := w.cfg.PollInterval
+
+		w.enqueueScheduled(ctx)
+
+		if idle := cap(pool) - len(pool); idle > 0 {
+			jobs, err := w.jobs.Claim(ctx, idle, w.now().Add(w.cfg.Timeout+jobLeaseMargin))
+			if err != nil {
+				clog.Error(err, "failed to claim the jobs")
+			} else if len(jobs) == idle {
+				wait = 0
+			}
+
+			for _, job := range jobs {
+				pool <- struct{}{}
+				running.Add(1)
+
+				go func(job *domain.Job) {
+					defer func() {
+						<-pool
+						running.Done()
+					}()
+
+					w.run(jobsCtx, job)
+				}(job)
+			}
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+// drain waits for the running jobs, they are canceled
+// if they have not finished within the shutdown timeout.
+func (w *JobRunner) drain(running *sync.WaitGroup, cancel context.CancelFunc) {
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(w.cfg.ShutdownTimeout):
+		clog.Warn("canceling the jobs still running")
+		cancel()
+		<-done
+	}
+}
+
+// run runs job and records the outcome of its attempt: succeeded,
+// retried after a backoff or failed once out of attempts. A job
+// canceled by the shutdown is handed back without losing an attempt.
+func (w *JobRunner) run(ctx context.Context, job *domain.Job) {
+	err := w.handle(ctx, job)
+	now := w.now()
+
+	var result string
+	switch {
+	case err == nil:
+		result = "succeeded"
+		job.Status = domain.JobSucceeded
+		job.LastError = ""
+		job.FinishedAt = &now
+	case ctx.Err() != nil:
+		result = "interrupted"
+		job.Status = domain.JobPending
+		job.Attempts--
+		job.RunAt = now
+	case job.Attempts >= job.MaxAttempts:
+		result = "failed"
+		job.Status = domain.JobFailed
+		job.LastError = err.Error()
+		job.FinishedAt = &now
+	default:
+		result = "retried"
+		job.Status = domain.JobPending
+		job.LastError = err.Error()
+		job.RunAt = now.Add(w.backoff(job.Attempts))
+	}
+
+	metrics.Jobs.WithLabelValues(job.Kind, result).Inc()
+
+	if err != nil {
+		clog.ErrorContext(ctx, err, fmt.Sprintf("the job %s of kind %s %s", job.UUID, job.Kind, result))
+	}
+
+	if err := w.jobs.Record(context.WithoutCancel(ctx), job); err != nil {
+		clog.Error(err, "failed to record the job "+job.UUID.String())
+	}
+}
+
+// handle runs the handler of the kind of job within
+// the timeout, a panic is reported as an error.
+func (w *JobRunner) handle(ctx context.Context, job *domain.Job) (err error) {
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for the jobs of kind %q", job.Kind)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("the job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
+
+// enqueueScheduled enqueues the jobs of the due schedules, a schedule
+// whose job could not be enqueued is tried again at the next poll.
+func (w *JobRunner) enqueueScheduled(ctx context.Context) {
+	now := w.now()
+
+	for _, s := range w.schedules {
+		if s.due.IsZero() || now.Before(s.due) {
+			continue
+		}
+
+		job := s.job
+		job.SetDefaults(now, w.cfg.MaxAttempts)
+
+		next := s.spec.Next(now)
+		if _, err := w.jobs.Schedule(ctx, s.name, s.due, next, &job); err != nil {
+			clog.Error(err, "failed to enqueue the scheduled job "+s.name)
+			continue
+		}
+
+		s.due = next
+	}
+}
+
+// backoff doubles the wait before a retry at each attempt.
+func (w *JobRunner) backoff(attempts int) time.Duration {
+	wait := w.cfg.RetryMin
+	for i := 1; i < attempts && wait < w.cfg.RetryMax; i++ {
+		wait *= 2
+	}
+
+	return min(wait, w.cfg.RetryMax)
+}
+
+// purge removes the jobs finished before the retention.
+func (w *JobRunner) purge(ctx context.Context, _ *domain.Job) error {
+	purged, err := w.jobs.Purge(ctx, w.now().Add(-w.cfg.Retention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		clog.Custom(map[string]interface{}{
+			"message": "purged the finished jobs",
+			"purged":  purged,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var jobsConfig = config.Jobs{
+	PollInterval:    10 * time.Millisecond,
+	Concurrency:     2,
+	Timeout:         time.Second,
+	MaxAttempts:     3,
+	RetryMin:        10 * time.Second,
+	RetryMax:        time.Minute,
+	Retention:       7 * 24 * time.Hour,
+	ShutdownTimeout: 10 * time.Millisecond,
+}
+
+func TestJobRunnerOutcomes(t *testing.T) {
+	now := time.Date(2022, 6, 19, 16, 53, 9, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		kind     string
+		attempts int
+		status   string
+		runAt    time.Time
+		failed   bool
+	}{
+		"succeeded": {kind: "ok", attempts: 1, status: domain.JobSucceeded, runAt: now.Add(-time.Hour)},
+		"retried":   {kind: "fail", attempts: 2, status: domain.JobPending, runAt: now.Add(20 * time.Second), failed: true},
+		"failed":    {kind: "fail", attempts: 3, status: domain.JobFailed, runAt: now.Add(-time.Hour), failed: true},
+		"panicked":  {kind: "panic", attempts: 1, status: domain.JobPending, runAt: now.Add(10 * time.Second), failed: true},
+		"unknown":   {kind: "unknown", attempts: 3, status: domain.JobFailed, runAt: now.Add(-time.Hour), failed: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			job := &domain.Job{UUID: uuid.New(), Kind: tc.kind, Attempts: tc.attempts, MaxAttempts: 3, RunAt: now.Add(-time.Hour)}
+
+			jobs := new(mocks.JobRepository)
+			jobs.On("Record", mock.Anything, job).Return(nil).Once()
+
+			w := NewJobRunner(jobsConfig, jobs)
+			w.now = func() time.Time { return now }
+			w.Handle("ok", func(context.Context, *domain.Job) error { return nil })
+			w.Handle("fail", func(context.Context, *domain.Job) error { return errors.New("unexpected error") })
+			w.Handle("panic", func(context.Context, *domain.Job) error { panic("unexpected panic") })
+
+			w.run(context.Background(), job)
+
+			assert.Equal(t, tc.status, job.Status)
+			assert.Equal(t, tc.attempts, job.Attempts)
+			assert.Equal(t, tc.runAt, job.RunAt)
+			assert.Equal(t, tc.failed, job.LastError != "")
+			assert.Equal(t, tc.status != domain.JobPending, job.FinishedAt != nil)
+			jobs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestJobRunnerBackoff(t *testing.T) {
+	w := NewJobRunner(config.Jobs{RetryMin: time.Second, RetryMax: time.Minute}, nil)
+
+	for attempts, wait := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		6:  32 * time.Second,
+		7:  time.Minute,
+		40: time.Minute,
+	} {
+		assert.Equal(t, wait, w.backoff(attempts), "attempt %d", attempts)
+	}
+}
+
+func TestJobRunnerSchedule(t *testing.T) {
+	now := time.Date(2022, 6, 19, 16, 53, 9, 0, time.UTC)
+	due := time.Date(2022, 6, 19, 17, 0, 0, 0, time.UTC)
+
+	jobs := new(mocks.JobRepository)
+	jobs.On("Schedule", mock.Anything, "albums.export", due, due.Add(24*time.Hour), mock.Anything).
+		Return(false, errors.New("unexpected error")).
+		Once()
+	jobs.On("Schedule", mock.Anything, "albums.export", due, due.Add(24*time.Hour), mock.Anything).
+		Return(true, nil).
+		Once()
+
+	w := NewJobRunner(jobsConfig, nil)
+	w.jobs = jobs
+	w.now = func() time.Time { return now }
+	w.schedules = nil
+
+	assert.ErrorContains(t, w.Schedule("albums.export", "0 17 * *", domain.Job{Kind: "albums.export"}), "invalid cron expression")
+	assert.NoError(t, w.Schedule("albums.export", "0 17 * * *", domain.Job{Kind: "albums.export"}))
+
+	// not due yet
+	w.enqueueScheduled(context.Background())
+
+	// the failed enqueue is tried again at the next poll
+	now = due.Add(time.Second)
+	w.enqueueScheduled(context.Background())
+	w.enqueueScheduled(context.Background())
+	w.enqueueScheduled(context.Background())
+
+	jobs.AssertExpectations(t)
+
+	job := jobs.Calls[1].Arguments.Get(4).(*domain.Job)
+	assert.Equal(t, "schedule:albums.export", job.UniqueKey)
+	assert.Equal(t, domain.JobPending, job.Status)
+	assert.Equal(t, 3, job.MaxAttempts)
+}
+
+func TestJobRunnerShutdown(t *testing.T) {
+	job := &domain.Job{UUID: uuid.New(), Kind: "slow", Attempts: 1, MaxAttempts: 3}
+
+	jobs := new(mocks.JobRepository)
+	jobs.On("Claim", mock.Anything, 2, mock.Anything).Return([]*domain.Job{job}, nil).Once()
+	jobs.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	jobs.On("Record", mock.Anything, job).Return(nil).Once()
+
+	started := make(chan struct{})
+
+	w := NewJobRunner(jobsConfig, jobs)
+	w.Handle("slow", func(ctx context.Context, _ *domain.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the runner did not stop")
+	}
+
+	// the interrupted job is handed back without losing its attempt
+	assert.Equal(t, domain.JobPending, job.Status)
+	assert.Equal(t, 0, job.Attempts)
+	assert.Empty(t, job.LastError)
+	jobs.AssertExpectations(t)
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/clog"
+	"hexagony/libs/metrics"
+	"time"
+)
+
+// outboxPurgeInterval is the least time between two
+// removals of the events published before the retention.
+const outboxPurgeInterval = time.Hour
+
+// OutboxRelay publishes the events stored in the outbox, in order
+// for each aggregate, and retries the failed ones with a backoff.
+type OutboxRelay struct {
+	cfg       config.Outbox
+	outbox    domain.Outbox
+	publisher domain.Publisher
+	now       func() time.Time
+	lastPurge time.Time
+}
+
+// NewOutboxRelay creates a relay from outbox to publisher.
+func NewOutboxRelay(cfg config.Outbox, outbox domain.Outbox, publisher domain.Publisher) *OutboxRelay {
+	return &OutboxRelay{cfg: cfg, outbox: outbox, publisher: publisher, now: time.Now}
+}
+
+// Run relays the events and blocks until ctx is canceled. It polls
+// the outbox at every interval, at once while it is not drained.
+func (w *OutboxRelay) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		wait := w.cfg.PollInterval
+
+		dispatched, err := w.Relay(ctx)
+		if err != nil {
+			clog.Error(err, "failed to relay the outbox events")
+		} else if dispatched == w.cfg.BatchSize {
+			wait = 0
+		}
+
+		w.purge(ctx)
+
+		timer.Reset(wait)
+	}
+}
+
+// Relay publishes a batch of events and returns how many were dispatched.
+func (w *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	return w.outbox.Dispatch(ctx, w.cfg.BatchSize, w.backoff, func(ctx context.Context, event *domain.Event) error {
+		if err := w.publisher.Publish(ctx, event); err != nil {
+			metrics.OutboxEvents.WithLabelValues("failed").Inc()
+			clog.ErrorContext(ctx, err, "failed to publish the event "+event.ID.String())
+			return err
+		}
+
+		metrics.OutboxEvents.WithLabelValues("published").Inc()
+		return nil
+	})
+}
+
+// backoff doubles the wait before a retry at each attempt.
+func (w *OutboxRelay) backoff(attempts int) time.Duration {
+	wait := w.cfg.RetryMin
+	for i := 1; i < attempts && wait < w.cfg.RetryMax; i++ {
+		wait *= 2
+	}
+
+	return min(wait, w.cfg.RetryMax)
+}
+
+// purge removes the events published before the retention, at most once per interval.
+func (w *OutboxRelay) purge(ctx context.Context) {
+	now := w.now()
+	if now.Sub(w.lastPurge) < outboxPurgeInterval {
+		return
+	}
+	w.lastPurge = now
+
+	purged, err := w.outbox.Purge(ctx, now.Add(-w.cfg.Retention))
+	if err != nil {
+		clog.Error(err, "failed to purge the published events")
+		return
+	}
+
+	if purged > 0 {
+		clog.Custom(map[string]interface{}{
+			"message": "purged the published events",
+			"purged":  purged,
+		})
+	}
+}
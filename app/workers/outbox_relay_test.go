@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRelay(t *testing.T) {
+	events := []*domain.Event{
+		{ID: uuid.New(), Type: domain.EventAlbumCreated},
+		{ID: uuid.New(), Type: domain.EventUserDeleted},
+	}
+
+	// the outbox reports which events were published
+	var failed []error
+	outbox := new(mocks.Outbox)
+	outbox.On("Dispatch", mock.Anything, 100, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, limit int, _ func(int) time.Duration, fn func(context.Context, *domain.Event) error) int {
+			for _, event := range events {
+				failed = append(failed, fn(ctx, event))
+			}
+			return len(events)
+		}, nil).
+		Once()
+
+	publisher := new(mocks.Publisher)
+	publisher.On("Publish", mock.Anything, events[0]).Return(nil).Once()
+	publisher.On("Publish", mock.Anything, events[1]).Return(errors.New("unexpected error")).Once()
+
+	w := NewOutboxRelay(config.Outbox{BatchSize: 100}, outbox, publisher)
+	dispatched, err := w.Relay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dispatched)
+	assert.NoError(t, failed[0])
+	assert.Error(t, failed[1])
+
+	outbox.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
+
+func TestRelayBackoff(t *testing.T) {
+	w := NewOutboxRelay(config.Outbox{RetryMin: time.Second, RetryMax: time.Minute}, nil, nil)
+
+	for attempts, wait := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		6:  32 * time.Second,
+		7:  time.Minute,
+		40: time.Minute,
+	} {
+		assert.Equal(t, wait, w.backoff(attempts), "attempt %d", attempts)
+	}
+}
+
+func TestRelayPurge(t *testing.T) {
+	now := time.Date(2022, 6, 19, 16, 53, 9, 0, time.UTC)
+
+	outbox := new(mocks.Outbox)
+	outbox.On("Purge", mock.Anything, now.Add(-7*24*time.Hour)).Return(int64(3), nil).Once()
+
+	w := NewOutboxRelay(config.Outbox{Retention: 7 * 24 * time.Hour}, outbox, nil)
+	w.now = func() time.Time { return now }
+
+	// purged at most once per interval
+	w.purge(context.Background())
+	w.purge(context.Background())
+
+	outbox.AssertExpectations(t)
+}
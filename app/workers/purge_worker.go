@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"hexagony/config"
+	"hexagony/libs/clog"
+	"time"
+)
+
+// Purger permanently removes the rows deleted before a given time.
+type Purger interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type purger struct {
+	name string
+	Purger
+}
+
+// PurgeWorker empties the trash of the rows deleted
+// longer ago than the retention period.
+type PurgeWorker struct {
+	cfg     config.Trash
+	purgers []purger
+	now     func() time.Time
+}
+
+// NewPurgeWorker creates a purge worker without purgers.
+func NewPurgeWorker(cfg config.Trash) *PurgeWorker {
+	return &PurgeWorker{cfg: cfg, now: time.Now}
+}
+
+// Register adds a purger, the name is used in the logs.
+func (w *PurgeWorker) Register(name string, p Purger) {
+	w.purgers = append(w.purgers, purger{name, p})
+}
+
+// Run purges the trash at every interval and blocks until ctx is
+// canceled. It returns at once when the retention is zero.
+func (w *PurgeWorker) Run(ctx context.Context) {
+	if w.cfg.Retention == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		w.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge runs every purger once. A failing purger
+// is logged and does not stop the others.
+func (w *PurgeWorker) Purge(ctx context.Context) {
+	before := w.now().Add(-w.cfg.Retention)
+
+	for _, p := range w.purgers {
+		purged, err := p.Purge(ctx, before)
+		if err != nil {
+			clog.Error(err, "failed to purge the deleted "+p.name)
+			continue
+		}
+
+		if purged > 0 {
+			clog.Custom(map[string]interface{}{
+				"message":  "purged the deleted " + p.name,
+				"resource": p.name,
+				"purged":   purged,
+				"before":   before,
+			})
+		}
+	}
+}
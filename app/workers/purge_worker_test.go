@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"hexagony/app/domain/mocks"
+	"hexagony/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPurge(t *testing.T) {
+	now := time.Date(2022, 6, 19, 16, 53, 9, 0, time.UTC)
+	before := now.Add(-30 * 24 * time.Hour)
+
+	albums := new(mocks.AlbumUseCase)
+	albums.On("Purge", mock.Anything, before).Return(int64(0), errors.New("unexpected error"))
+
+	users := new(mocks.UserUseCase)
+	users.On("Purge", mock.Anything, before).Return(int64(2), nil)
+
+	w := NewPurgeWorker(config.Trash{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour})
+	w.now = func() time.Time { return now }
+	w.Register("albums", albums)
+	w.Register("users", users)
+
+	// a failing purger does not stop the others
+	w.Purge(context.Background())
+
+	albums.AssertExpectations(t)
+	users.AssertExpectations(t)
+}
+
+func TestRunDisabled(t *testing.T) {
+	albums := new(mocks.AlbumUseCase)
+
+	w := NewPurgeWorker(config.Trash{})
+	w.Register("albums", albums)
+
+	// returns at once without purging
+	w.Run(context.Background())
+
+	albums.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/clog"
+	"time"
+)
+
+// WebhookDispatcher attempts the due webhook deliveries.
+type WebhookDispatcher struct {
+	cfg      config.Webhooks
+	webhooks domain.WebhooksUseCase
+}
+
+// NewWebhookDispatcher creates a dispatcher of the deliveries of webhooks.
+func NewWebhookDispatcher(cfg config.Webhooks, webhooks domain.WebhooksUseCase) *WebhookDispatcher {
+	return &WebhookDispatcher{cfg: cfg, webhooks: webhooks}
+}
+
+// Run dispatches the deliveries and blocks until ctx is canceled. It
+// polls at every interval, at once while full batches are claimed.
+func (w *WebhookDispatcher) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		wait := w.cfg.PollInterval
+
+		delivered, err := w.webhooks.Deliver(ctx)
+		if err != nil {
+			clog.Error(err, "failed to deliver the webhooks")
+		} else if delivered == w.cfg.BatchSize {
+			wait = 0
+		}
+
+		timer.Reset(wait)
+	}
+}
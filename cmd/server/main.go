@@ -2,24 +2,39 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"time"
+	"flag"
+	"sync"
+	"syscall"
 
+	"hexagony/app/domain"
+	"hexagony/config"
+	"hexagony/libs/clientip"
 	"hexagony/libs/clog"
+	"hexagony/libs/health"
+	"hexagony/libs/metrics"
+	"hexagony/libs/ratelimit"
 	"hexagony/libs/rest"
+	"hexagony/libs/tracing"
+	"hexagony/libs/validation"
 	"hexagony/routes"
 
+	publisher "hexagony/app/publishers"
 	repository "hexagony/app/repositories"
+	"hexagony/app/repositories/memory"
 	usecase "hexagony/app/usecases"
+	worker "hexagony/app/workers"
 
 	"net/http"
 	"os"
 	"os/signal"
 
+	grpcserver "hexagony/app/grpc/server"
+	controller "hexagony/app/http/controllers"
 	cmiddleware "hexagony/app/http/middleware"
+	"hexagony/app/http/response"
+	"hexagony/app/http/server"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/render"
 	"github.com/jmoiron/sqlx"
@@ -30,11 +45,6 @@ import (
 	_ "github.com/lib/pq"
 )
 
-const (
-	development = "development"
-	production  = "production"
-)
-
 // @title        Hexagony API
 // @version      1.0
 // @description  Clean architecture example in Golang.
@@ -48,119 +58,364 @@ const (
 
 // @host  http://localhost:8000
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// the context is canceled on SIGINT (local runs) and SIGTERM (containers)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	envMode := os.Getenv("ENV_MODE")
+	configFile := flag.String("config", "", "path to an optional YAML or TOML config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		clog.Fatal("invalid configuration:\n" + err.Error())
+	}
 
-	if envMode == development {
+	if err := clog.SetLevel(cfg.Log.Level); err != nil {
+		clog.Fatal("invalid log level: " + err.Error())
+	}
+	clog.SetSampling(cfg.Log.Sampling)
+
+	if cfg.IsDevelopment() {
 		clog.UseConsoleOutput()
 		clog.Debug("running in development mode")
+		clog.Debug("loaded configuration:\n" + cfg.String())
 	} else {
 		clog.Info("running in production mode")
 	}
 
-	// postgres url string
-	databaseURL := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("POSTGRES_HOST"), os.Getenv("POSTGRES_PORT"), os.Getenv("POSTGRES_USER"),
-		os.Getenv("POSTGRES_PASSWORD"), os.Getenv("POSTGRES_DB"),
-	)
-
-	if envMode == production { // get url from heroku if production
-		databaseURL = os.Getenv("DATABASE_URL")
+	// tracing exporter and W3C trace context propagation
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		clog.Fatal("tracing failed to start: " + err.Error())
 	}
 
 	// connecting to postgres
-	conn, err := sqlx.ConnectContext(ctx, "postgres", databaseURL)
+	conn, err := sqlx.ConnectContext(ctx, "postgres", cfg.Postgres.DSN())
 	if err != nil {
 		clog.Fatal("postgres failed to start:" + err.Error())
 	}
-	defer conn.Close()
 
 	if err := conn.PingContext(ctx); err != nil {
 		clog.Fatal("could not ping postgres database")
 	}
 
+	// X-Forwarded-For is only honoured when sent by these proxies
+	proxies, err := clientip.ParseProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		clog.Fatal("invalid trusted proxies: " + err.Error())
+	}
+
 	router := chi.NewRouter()
 
 	// enabling CORS
 	cors := cors.New(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins:   cfg.Server.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", "X-Request-ID"},
+		ExposedHeaders:   []string{"ETag", "Idempotent-Replayed", "Link", "RateLimit-Limit", "RateLimit-Policy", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID"},
 		AllowCredentials: true,
 		MaxAge:           300,
-		Debug:            envMode == development,
+		Debug:            cfg.IsDevelopment(),
 	})
 
 	// middlewares
 	router.Use(
-		middleware.Recoverer,
+		cmiddleware.MetricsMiddleware,
+		cmiddleware.TracingMiddleware,
+		cmiddleware.RequestIDMiddleware,
+		cmiddleware.ClientIPMiddleware(proxies),
 		cmiddleware.LoggerMiddleware,
+		cmiddleware.RecovererMiddleware,
 		render.SetContentType(render.ContentTypeJSON),
 		cors.Handler,
 	)
 
+	// errors raised by the router itself
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		response.StatusProblem(w, r, http.StatusNotFound, "route_not_found")
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		response.StatusProblem(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+	})
+
 	// root page
 	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		rest.JSON(w, http.StatusOK, rest.Message{Message: "Welcome to Hexagony API"})
 	})
 
+	// liveness and readiness probes
+	probes := health.New(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	probes.Register("postgres", health.CheckerFunc(conn.PingContext))
+
+	router.Get("/healthz", probes.Liveness)
+	router.Get("/readyz", probes.Readiness)
+
+	// prometheus metrics, served by the admin listener when configured
+	metrics.RegisterDB(conn.DB, "hexagony")
+
+	adminRouter := router
+	if cfg.Admin.Port != "" {
+		adminRouter = chi.NewRouter()
+	}
+
+	adminRouter.Handle("/metrics", metrics.Handler())
+
+	// runtime log level and sampling, changed by the administrators
+	adminRouter.With(cmiddleware.AuthMiddleware(cfg.JWT), cmiddleware.AdminMiddleware(cfg.Admin)).Handle("/admin/log", clog.Handler())
+
 	// swagger documentation end-point
 	router.Get("/docs/*", httpSwagger.WrapHandler)
 
 	// domain instances
+	auditLog := repository.NewAuditLogRepository(conn)
+	auditUseCase := usecase.NewAuditUseCase(auditLog)
+
+	// events of the albums and users, stored with their changes
+	outbox := repository.NewOutboxRepository(conn)
+
 	usersRepository := repository.NewUsersRepository(conn)
-	usersUseCase := usecase.NewUserUseCase(usersRepository)
+	usersUseCase := usecase.NewUserUseCase(usersRepository, auditLog, outbox)
 
 	albumsRepository := repository.NewAlbumsRepository(conn)
-	albumsUseCase := usecase.NewAlbumsUseCase(albumsRepository)
+	albumsUseCase := usecase.NewAlbumsUseCase(albumsRepository, auditLog, outbox)
+
+	// the albums and users found by ID are cached in front of the use cases
+	if cfg.Cache.Enabled {
+		albumsUseCase = usecase.NewAlbumsCache(albumsUseCase, cfg.Cache.Size, cfg.Cache.TTL)
+		usersUseCase = usecase.NewUsersCache(usersUseCase, cfg.Cache.Size, cfg.Cache.TTL)
+	}
+
+	webhooksRepository := repository.NewWebhooksRepository(conn)
+	webhooksUseCase := usecase.NewWebhooksUseCase(webhooksRepository, cfg.Webhooks)
+
+	jobsRepository := repository.NewJobsRepository(conn)
+	jobsUseCase := usecase.NewJobsUseCase(jobsRepository, cfg.Jobs)
 
 	authRepository := repository.NewAuthRepository(conn)
-	authUseCase := usecase.NewAuthUsecase(authRepository)
+	authUseCase := usecase.NewAuthUsecase(authRepository, auditLog, cfg.JWT)
+
+	// responses replayed for the retried POST requests
+	idempotency := memory.NewIdempotencyStore()
+	if cfg.Idempotency.Store == "postgres" {
+		idempotency = repository.NewIdempotencyRepository(conn)
+	}
+
+	// rate limits of the route groups
+	rateLimitStore := ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "postgres" {
+		rateLimitStore = repository.NewRateLimitRepository(conn)
+	}
+
+	// events relayed from the outbox to the subscribers of the bus
+	bus := publisher.NewBus()
+	bus.Subscribe(webhooksUseCase.Enqueue)
+
+	// events published by any instance, listened to by every instance
+	published := publisher.NewBus()
+
+	// the last album events, streamed to the clients
+	albumsFeed := publisher.NewFeed("album", cfg.Stream.Buffer)
+	published.Subscribe(albumsFeed.Publish)
+
+	// the editors of the albums, notified of the changes made through
+	// any instance. They are kept in memory, served by a single instance.
+	var albumsPresence *publisher.Hub
+	var presenceLock domain.InstanceLock
+	if cfg.Presence.Enabled {
+		presenceLock = repository.NewInstanceLock(conn, "presence")
+		if err := presenceLock.Acquire(ctx); err != nil {
+			clog.Fatal("presence failed to start, it is served by another instance unless PRESENCE_ENABLED=false: " + err.Error())
+		}
+
+		albumsPresence = publisher.NewHub(cfg.Presence.LockTTL)
+		published.Subscribe(albumsPresence.Publish)
+	}
 
 	rs := &routes.RoutesUseCases{
-		AuthUseCase:   authUseCase,
-		UsersUseCase:  usersUseCase,
-		AlbumsUseCase: albumsUseCase,
+		AuthUseCase:     authUseCase,
+		UsersUseCase:    usersUseCase,
+		AlbumsUseCase:   albumsUseCase,
+		AuditUseCase:    auditUseCase,
+		WebhooksUseCase: webhooksUseCase,
+		JobsUseCase:     jobsUseCase,
+		Idempotency:     idempotency,
+		RateLimiter:     ratelimit.New(rateLimitStore),
+		AlbumsFeed:      albumsFeed,
+	}
+	if albumsPresence != nil {
+		rs.AlbumsPresence = albumsPresence
+	}
+
+	// request validation, shared by every controller
+	validator, err := validation.New(controller.ValidationRules...)
+	if err != nil {
+		clog.Fatal("validator failed to start: " + err.Error())
 	}
 
 	// api routes
-	routes.Api(router, rs)
+	routes.Api(router, rs, validator, cfg)
+
+	// audit log and jobs, served by the admin listener when configured
+	routes.Admin(adminRouter, rs, validator, cfg)
 
-	// server configuration and timeouts
-	srv := &http.Server{
-		Addr:              ":" + os.Getenv("PORT"),
-		ReadTimeout:       time.Duration(time.Second * 5),
-		ReadHeaderTimeout: time.Duration(time.Second * 5),
-		WriteTimeout:      time.Duration(time.Second * 5),
-		IdleTimeout:       time.Duration(time.Second * 20),
-		Handler:           router,
+	// server configuration, timeouts and TLS
+	srv, err := server.New(cfg.Server, router)
+	if err != nil {
+		clog.Fatal("server failed to configure: " + err.Error())
 	}
 
-	// graceful shutdown
-	idleConnsClosed := make(chan struct{})
+	// stop reporting ready as soon as the shutdown starts
+	srv.OnShutdown(probes.Shutdown)
 
-	go func() {
-		gracefulStop := make(chan os.Signal, 1)
-		signal.Notify(gracefulStop, os.Interrupt)
-		<-gracefulStop
+	// the event streams would otherwise hold the drain until the timeout
+	srv.OnShutdown(albumsFeed.Close)
+
+	// the WebSockets are hijacked, the server does not wait for them
+	if albumsPresence != nil {
+		srv.OnShutdown(albumsPresence.Close)
+	}
 
-		clog.Info("shutting down the server...")
-		if err := srv.Shutdown(ctx); err != nil {
-			clog.Error(err, "server failed to shutdown")
+	var wg sync.WaitGroup
+
+	if cfg.Admin.Port != "" {
+		admin, err := server.New(cfg.AdminServer(), adminRouter)
+		if err != nil {
+			clog.Fatal("admin server failed to configure: " + err.Error())
 		}
-		close(idleConnsClosed)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			clog.Info("admin listening on port: " + cfg.Admin.Port)
+			if err := admin.Run(ctx); err != nil {
+				clog.Error(err, "admin server failed")
+				stop()
+			}
+		}()
+	}
+
+	// gRPC API on its own port, checking the same tokens and sharing the
+	// authentication rate limit of the REST API
+	if cfg.GRPC.Port != "" {
+		grpcServer := grpcserver.New(cfg.GRPC, cfg.JWT, rs.RateLimiter, cfg.RateLimit.Auth, cfg.Server.ShutdownTimeout)
+		routes.Grpc(grpcServer, rs, validator, cfg)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			clog.Info("gRPC listening on port: " + cfg.GRPC.Port)
+			if err := grpcServer.Run(ctx); err != nil {
+				clog.Error(err, "gRPC server failed")
+				stop()
+			}
+		}()
+	}
+
+	// permanent removal of the albums and users deleted before the retention period
+	purgeWorker := worker.NewPurgeWorker(cfg.Trash)
+	purgeWorker.Register("albums", albumsUseCase)
+	purgeWorker.Register("users", usersUseCase)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		purgeWorker.Run(ctx)
+	}()
+
+	// events relayed from the outbox to the subscribers of the bus
+	var events domain.Publisher = bus
+	if cfg.Outbox.Log {
+		events = publisher.Fanout(bus, publisher.NewLogPublisher())
+	}
+
+	outboxRelay := worker.NewOutboxRelay(cfg.Outbox, outbox, events)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		outboxRelay.Run(ctx)
 	}()
 
-	clog.Info("listening on port: " + os.Getenv("PORT"))
+	// events published from the outbox by every instance, handed to the bus of this one
+	eventListener := worker.NewEventListener(repository.NewOutboxListener(conn, cfg.Postgres.DSN()), published)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		eventListener.Run(ctx)
+	}()
+
+	if albumsPresence != nil {
+		// release of the expired field locks of the editors
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			albumsPresence.Run(ctx)
+		}()
+
+		// the lock is released on shutdown, before the pool is closed;
+		// another instance may serve the presence once it is lost
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := presenceLock.Hold(ctx); err != nil {
+				clog.Error(err, "presence lock failed")
+				stop()
+			}
+		}()
+	}
+
+	// deliveries of the events to the webhooks
+	webhookDispatcher := worker.NewWebhookDispatcher(cfg.Webhooks, webhooksUseCase)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		webhookDispatcher.Run(ctx)
+	}()
+
+	// jobs of the queue, the running ones are given the jobs shutdown timeout to finish
+	jobRunner := worker.NewJobRunner(cfg.Jobs, jobsRepository)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobRunner.Run(ctx)
+	}()
+
+	clog.Info("listening on port: " + cfg.Server.Port)
 	clog.Info("you're good to go! :)")
 
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		clog.Error(err, "server failed to start")
+	// blocks until a shutdown signal arrives and the in-flight requests are drained
+	if err := srv.Run(ctx); err != nil {
+		clog.Error(err, "server failed")
+	}
+
+	// stops the other listeners and the workers if the server failed on its own
+	stop()
+	wg.Wait()
+
+	// the pool is closed only after the last request has finished
+	if err := conn.Close(); err != nil {
+		clog.Error(err, "failed to close the postgres connections")
+	}
+
+	// flushes the spans still buffered
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := shutdownTracing(flushCtx); err != nil {
+		clog.Error(err, "failed to flush the traces")
 	}
 
-	<-idleConnsClosed
+	clog.Info("server stopped")
 }
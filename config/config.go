@@ -0,0 +1,636 @@
+// Package config loads the application settings from an optional YAML or
+// TOML file and the environment into a typed struct that is validated once at startup.
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"hexagony/libs/clientip"
+	"hexagony/libs/ratelimit"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+// redacted replaces secret values when the configuration is printed.
+const redacted = "******"
+
+// Config holds every setting the application needs to run.
+//
+// Values are resolved in the following order, the last one wins:
+// the `default` tag, the YAML or TOML file and the environment variable
+// named in the `env` tag.
+type Config struct {
+	Env         string      `yaml:"env_mode" env:"ENV_MODE" default:"production"`
+	Server      Server      `yaml:"server"`
+	Postgres    Postgres    `yaml:"postgres"`
+	JWT         JWT         `yaml:"jwt"`
+	Health      Health      `yaml:"health"`
+	Admin       Admin       `yaml:"admin"`
+	GRPC        GRPC        `yaml:"grpc"`
+	GraphQL     GraphQL     `yaml:"graphql"`
+	Tracing     Tracing     `yaml:"tracing"`
+	Log         Log         `yaml:"log"`
+	Concurrency Concurrency `yaml:"concurrency"`
+	Trash       Trash       `yaml:"trash"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	RateLimit   RateLimit   `yaml:"rate_limit"`
+	Cache       Cache       `yaml:"cache"`
+	Outbox      Outbox      `yaml:"outbox"`
+	Webhooks    Webhooks    `yaml:"webhooks"`
+	Stream      Stream      `yaml:"stream"`
+	Presence    Presence    `yaml:"presence"`
+	Jobs        Jobs        `yaml:"jobs"`
+}
+
+// Server represents the HTTP server settings. TLS is enabled when both
+// the certificate and the key files are set. AllowedOrigins are the
+// origins of the browsers allowed by CORS and by the WebSockets, a *
+// stands for any part of an origin.
+type Server struct {
+	Port              string        `yaml:"port" env:"PORT" default:"8000"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"5s"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"5s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"20s"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"15s"`
+	TLSCertFile       string        `yaml:"tls_cert_file" env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile        string        `yaml:"tls_key_file" env:"SERVER_TLS_KEY_FILE"`
+	H2C               bool          `yaml:"h2c" env:"SERVER_H2C" default:"false"`
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+	AllowedOrigins    []string      `yaml:"allowed_origins" env:"SERVER_ALLOWED_ORIGINS" default:"https://*,http://*"`
+}
+
+// Postgres represents the database settings. When URL is set it takes
+// precedence over the discrete connection fields.
+type Postgres struct {
+	URL      string `yaml:"url" env:"DATABASE_URL" secret:"true"`
+	Host     string `yaml:"host" env:"POSTGRES_HOST"`
+	Port     string `yaml:"port" env:"POSTGRES_PORT" default:"5432"`
+	User     string `yaml:"user" env:"POSTGRES_USER"`
+	Password string `yaml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	DB       string `yaml:"db" env:"POSTGRES_DB"`
+	SSLMode  string `yaml:"sslmode" env:"POSTGRES_SSLMODE" default:"disable"`
+}
+
+// JWT represents the token signing settings.
+type JWT struct {
+	Secret   string        `yaml:"secret" env:"JWT_SECRET" secret:"true" required:"true"`
+	TokenTTL time.Duration `yaml:"token_ttl" env:"JWT_TOKEN_TTL" default:"1h"`
+}
+
+// Health represents the readiness probe settings.
+type Health struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	CacheTTL     time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL" default:"5s"`
+}
+
+// Admin represents the internal listener settings. When Port is
+// empty the admin endpoints are served by the main server. Users are
+// the UUIDs of the users allowed to call the admin endpoints.
+type Admin struct {
+	Port  string   `yaml:"port" env:"ADMIN_PORT"`
+	Users []string `yaml:"users" env:"ADMIN_USERS"`
+}
+
+// GRPC represents the gRPC listener settings. When Port is empty, the
+// default, the gRPC API is not served. Reflection lets the clients such
+// as grpcurl list the services, it is off unless enabled.
+type GRPC struct {
+	Port       string `yaml:"port" env:"GRPC_PORT"`
+	Reflection bool   `yaml:"reflection" env:"GRPC_REFLECTION" default:"false"`
+}
+
+// GraphQL represents the limits of the /graphql operations, refused
+// before they run when their fields are nested deeper than MaxDepth or
+// when they cost more than MaxComplexity. Introspection lets the
+// clients read the schema.
+type GraphQL struct {
+	MaxDepth      int  `yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH" default:"8"`
+	MaxComplexity int  `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" default:"500"`
+	Introspection bool `yaml:"introspection" env:"GRAPHQL_INTROSPECTION" default:"true"`
+}
+
+// Tracing represents the OpenTelemetry settings. Exporter is one of
+// none, otlp (OTLP over HTTP to Endpoint), stdout or file.
+type Tracing struct {
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" default:"hexagony"`
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_OTLP_INSECURE" default:"false"`
+	File        string  `yaml:"file" env:"TRACING_FILE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+// Log represents the logger settings. Both values
+// can also be changed at runtime on /admin/log.
+type Log struct {
+	Level    string `yaml:"level" env:"LOG_LEVEL" default:"debug"`
+	Sampling uint32 `yaml:"sampling" env:"LOG_SAMPLING" default:"1"`
+}
+
+// Concurrency represents the optimistic concurrency settings. When
+// RequireIfMatch is set, writes without an If-Match header are refused.
+type Concurrency struct {
+	RequireIfMatch bool `yaml:"require_if_match" env:"REQUIRE_IF_MATCH" default:"false"`
+}
+
+// Trash represents the soft delete settings. The deleted rows are
+// purged once older than Retention, a zero Retention keeps them forever.
+type Trash struct {
+	Retention     time.Duration `yaml:"retention" env:"TRASH_RETENTION" default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"TRASH_PURGE_INTERVAL" default:"1h"`
+}
+
+// Idempotency represents the Idempotency-Key settings. Store is memory,
+// for a single replica, or postgres, shared by every replica. MaxBody
+// bounds, in bytes, the body of the requests with a key, which is read
+// in memory to be fingerprinted.
+type Idempotency struct {
+	Store   string        `yaml:"store" env:"IDEMPOTENCY_STORE" default:"memory"`
+	TTL     time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" default:"24h"`
+	MaxBody int64         `yaml:"max_body" env:"IDEMPOTENCY_MAX_BODY" default:"10485760"`
+}
+
+// RateLimit represents the rate limits of the route groups, written as
+// "<rate>/<period>[,burst=<n>][,key=ip|user]" or "off". Store is
+// memory, for a single replica, or postgres, shared by every replica.
+type RateLimit struct {
+	Store   string           `yaml:"store" env:"RATE_LIMIT_STORE" default:"memory"`
+	Auth    ratelimit.Policy `yaml:"auth" env:"RATE_LIMIT_AUTH" default:"10/1m,burst=5,key=ip"`
+	Users   ratelimit.Policy `yaml:"users" env:"RATE_LIMIT_USERS" default:"300/1m,burst=60,key=user"`
+	Albums  ratelimit.Policy `yaml:"albums" env:"RATE_LIMIT_ALBUMS" default:"300/1m,burst=60,key=user"`
+	GraphQL ratelimit.Policy `yaml:"graphql" env:"RATE_LIMIT_GRAPHQL" default:"300/1m,burst=60,key=user"`
+}
+
+// Cache represents the read-through cache of the albums and users
+// found by ID. Size is the number of entries of each resource.
+type Cache struct {
+	Enabled bool          `yaml:"enabled" env:"CACHE_ENABLED" default:"false"`
+	Size    int           `yaml:"size" env:"CACHE_SIZE" default:"10000"`
+	TTL     time.Duration `yaml:"ttl" env:"CACHE_TTL" default:"1m"`
+}
+
+// Outbox represents the relay of the domain events. The failed events
+// are retried after RetryMin, doubled at each attempt up to RetryMax.
+// The published events are kept for Retention. Log also writes the
+// events to the log.
+type Outbox struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" default:"100"`
+	RetryMin     time.Duration `yaml:"retry_min" env:"OUTBOX_RETRY_MIN" default:"1s"`
+	RetryMax     time.Duration `yaml:"retry_max" env:"OUTBOX_RETRY_MAX" default:"5m"`
+	Retention    time.Duration `yaml:"retention" env:"OUTBOX_RETENTION" default:"168h"`
+	Log          bool          `yaml:"log" env:"OUTBOX_LOG" default:"true"`
+}
+
+// Webhooks represents the delivery of the events to the webhooks.
+// Up to BatchSize deliveries are claimed at each poll and Concurrency
+// of them sent at once, each one waiting Timeout for the response. The
+// failed deliveries are retried after RetryMin, doubled at each attempt
+// up to RetryMax, until MaxAttempts were made. The loopback, private
+// and link-local receivers are refused unless AllowPrivate is set,
+// e.g. for a local receiver in development.
+type Webhooks struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOKS_POLL_INTERVAL" default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env:"WEBHOOKS_BATCH_SIZE" default:"50"`
+	Concurrency  int           `yaml:"concurrency" env:"WEBHOOKS_CONCURRENCY" default:"10"`
+	Timeout      time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" default:"10s"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" default:"10"`
+	RetryMin     time.Duration `yaml:"retry_min" env:"WEBHOOKS_RETRY_MIN" default:"10s"`
+	RetryMax     time.Duration `yaml:"retry_max" env:"WEBHOOKS_RETRY_MAX" default:"1h"`
+	AllowPrivate bool          `yaml:"allow_private" env:"WEBHOOKS_ALLOW_PRIVATE" default:"false"`
+}
+
+// Stream represents the event streams pushed to the clients. The last
+// Buffer events are kept for the clients resuming a stream, Heartbeat
+// is the interval of the keep-alive messages and Retry the wait the
+// clients are told to make before reconnecting.
+type Stream struct {
+	Buffer    int           `yaml:"buffer" env:"STREAM_BUFFER" default:"1000"`
+	Heartbeat time.Duration `yaml:"heartbeat" env:"STREAM_HEARTBEAT" default:"15s"`
+	Retry     time.Duration `yaml:"retry" env:"STREAM_RETRY" default:"3s"`
+}
+
+// Presence represents the editors of the albums connected by
+// WebSocket. A field lock is released after LockTTL unless it is
+// renewed, the connections are pinged every PingInterval and closed
+// when no pong arrives in twice that time. The editors are kept in
+// memory, a single instance serves them: it must be disabled on the
+// others, which refuse to start otherwise.
+type Presence struct {
+	Enabled      bool          `yaml:"enabled" env:"PRESENCE_ENABLED" default:"true"`
+	LockTTL      time.Duration `yaml:"lock_ttl" env:"PRESENCE_LOCK_TTL" default:"30s"`
+	PingInterval time.Duration `yaml:"ping_interval" env:"PRESENCE_PING_INTERVAL" default:"30s"`
+}
+
+// Jobs represents the job runner. Up to Concurrency jobs run at once,
+// each for at most Timeout. A failed job is retried after RetryMin,
+// doubled at each attempt up to RetryMax, until it has made MaxAttempts
+// unless it sets its own. The finished jobs are kept for Retention, the
+// running ones are given ShutdownTimeout to finish on shutdown.
+type Jobs struct {
+	PollInterval    time.Duration `yaml:"poll_interval" env:"JOBS_POLL_INTERVAL" default:"1s"`
+	Concurrency     int           `yaml:"concurrency" env:"JOBS_CONCURRENCY" default:"10"`
+	Timeout         time.Duration `yaml:"timeout" env:"JOBS_TIMEOUT" default:"5m"`
+	MaxAttempts     int           `yaml:"max_attempts" env:"JOBS_MAX_ATTEMPTS" default:"5"`
+	RetryMin        time.Duration `yaml:"retry_min" env:"JOBS_RETRY_MIN" default:"10s"`
+	RetryMax        time.Duration `yaml:"retry_max" env:"JOBS_RETRY_MAX" default:"1h"`
+	Retention       time.Duration `yaml:"retention" env:"JOBS_RETENTION" default:"168h"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"JOBS_SHUTDOWN_TIMEOUT" default:"10s"`
+}
+
+// DSN returns the connection string used to open the database.
+func (p Postgres) DSN() string {
+	if p.URL != "" {
+		return p.URL
+	}
+
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		p.Host, p.Port, p.User, p.Password, p.DB, p.SSLMode,
+	)
+}
+
+// AdminServer returns the settings of the admin listener. It shares
+// the timeouts of the main server and is never exposed over TLS.
+func (c *Config) AdminServer() Server {
+	admin := c.Server
+	admin.Port = c.Admin.Port
+	admin.TLSCertFile = ""
+	admin.TLSKeyFile = ""
+	admin.H2C = false
+
+	return admin
+}
+
+// IsDevelopment reports whether the application runs in development mode.
+func (c *Config) IsDevelopment() bool {
+	return c.Env == Development
+}
+
+// Load builds the configuration from the defaults, the optional YAML
+// or TOML file at path and the environment. When path is empty the CONFIG_FILE
+// environment variable is used instead.
+//
+// Every problem found is reported at once in the returned error.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	var errs []error
+
+	walk(reflect.ValueOf(cfg).Elem(), "", func(f field) {
+		if def, ok := f.tag.Lookup("default"); ok {
+			if err := set(f.value, def); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid default: %w", f.path, err))
+			}
+		}
+	})
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	walk(reflect.ValueOf(cfg).Elem(), "", func(f field) {
+		name, ok := f.tag.Lookup("env")
+		if !ok {
+			return
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if err := set(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+
+	errs = append(errs, cfg.validate()...)
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return cfg, nil
+}
+
+// String returns a printable representation of the configuration
+// with every secret value redacted.
+func (c Config) String() string {
+	var b strings.Builder
+
+	walk(reflect.ValueOf(&c).Elem(), "", func(f field) {
+		value := fmt.Sprint(f.value.Interface())
+		if f.tag.Get("secret") == "true" && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", f.path, value)
+	})
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the config file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		if data, err = tomlToYAML(data); err != nil {
+			return fmt.Errorf("failed to parse the config file: %w", err)
+		}
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse the config file: %w", err)
+	}
+
+	return nil
+}
+
+// tomlToYAML converts a TOML document to YAML, so that both formats are
+// decoded with the yaml keys and the same parsing of the values.
+func tomlToYAML(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// validate checks the loaded values and returns every problem found.
+func (c *Config) validate() []error {
+	var errs []error
+
+	walk(reflect.ValueOf(c).Elem(), "", func(f field) {
+		if f.tag.Get("required") == "true" && f.value.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", describe(f)))
+		}
+	})
+
+	if c.Env != Development && c.Env != Production {
+		errs = append(errs, fmt.Errorf("env_mode must be %q or %q, got %q", Development, Production, c.Env))
+	}
+
+	if c.Postgres.URL == "" {
+		for _, f := range []struct{ name, value string }{
+			{"postgres.host (POSTGRES_HOST)", c.Postgres.Host},
+			{"postgres.user (POSTGRES_USER)", c.Postgres.User},
+			{"postgres.db (POSTGRES_DB)", c.Postgres.DB},
+		} {
+			if f.value == "" {
+				errs = append(errs, fmt.Errorf("%s is required when postgres.url is not set", f.name))
+			}
+		}
+	}
+
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("server.tls_cert_file and server.tls_key_file must be set together"))
+	}
+
+	if c.Admin.Port != "" && c.Admin.Port == c.Server.Port {
+		errs = append(errs, errors.New("admin.port must differ from server.port"))
+	}
+
+	for _, user := range c.Admin.Users {
+		if _, err := uuid.Parse(user); err != nil {
+			errs = append(errs, fmt.Errorf("admin.users (ADMIN_USERS): %q is not a user UUID", user))
+		}
+	}
+
+	if c.GRPC.Port != "" && (c.GRPC.Port == c.Server.Port || c.GRPC.Port == c.Admin.Port) {
+		errs = append(errs, errors.New("grpc.port must differ from server.port and admin.port"))
+	}
+
+	if c.GraphQL.MaxDepth <= 0 || c.GraphQL.MaxComplexity <= 0 {
+		errs = append(errs, errors.New("graphql.max_depth and graphql.max_complexity must be positive"))
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must be positive"))
+	}
+
+	if c.Trash.Retention < 0 {
+		errs = append(errs, errors.New("trash.retention must not be negative"))
+	}
+
+	if c.Trash.Retention > 0 && c.Trash.PurgeInterval <= 0 {
+		errs = append(errs, errors.New("trash.purge_interval must be positive"))
+	}
+
+	if c.Idempotency.Store != "memory" && c.Idempotency.Store != "postgres" {
+		errs = append(errs, fmt.Errorf("idempotency.store must be memory or postgres, got %q", c.Idempotency.Store))
+	}
+
+	if c.Idempotency.TTL <= 0 {
+		errs = append(errs, errors.New("idempotency.ttl must be positive"))
+	}
+
+	if c.Idempotency.MaxBody <= 0 {
+		errs = append(errs, errors.New("idempotency.max_body must be positive"))
+	}
+
+	if c.RateLimit.Store != "memory" && c.RateLimit.Store != "postgres" {
+		errs = append(errs, fmt.Errorf("rate_limit.store must be memory or postgres, got %q", c.RateLimit.Store))
+	}
+
+	if c.Cache.Enabled && (c.Cache.Size <= 0 || c.Cache.TTL <= 0) {
+		errs = append(errs, errors.New("cache.size and cache.ttl must be positive when the cache is enabled"))
+	}
+
+	if c.Outbox.PollInterval <= 0 || c.Outbox.BatchSize <= 0 || c.Outbox.RetryMin <= 0 || c.Outbox.Retention <= 0 {
+		errs = append(errs, errors.New("outbox.poll_interval, outbox.batch_size, outbox.retry_min and outbox.retention must be positive"))
+	}
+
+	if c.Outbox.RetryMax < c.Outbox.RetryMin {
+		errs = append(errs, errors.New("outbox.retry_max must not be less than outbox.retry_min"))
+	}
+
+	if c.Webhooks.PollInterval <= 0 || c.Webhooks.BatchSize <= 0 || c.Webhooks.Concurrency <= 0 ||
+		c.Webhooks.Timeout <= 0 || c.Webhooks.MaxAttempts <= 0 || c.Webhooks.RetryMin <= 0 {
+		errs = append(errs, errors.New("the webhooks settings must be positive"))
+	}
+
+	if c.Webhooks.RetryMax < c.Webhooks.RetryMin {
+		errs = append(errs, errors.New("webhooks.retry_max must not be less than webhooks.retry_min"))
+	}
+
+	if c.Stream.Buffer <= 0 || c.Stream.Heartbeat <= 0 || c.Stream.Retry <= 0 {
+		errs = append(errs, errors.New("stream.buffer, stream.heartbeat and stream.retry must be positive"))
+	}
+
+	if c.Presence.LockTTL <= 0 || c.Presence.PingInterval <= 0 {
+		errs = append(errs, errors.New("presence.lock_ttl and presence.ping_interval must be positive"))
+	}
+
+	if c.Jobs.PollInterval <= 0 || c.Jobs.Concurrency <= 0 || c.Jobs.Timeout <= 0 || c.Jobs.MaxAttempts <= 0 ||
+		c.Jobs.RetryMin <= 0 || c.Jobs.Retention <= 0 || c.Jobs.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("the jobs settings must be positive"))
+	}
+
+	if c.Jobs.RetryMax < c.Jobs.RetryMin {
+		errs = append(errs, errors.New("jobs.retry_max must not be less than jobs.retry_min"))
+	}
+
+	if _, err := clientip.ParseProxies(c.Server.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("server.trusted_proxies: %w", err))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		if c.Tracing.File == "" {
+			errs = append(errs, errors.New("tracing.file (TRACING_FILE) is required by the file exporter"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, otlp, stdout or file, got %q", c.Tracing.Exporter))
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+
+	switch c.Log.Level {
+	case "trace", "debug", "info", "warn", "error", "fatal", "panic", "disabled":
+	default:
+		errs = append(errs, fmt.Errorf("log.level is not a valid level, got %q", c.Log.Level))
+	}
+
+	if c.JWT.TokenTTL <= 0 {
+		errs = append(errs, errors.New("jwt.token_ttl must be positive"))
+	}
+
+	return errs
+}
+
+// field is a leaf of the configuration tree.
+type field struct {
+	path  string
+	tag   reflect.StructTag
+	value reflect.Value
+}
+
+// walk calls fn for every leaf field of v, descending into nested
+// structs. Durations and text unmarshalers are treated as leaves.
+func walk(v reflect.Value, prefix string, fn func(field)) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && !isText(fv) {
+			walk(fv, name, fn)
+			continue
+		}
+
+		fn(field{path: name, tag: sf.Tag, value: fv})
+	}
+}
+
+// isText reports whether v parses itself from text.
+func isText(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// set parses raw according to the kind of v and stores the result.
+func set(v reflect.Value, raw string) error {
+	if isText(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func describe(f field) string {
+	if env := f.tag.Get("env"); env != "" {
+		return fmt.Sprintf("%s (%s)", f.path, env)
+	}
+	return f.path
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hexagony/libs/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setEnv(t *testing.T, env map[string]string) {
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func validEnv() map[string]string {
+	return map[string]string{
+		"ENV_MODE":          "development",
+		"POSTGRES_HOST":     "localhost",
+		"POSTGRES_USER":     "hexagony",
+		"POSTGRES_PASSWORD": "secret",
+		"POSTGRES_DB":       "hexagony",
+		"JWT_SECRET":        "jwt-secret",
+	}
+}
+
+func TestLoad(t *testing.T) {
+	setEnv(t, validEnv())
+	t.Setenv("JWT_TOKEN_TTL", "30m")
+	t.Setenv("RATE_LIMIT_AUTH", "5/1s")
+
+	cfg, err := Load("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ratelimit.Policy{Rate: 5, Period: time.Second, Burst: 5, Key: ratelimit.KeyIP}, cfg.RateLimit.Auth)
+	assert.Equal(t, ratelimit.KeyUser, cfg.RateLimit.Albums.Key)
+	assert.True(t, cfg.IsDevelopment())
+	assert.Equal(t, "8000", cfg.Server.Port)
+	assert.Equal(t, 30*time.Minute, cfg.JWT.TokenTTL)
+	assert.Equal(t,
+		"host=localhost port=5432 user=hexagony password=secret dbname=hexagony sslmode=disable",
+		cfg.Postgres.DSN(),
+	)
+}
+
+func TestLoadFile(t *testing.T) {
+	setEnv(t, validEnv())
+	t.Setenv("PORT", "9000")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := "server:\n  port: \"7000\"\njwt:\n  token_ttl: 2h\npostgres:\n  url: postgres://db\n"
+	assert.NoError(t, os.WriteFile(path, []byte(file), 0o600))
+
+	cfg, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "9000", cfg.Server.Port) // environment wins over the file
+	assert.Equal(t, 2*time.Hour, cfg.JWT.TokenTTL)
+	assert.Equal(t, "postgres://db", cfg.Postgres.DSN())
+}
+
+func TestLoadTOMLFile(t *testing.T) {
+	setEnv(t, validEnv())
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	file := "[server]\nport = \"7000\"\n\n[jwt]\ntoken_ttl = \"2h\"\n\n[rate_limit]\nauth = \"5/1s\"\n"
+	assert.NoError(t, os.WriteFile(path, []byte(file), 0o600))
+
+	cfg, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "7000", cfg.Server.Port)
+	assert.Equal(t, 2*time.Hour, cfg.JWT.TokenTTL)
+	assert.Equal(t, 5, cfg.RateLimit.Auth.Rate)
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	t.Setenv("ENV_MODE", "staging")
+	t.Setenv("JWT_TOKEN_TTL", "forever")
+	t.Setenv("IDEMPOTENCY_STORE", "redis")
+	t.Setenv("RATE_LIMIT_USERS", "often")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8,proxy")
+	t.Setenv("GRPC_PORT", "8000")
+	t.Setenv("GRAPHQL_MAX_DEPTH", "0")
+	t.Setenv("ADMIN_USERS", "root")
+
+	_, err := Load("")
+
+	assert.Error(t, err)
+	for _, problem := range []string{
+		"JWT_TOKEN_TTL",
+		"jwt.secret (JWT_SECRET) is required",
+		"env_mode must be",
+		"postgres.host (POSTGRES_HOST) is required",
+		"postgres.db (POSTGRES_DB) is required",
+		"idempotency.store must be",
+		"RATE_LIMIT_USERS: invalid rate limit",
+		"server.trusted_proxies",
+		"grpc.port must differ",
+		`admin.users (ADMIN_USERS): "root" is not a user UUID`,
+		"graphql.max_depth and graphql.max_complexity must be positive",
+	} {
+		assert.Contains(t, err.Error(), problem)
+	}
+}
+
+func TestLoadReportsFileAndEnvironmentProblems(t *testing.T) {
+	setEnv(t, validEnv())
+	t.Setenv("JWT_TOKEN_TTL", "forever")
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read the config file")
+	assert.Contains(t, err.Error(), "JWT_TOKEN_TTL")
+}
+
+func TestGRPCIsOptIn(t *testing.T) {
+	setEnv(t, validEnv())
+
+	cfg, err := Load("")
+
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.GRPC.Port)
+	assert.False(t, cfg.GRPC.Reflection)
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	setEnv(t, validEnv())
+
+	cfg, err := Load("")
+	assert.NoError(t, err)
+
+	out := cfg.String()
+
+	assert.Contains(t, out, "postgres.password="+redacted)
+	assert.Contains(t, out, "jwt.secret="+redacted)
+	assert.Contains(t, out, "postgres.host=localhost")
+	assert.False(t, strings.Contains(out, "jwt-secret"))
+}
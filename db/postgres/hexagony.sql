@@ -4,15 +4,191 @@ CREATE TABLE IF NOT EXISTS users (
   email VARCHAR(100) NOT NULL UNIQUE,
   password VARCHAR(100) NOT NULL,
   created_at TIMESTAMPTZ NOT NULL,
-  updated_at TIMESTAMPTZ NOT NULL
+  updated_at TIMESTAMPTZ NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  deleted_at TIMESTAMPTZ
 );
 
 CREATE TABLE IF NOT EXISTS albums (
   uuid VARCHAR(36) NOT NULL PRIMARY KEY,
   name VARCHAR(100) NOT NULL,
   length SMALLINT NOT NULL,
+  barcode VARCHAR(13) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS barcode VARCHAR(13) NOT NULL DEFAULT '';
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- the trash is listed and purged by deletion time
+CREATE INDEX IF NOT EXISTS albums_deleted_at_idx ON albums (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- album snapshots taken by every write, the revision is the album version
+CREATE TABLE IF NOT EXISTS album_revisions (
+  album_uuid VARCHAR(36) NOT NULL REFERENCES albums (uuid) ON DELETE CASCADE,
+  revision INTEGER NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  snapshot JSONB NOT NULL,
+  actor_uuid VARCHAR(36),
+  actor_email VARCHAR(100) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (album_uuid, revision)
+);
+
+-- append-only record of the mutating actions and of the logins,
+-- every entry is chained to the previous one by its hash
+CREATE TABLE IF NOT EXISTS audit_log (
+  id BIGSERIAL PRIMARY KEY,
+  actor_uuid VARCHAR(36),
+  actor_email VARCHAR(100) NOT NULL DEFAULT '',
+  action VARCHAR(32) NOT NULL,
+  outcome VARCHAR(16) NOT NULL,
+  resource_type VARCHAR(32) NOT NULL,
+  resource_id VARCHAR(36) NOT NULL DEFAULT '',
+  ip VARCHAR(45) NOT NULL DEFAULT '',
+  request_id VARCHAR(128) NOT NULL DEFAULT '',
+  changes JSONB NOT NULL DEFAULT '[]',
+  created_at TIMESTAMPTZ NOT NULL,
+  prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+  hash VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_resource_idx ON audit_log (resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+  FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+-- responses of the requests made with an Idempotency-Key, replayed
+-- for their repeats until they expire, status is 0 while in progress
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  scope VARCHAR(64) NOT NULL,
+  key VARCHAR(255) NOT NULL,
+  fingerprint VARCHAR(64) NOT NULL,
+  status INT NOT NULL DEFAULT 0,
+  header JSONB,
+  body BYTEA,
+  created_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+-- theoretical arrival times of the rate limited keys, in Unix nanoseconds,
+-- shared by the replicas
+CREATE TABLE IF NOT EXISTS rate_limits (
+  key VARCHAR(255) PRIMARY KEY,
+  tat BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS rate_limits_tat_idx ON rate_limits (tat);
+
+-- domain events written in the transaction of the change raising them,
+-- published in order per aggregate by the relay
+CREATE TABLE IF NOT EXISTS outbox (
+  id BIGSERIAL PRIMARY KEY,
+  event_id VARCHAR(36) NOT NULL UNIQUE,
+  event_type VARCHAR(64) NOT NULL,
+  aggregate_type VARCHAR(32) NOT NULL,
+  aggregate_id VARCHAR(36) NOT NULL,
+  actor_uuid VARCHAR(36),
+  payload JSONB NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error TEXT NOT NULL DEFAULT '',
+  published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_published_at_idx ON outbox (published_at);
+
+-- subscriber URLs notified of the chosen event types
+CREATE TABLE IF NOT EXISTS webhooks (
+  uuid VARCHAR(36) NOT NULL PRIMARY KEY,
+  url VARCHAR(2048) NOT NULL,
+  event_types VARCHAR(64)[] NOT NULL,
+  secret VARCHAR(100) NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
   created_at TIMESTAMPTZ NOT NULL,
   updated_at TIMESTAMPTZ NOT NULL
 );
 
+-- an event to deliver to a webhook, attempted until it succeeds or is dead
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  uuid VARCHAR(36) NOT NULL PRIMARY KEY,
+  webhook_uuid VARCHAR(36) NOT NULL REFERENCES webhooks (uuid) ON DELETE CASCADE,
+  event_id VARCHAR(36) NOT NULL,
+  event_type VARCHAR(64) NOT NULL,
+  payload JSONB NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ,
+  response_status INT NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL,
+  delivered_at TIMESTAMPTZ,
+  UNIQUE (webhook_uuid, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_uuid, created_at DESC);
+
+-- the log of the attempts of each delivery
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+  id BIGSERIAL PRIMARY KEY,
+  delivery_uuid VARCHAR(36) NOT NULL REFERENCES webhook_deliveries (uuid) ON DELETE CASCADE,
+  status_code INT NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL,
+  attempted_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_attempts_delivery_idx ON webhook_attempts (delivery_uuid, id);
+
+-- deferred and scheduled work, run by the job runner of any instance
+CREATE TABLE IF NOT EXISTS jobs (
+  uuid VARCHAR(36) NOT NULL PRIMARY KEY,
+  kind VARCHAR(64) NOT NULL,
+  payload JSONB NOT NULL,
+  unique_key VARCHAR(255),
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL,
+  run_at TIMESTAMPTZ NOT NULL,
+  lock_id VARCHAR(36),
+  locked_until TIMESTAMPTZ,
+  last_error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS jobs_due_idx ON jobs (run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS jobs_locked_until_idx ON jobs (locked_until) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS jobs_created_at_idx ON jobs (created_at DESC);
+CREATE INDEX IF NOT EXISTS jobs_finished_at_idx ON jobs (finished_at);
+CREATE UNIQUE INDEX IF NOT EXISTS jobs_unique_key_idx ON jobs (unique_key) WHERE status IN ('pending', 'running');
+
+-- the next run of each schedule, advanced by the runner enqueuing it
+CREATE TABLE IF NOT EXISTS job_schedules (
+  name VARCHAR(64) NOT NULL PRIMARY KEY,
+  next_run_at TIMESTAMPTZ NOT NULL
+);
+
 INSERT INTO users VALUES ('7d31461a-6ed5-425e-96fe-fa98e56d6828', 'John Doe', 'john@doe.com', '$2a$10$rPyJPskrTN545bXE0cqEU.T3uqluwiPFjGHMjE0/K.QuTe5XedjYi', '2022-06-19 16:53:09.000', '2022-06-19 16:53:09.000');
\ No newline at end of file
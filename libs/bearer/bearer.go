@@ -0,0 +1,61 @@
+// Package bearer reads and verifies the HMAC signed JWTs sent as
+// bearer tokens, shared by the HTTP and the gRPC APIs.
+package bearer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmpty     = errors.New("empty token")
+	ErrMalformed = errors.New("malformed token")
+	ErrInvalid   = errors.New("invalid token")
+)
+
+// Claims are the claims of the tokens issued by /auth.
+type Claims struct {
+	jwt.RegisteredClaims
+	UUID  uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+}
+
+// Token returns the token of an authorization value, "Bearer <token>".
+func Token(authorization string) (string, error) {
+	if authorization == "" {
+		return "", ErrEmpty
+	}
+
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return "", ErrMalformed
+	}
+
+	return token, nil
+}
+
+// Verify parses token and checks its signature, made with secret, and
+// its expiration. The errors other than ErrInvalid wrap the cause.
+func Verify(token, secret string) (*Claims, error) {
+	var claims Claims
+
+	parsed, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !parsed.Valid {
+		return nil, ErrInvalid
+	}
+
+	return &claims, nil
+}
@@ -0,0 +1,53 @@
+package bearer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken(t *testing.T) {
+	token, err := Token("Bearer abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", token)
+
+	_, err = Token("")
+	assert.ErrorIs(t, err, ErrEmpty)
+
+	for _, value := range []string{"abc", "Bearer ", "Basic abc"} {
+		_, err = Token(value)
+		assert.ErrorIs(t, err, ErrMalformed, value)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	id := uuid.New()
+
+	sign := func(expiresAt time.Time, method jwt.SigningMethod, key interface{}) string {
+		claims := Claims{UUID: id, Email: "john@example.com"}
+		claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		assert.NoError(t, err)
+
+		return token
+	}
+
+	claims, err := Verify(sign(time.Now().Add(time.Hour), jwt.SigningMethodHS256, []byte("secret")), "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, id, claims.UUID)
+	assert.Equal(t, "john@example.com", claims.Email)
+
+	_, err = Verify(sign(time.Now().Add(time.Hour), jwt.SigningMethodHS256, []byte("other")), "secret")
+	assert.Error(t, err)
+
+	_, err = Verify(sign(time.Now().Add(-time.Hour), jwt.SigningMethodHS256, []byte("secret")), "secret")
+	assert.Error(t, err)
+
+	// the tokens must be signed with HMAC
+	_, err = Verify(sign(time.Now().Add(time.Hour), jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), "secret")
+	assert.Error(t, err)
+}
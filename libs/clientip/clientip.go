@@ -0,0 +1,105 @@
+// Package clientip carries the IP address of the client of the current request in a context.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// FromRequest returns the IP address of the peer of r, without the port.
+func FromRequest(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// Proxies are the trusted reverse proxies, allowed to
+// report the client address in X-Forwarded-For.
+type Proxies []netip.Prefix
+
+// ParseProxies parses the addresses and CIDR ranges of the trusted proxies.
+func ParseProxies(proxies []string) (Proxies, error) {
+	var prefixes Proxies
+
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+func (p Proxies) trusts(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range p {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FromRequest returns the IP address of the client of r. The
+// X-Forwarded-For addresses are read from the right, as long as they
+// were added by a trusted proxy, the first untrusted one is the client.
+// The header is ignored when the peer itself is not a trusted proxy,
+// any client could send it.
+func (p Proxies) FromRequest(r *http.Request) string {
+	ip := FromRequest(r)
+	if !p.trusts(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+
+		ip = hop
+		if !p.trusts(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// NewContext returns a copy of ctx carrying ip.
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, ip)
+}
+
+// FromContext returns the client IP address carried by ctx, if any.
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ctxKey{}).(string)
+	return ip
+}
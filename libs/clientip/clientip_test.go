@@ -0,0 +1,36 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxiesFromRequest(t *testing.T) {
+	proxies, err := ParseProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	assert.NoError(t, err)
+
+	for _, c := range []struct {
+		name, peer, forwarded, ip string
+	}{
+		{"direct", "203.0.113.7:4000", "", "203.0.113.7"},
+		{"untrusted peer", "203.0.113.7:4000", "198.51.100.1", "203.0.113.7"},
+		{"trusted peer", "10.0.0.2:4000", "198.51.100.1", "198.51.100.1"},
+		{"proxy chain", "10.0.0.2:4000", "198.51.100.1, 203.0.113.9, 192.168.1.1", "203.0.113.9"},
+		{"spoofed", "10.0.0.2:4000", "not-an-ip, 198.51.100.1", "198.51.100.1"},
+		{"only proxies", "10.0.0.2:4000", "10.0.0.3", "10.0.0.3"},
+		{"no header", "10.0.0.2:4000", "", "10.0.0.2"},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = c.peer
+		if c.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+
+		assert.Equal(t, c.ip, proxies.FromRequest(r), c.name)
+	}
+
+	_, err = ParseProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
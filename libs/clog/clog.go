@@ -1,17 +1,29 @@
 package clog
 
 import (
+	"context"
 	"os"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+func init() {
+	log.Logger = log.Logger.Hook(traceHook{}).Sample(sampler)
+}
+
 // Error is used for error level logs.
 func Error(err error, msg string) {
 	log.Error().Err(err).Msg(msg)
 }
 
+// ErrorContext is used for error level logs tied to a request.
+// The logger carried by ctx is used and the trace and span IDs
+// of ctx are added to the log.
+func ErrorContext(ctx context.Context, err error, msg string) {
+	FromContext(ctx).Error().Ctx(ctx).Err(err).Msg(msg)
+}
+
 // Debug is used for debug level logs.
 func Debug(msg string) {
 	log.Debug().Msg(msg)
@@ -45,6 +57,12 @@ func Custom(msg map[string]interface{}) {
 	log.Info().Fields(msg).Msg("")
 }
 
+// CustomContext is like Custom, using the logger carried by ctx
+// and adding the trace and span IDs of ctx to the log.
+func CustomContext(ctx context.Context, msg map[string]interface{}) {
+	FromContext(ctx).Info().Ctx(ctx).Fields(msg).Msg("")
+}
+
 // UseConsoleOutput writes the output to the console.
 func UseConsoleOutput() {
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
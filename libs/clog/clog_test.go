@@ -0,0 +1,61 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func captureOutput(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	previous := log.Logger
+	log.Logger = log.Output(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+
+	return &buf
+}
+
+func TestWith(t *testing.T) {
+	buf := captureOutput(t)
+
+	ctx := With(context.Background(), map[string]interface{}{"request_id": "abc"})
+	InfoContext(ctx, "hello")
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.Equal(t, "hello", entry["message"])
+}
+
+func TestSampling(t *testing.T) {
+	buf := captureOutput(t)
+
+	SetSampling(3)
+	t.Cleanup(func() { SetSampling(1) })
+
+	for i := 0; i < 6; i++ {
+		Info("info")
+		Warn("warn")
+	}
+
+	out := buf.String()
+	assert.Equal(t, 2, strings.Count(out, `"message":"info"`))
+	assert.Equal(t, 6, strings.Count(out, `"message":"warn"`))
+}
+
+func TestSetLevel(t *testing.T) {
+	previous := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(previous) })
+
+	assert.NoError(t, SetLevel("warn"))
+	assert.Equal(t, "warn", Level())
+	assert.Error(t, SetLevel("verbose"))
+}
@@ -0,0 +1,42 @@
+package clog
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type ctxKey struct{}
+
+// FromContext returns the logger carried by ctx,
+// or the global logger if there is none.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*zerolog.Logger); ok {
+		return l
+	}
+	return &log.Logger
+}
+
+// With derives a logger from the one carried by ctx with the given
+// fields and returns a copy of ctx carrying it. Every log written
+// with the returned context includes the fields.
+func With(ctx context.Context, fields map[string]interface{}) context.Context {
+	l := FromContext(ctx).With().Fields(fields).Logger()
+	return context.WithValue(ctx, ctxKey{}, &l)
+}
+
+// InfoContext is used for info level logs tied to a request.
+func InfoContext(ctx context.Context, msg string) {
+	FromContext(ctx).Info().Ctx(ctx).Msg(msg)
+}
+
+// WarnContext is used for warn level logs tied to a request.
+func WarnContext(ctx context.Context, msg string) {
+	FromContext(ctx).Warn().Ctx(ctx).Msg(msg)
+}
+
+// DebugContext is used for debug level logs tied to a request.
+func DebugContext(ctx context.Context, msg string) {
+	FromContext(ctx).Debug().Ctx(ctx).Msg(msg)
+}
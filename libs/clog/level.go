@@ -0,0 +1,106 @@
+package clog
+
+import (
+	"encoding/json"
+	"hexagony/libs/problem"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// levelSampler keeps one out of every n events below the warn level.
+// Warnings and errors are never dropped.
+type levelSampler struct {
+	n       atomic.Uint32
+	counter atomic.Uint32
+}
+
+func (s *levelSampler) Sample(lvl zerolog.Level) bool {
+	if lvl >= zerolog.WarnLevel {
+		return true
+	}
+
+	n := s.n.Load()
+	if n <= 1 {
+		return true
+	}
+
+	return s.counter.Add(1)%n == 1
+}
+
+var sampler = &levelSampler{}
+
+// SetLevel changes the minimum level logged, e.g. "debug" or "warn".
+// It is safe to call at runtime.
+func SetLevel(level string) error {
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	zerolog.SetGlobalLevel(lvl)
+
+	return nil
+}
+
+// Level returns the minimum level logged.
+func Level() string {
+	return zerolog.GlobalLevel().String()
+}
+
+// SetSampling keeps one out of every n logs below the warn level.
+// A value of 0 or 1 disables sampling. It is safe to call at runtime.
+func SetSampling(n uint32) {
+	sampler.n.Store(n)
+}
+
+// Sampling returns the current sampling rate.
+func Sampling() uint32 {
+	return sampler.n.Load()
+}
+
+// Settings is the payload of the runtime settings handler.
+type Settings struct {
+	Level    string  `json:"level"`
+	Sampling *uint32 `json:"sampling,omitempty"`
+}
+
+// Handler reads (GET) and changes (PUT) the level and
+// the sampling rate of the logger at runtime.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var payload Settings
+
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				problem.Write(w, problem.New(http.StatusBadRequest, "invalid_payload", "the request payload is invalid"))
+				return
+			}
+
+			if payload.Level != "" {
+				if err := SetLevel(payload.Level); err != nil {
+					problem.Write(w, problem.New(http.StatusBadRequest, "invalid_log_level", err.Error()))
+					return
+				}
+			}
+
+			if payload.Sampling != nil {
+				SetSampling(*payload.Sampling)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			problem.Write(w, problem.New(http.StatusMethodNotAllowed, "method_not_allowed", ""))
+			return
+		}
+
+		sampling := Sampling()
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&Settings{Level: Level(), Sampling: &sampling}); err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,214 @@
+// Package cron parses the cron expressions of the schedules: five
+// fields (minute, hour, day of month, month and day of week) made of
+// values, ranges, steps and lists, or one of the @yearly, @monthly,
+// @weekly, @daily and @hourly shorthands, or @every <duration>.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSpec is wrapped by the errors of the invalid expressions.
+var ErrSpec = errors.New("invalid cron expression")
+
+// searchLimit bounds the search of the next time, an expression such
+// as "0 0 30 2 *" never matches.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// bounds of a field, the names are accepted in place of the values.
+type bounds struct {
+	min, max int
+	names    []string
+}
+
+var fields = []bounds{
+	{min: 0, max: 59},
+	{min: 0, max: 23},
+	{min: 1, max: 31},
+	{min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Schedule is a parsed expression. The fields are the sets of the
+// values they match, bit n set for the value n.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// a day matches either restricted day field, as in crontab
+	anyDom, anyDow bool
+
+	every time.Duration
+}
+
+// Parse parses spec.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("%w: %q, the interval must be a duration of a second or more", ErrSpec, spec)
+		}
+
+		return &Schedule{every: interval}, nil
+	}
+
+	if expanded, ok := shorthands[spec]; ok {
+		spec = expanded
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%w: %q, expected %d fields", ErrSpec, spec, len(fields))
+	}
+
+	sets := make([]uint64, len(fields))
+
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q, %s", ErrSpec, spec, err)
+		}
+		sets[i] = set
+	}
+
+	// 7 is another name of sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &Schedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		anyDom: parts[2] == "*",
+		anyDow: parts[4] == "*",
+	}, nil
+}
+
+// Next returns the first time matched after t, in the location of t,
+// or the zero time if there is none. The intervals of @every are
+// aligned on the zero time, so that every process agrees on them.
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Truncate(s.every).Add(s.every)
+	}
+
+	limit := t.Add(searchLimit)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !has(s.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+
+	switch {
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func has(set uint64, value int) bool {
+	return set&(1<<value) != 0
+}
+
+// parseField parses a comma separated list of *, values or ranges,
+// each with an optional step.
+func parseField(field string, b bounds) (uint64, error) {
+	var set uint64
+
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		low, high := b.min, b.max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if low, err = value(from, b); err != nil {
+				return 0, err
+			}
+
+			high = low
+			if isRange {
+				if high, err = value(to, b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// a step from a value runs to the end of the field
+				high = b.max
+			}
+		}
+
+		if low > high {
+			return 0, fmt.Errorf("the range %q is reversed", item)
+		}
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("the step %q is not a positive number", stepPart)
+			}
+			step = n
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+// value parses a value of a field, either a number or a name.
+func value(s string, b bounds) (int, error) {
+	for i, name := range b.names {
+		if strings.EqualFold(s, name) {
+			return b.min + i, nil
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < b.min || n > b.max {
+		return 0, fmt.Errorf("the value %q is not between %d and %d", s, b.min, b.max)
+	}
+
+	return n, nil
+}
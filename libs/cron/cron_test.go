@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	// a wednesday
+	from := time.Date(2024, time.January, 31, 10, 17, 42, 0, time.UTC)
+
+	for spec, want := range map[string]time.Time{
+		"* * * * *":        time.Date(2024, time.January, 31, 10, 18, 0, 0, time.UTC),
+		"*/15 * * * *":     time.Date(2024, time.January, 31, 10, 30, 0, 0, time.UTC),
+		"5 10 * * *":       time.Date(2024, time.February, 1, 10, 5, 0, 0, time.UTC),
+		"0 9-17/4 * * *":   time.Date(2024, time.January, 31, 13, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":       time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 * * sun":      time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":        time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * mon":      time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"30 4 1,15 mar *":  time.Date(2024, time.March, 1, 4, 30, 0, 0, time.UTC),
+		"@hourly":          time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC),
+		"@yearly":          time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"@every 10m":       time.Date(2024, time.January, 31, 10, 20, 0, 0, time.UTC),
+		"0 0 31 dec fri":   time.Date(2024, time.December, 6, 0, 0, 0, 0, time.UTC),
+		"0 0 30 2 *":       {},
+		"59 23 31 12 *":    time.Date(2024, time.December, 31, 23, 59, 0, 0, time.UTC),
+		" 0   12 * * 1-5 ": time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC),
+	} {
+		schedule, err := Parse(spec)
+		if assert.NoError(t, err, spec) {
+			assert.Equal(t, want, schedule.Next(from), spec)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every 1ms",
+		"@every soon",
+		"@fortnightly",
+	} {
+		_, err := Parse(spec)
+		assert.ErrorIs(t, err, ErrSpec, spec)
+	}
+}
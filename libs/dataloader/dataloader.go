@@ -0,0 +1,210 @@
+// Package dataloader batches and caches the loads of the resources by
+// key, made concurrently while resolving a request, so that a list of
+// N items referencing the same resources does not load them N times.
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Fetch loads the resources of keys, returning them in the order of the
+// keys. errs is nil when every key is loaded, otherwise it holds the
+// error of each key, nil for the keys loaded.
+type Fetch[K comparable, V any] func(ctx context.Context, keys []K) (values []V, errs []error)
+
+// Loader collects the keys loaded within wait of the first one into a
+// batch fetched at once, and keeps the result of every key. A loader
+// lives as long as a request, its results are never refreshed.
+type Loader[K comparable, V any] struct {
+	ctx      context.Context
+	fetch    Fetch[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	batch   *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys       []K
+	results    []*result[V]
+	dispatched bool
+}
+
+// New creates a loader fetching batches of up to maxBatch keys, after
+// waiting wait for the keys loaded along the first one. The batches are
+// fetched with ctx, the context of the request rather than the one of
+// the caller that happened to start the batch, as their results are
+// shared by every caller.
+func New[K comparable, V any](ctx context.Context, fetch Fetch[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  make(map[K]*result[V]),
+	}
+}
+
+// Load returns the resource of key, fetching it in the next batch
+// unless it has already been loaded. ctx only bounds the wait of the
+// caller, the fetch goes on for the other callers of the batch.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+
+		if l.batch == nil {
+			b := &batch[K, V]{}
+			l.batch = b
+			time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		}
+
+		l.batch.keys = append(l.batch.keys, key)
+		l.batch.results = append(l.batch.results, r)
+
+		if len(l.batch.keys) >= l.maxBatch {
+			b := l.batch
+			l.batch = nil
+			go l.dispatch(b)
+		}
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// dispatch fetches the batch b once, as soon as it is full or its wait
+// is over. A panic of the fetch fails every key of the batch.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	values, errs := l.call(l.ctx, b.keys)
+
+	for i, r := range b.results {
+		switch {
+		case i < len(errs) && errs[i] != nil:
+			r.err = errs[i]
+		case i < len(values):
+			r.value = values[i]
+		default:
+			r.err = fmt.Errorf("dataloader: no value fetched for the key %v", b.keys[i])
+		}
+
+		close(r.done)
+	}
+}
+
+func (l *Loader[K, V]) call(ctx context.Context, keys []K) (values []V, errs []error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err := fmt.Errorf("dataloader: the fetch panicked: %v", p)
+
+			values, errs = nil, make([]error, len(keys))
+			for i := range errs {
+				errs[i] = err
+			}
+		}
+	}()
+
+	return l.fetch(ctx, keys)
+}
+
+// Batch is the fetch of the resources found together by a single call
+// of find, in any order and leaving out the keys without a resource.
+// The resources are matched to the keys with key, the keys left out
+// fail with notFound.
+func Batch[K comparable, V any](find func(ctx context.Context, keys []K) ([]V, error), key func(V) K, notFound error) Fetch[K, V] {
+	return func(ctx context.Context, keys []K) ([]V, []error) {
+		values := make([]V, len(keys))
+		errs := make([]error, len(keys))
+
+		found, err := find(ctx, keys)
+		if err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+			return values, errs
+		}
+
+		byKey := make(map[K]V, len(found))
+		for _, value := range found {
+			byKey[key(value)] = value
+		}
+
+		for i, k := range keys {
+			value, ok := byKey[k]
+			if !ok {
+				errs[i] = notFound
+				continue
+			}
+			values[i] = value
+		}
+
+		return values, errs
+	}
+}
+
+// PerKey is the fetch of the resources that can only be found one at
+// a time, calling find for the keys of a batch in parallel, at most
+// parallelism at once.
+func PerKey[K comparable, V any](find func(ctx context.Context, key K) (V, error), parallelism int) Fetch[K, V] {
+	return func(ctx context.Context, keys []K) ([]V, []error) {
+		values := make([]V, len(keys))
+		errs := make([]error, len(keys))
+
+		sem := make(chan struct{}, parallelism)
+		var wg sync.WaitGroup
+
+		for i, key := range keys {
+			sem <- struct{}{}
+			wg.Add(1)
+
+			go func(i int, key K) {
+				defer func() {
+					if p := recover(); p != nil {
+						errs[i] = fmt.Errorf("dataloader: the find panicked: %v", p)
+					}
+
+					<-sem
+					wg.Done()
+				}()
+
+				values[i], errs[i] = find(ctx, key)
+			}(i, key)
+		}
+
+		wg.Wait()
+
+		return values, errs
+	}
+}
@@ -0,0 +1,140 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoaderBatches(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+
+	loader := New(context.Background(), func(_ context.Context, keys []int) ([]string, []error) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+
+		values := make([]string, len(keys))
+		errs := make([]error, len(keys))
+		for i, key := range keys {
+			if key < 0 {
+				errs[i] = errors.New("not found")
+				continue
+			}
+			values[i] = string(rune('a' + key))
+		}
+		return values, errs
+	}, 10*time.Millisecond, 3)
+
+	var wg sync.WaitGroup
+	got := make([]string, 8)
+	errs := make([]error, 8)
+
+	for i, key := range []int{0, 1, 0, 2, 3, 1, -1, 4} {
+		wg.Add(1)
+		go func(i, key int) {
+			defer wg.Done()
+			got[i], errs[i] = loader.Load(context.Background(), key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []string{"a", "b", "a", "c", "d", "b", "", "e"}, got)
+	assert.EqualError(t, errs[6], "not found")
+
+	keys := 0
+	for _, batch := range batches {
+		assert.LessOrEqual(t, len(batch), 3)
+		keys += len(batch)
+	}
+	assert.Equal(t, 6, keys, "every key is fetched once")
+	assert.Len(t, batches, 2)
+
+	// the results are kept
+	value, err := loader.Load(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "e", value)
+	assert.Len(t, batches, 2)
+}
+
+func TestLoaderFetchPanics(t *testing.T) {
+	loader := New(context.Background(), func(_ context.Context, keys []int) ([]int, []error) {
+		panic("boom")
+	}, time.Millisecond, 10)
+
+	_, err := loader.Load(context.Background(), 1)
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestPerKey(t *testing.T) {
+	fetch := PerKey(func(_ context.Context, key int) (int, error) {
+		if key == 0 {
+			return 0, errors.New("zero")
+		}
+		return key * 2, nil
+	}, 2)
+
+	values, errs := fetch(context.Background(), []int{1, 0, 3})
+
+	assert.Equal(t, []int{2, 0, 6}, values)
+	assert.NoError(t, errs[0])
+	assert.EqualError(t, errs[1], "zero")
+	assert.NoError(t, errs[2])
+}
+
+func TestLoaderCallerCanceled(t *testing.T) {
+	loader := New(context.Background(), func(ctx context.Context, keys []int) ([]int, []error) {
+		errs := make([]error, len(keys))
+		for i := range errs {
+			errs[i] = ctx.Err()
+		}
+		return keys, errs
+	}, 50*time.Millisecond, 10)
+
+	// the caller starting the batch gives up before it is fetched
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := loader.Load(canceled, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the batch is fetched for the other callers all the same
+	value, err := loader.Load(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+}
+
+func TestBatch(t *testing.T) {
+	notFound := errors.New("not found")
+
+	var calls int
+	fetch := Batch(func(_ context.Context, keys []int) ([]string, error) {
+		calls++
+		return []string{"3", "1"}, nil
+	}, func(value string) int {
+		return int(value[0] - '0')
+	}, notFound)
+
+	values, errs := fetch(context.Background(), []int{1, 2, 3})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"1", "", "3"}, values)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], notFound)
+	assert.NoError(t, errs[2])
+
+	failing := Batch(func(context.Context, []int) ([]string, error) {
+		return nil, errors.New("down")
+	}, func(string) int { return 0 }, notFound)
+
+	_, errs = failing(context.Background(), []int{1, 2})
+	assert.EqualError(t, errs[0], "down")
+	assert.EqualError(t, errs[1], "down")
+}
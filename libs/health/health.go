@@ -0,0 +1,193 @@
+// Package health provides liveness and readiness probes backed by
+// registered dependency checkers.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"hexagony/libs/clog"
+)
+
+const (
+	statusOK           = "ok"
+	statusUnavailable  = "unavailable"
+	statusShuttingDown = "shutting_down"
+)
+
+// Checker checks if a dependency is available.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result is the outcome of a single check. Error is only reported by
+// Check, the readiness endpoint being public.
+type Result struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the readiness payload.
+type Report struct {
+	Status string             `json:"status"`
+	Checks map[string]*Result `json:"checks,omitempty"`
+}
+
+type check struct {
+	name    string
+	checker Checker
+
+	mu     sync.Mutex
+	result *Result
+}
+
+// Health holds the registered checkers and the shutdown state.
+type Health struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu           sync.RWMutex
+	checks       []*check
+	shuttingDown atomic.Bool
+}
+
+// New creates a Health. Each check is bounded by timeout and its
+// result is reused for cacheTTL before running it again.
+func New(timeout, cacheTTL time.Duration) *Health {
+	return &Health{timeout: timeout, cacheTTL: cacheTTL}
+}
+
+// Register adds a dependency checker under the given name.
+func (h *Health) Register(name string, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks = append(h.checks, &check{name: name, checker: checker})
+}
+
+// Shutdown marks the application as not ready. It is meant
+// to be called as soon as the graceful shutdown starts.
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// Check runs every registered checker concurrently and
+// returns the aggregated report.
+func (h *Health) Check(ctx context.Context) *Report {
+	if h.shuttingDown.Load() {
+		return &Report{Status: statusShuttingDown}
+	}
+
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	report := &Report{Status: statusOK, Checks: make(map[string]*Result, len(checks))}
+
+	results := make([]*Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != statusOK {
+			report.Status = statusUnavailable
+		}
+	}
+
+	return report
+}
+
+// run returns the cached result of c or runs it again if it is stale.
+// The check is not canceled with ctx, its result being shared with the
+// other callers until it is stale.
+func (h *Health) run(ctx context.Context, c *check) *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.result != nil && time.Since(c.result.CheckedAt) < h.cacheTTL {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+
+	result := &Result{
+		Status:    statusOK,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+
+	if err != nil {
+		result.Status = statusUnavailable
+		result.Error = err.Error()
+
+		clog.FromContext(ctx).Warn().Ctx(ctx).Err(err).Str("check", c.name).Msg("health check failed")
+	}
+
+	c.result = result
+
+	return result
+}
+
+// Liveness reports that the process is alive.
+func (h *Health) Liveness(w http.ResponseWriter, r *http.Request) {
+	write(w, http.StatusOK, &Report{Status: statusOK})
+}
+
+// Readiness reports if the application can serve traffic, along
+// with the status of each dependency check. The errors of the checks
+// are logged instead of being reported.
+func (h *Health) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.Check(r.Context())
+
+	code := http.StatusOK
+	if report.Status != statusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	public := &Report{Status: report.Status}
+	if report.Checks != nil {
+		public.Checks = make(map[string]*Result, len(report.Checks))
+		for name, result := range report.Checks {
+			stripped := *result
+			stripped.Error = ""
+			public.Checks[name] = &stripped
+		}
+	}
+
+	write(w, code, public)
+}
+
+func write(w http.ResponseWriter, code int, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(report)
+}
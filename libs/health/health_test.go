@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	h := New(time.Second, time.Minute)
+	h.Register("postgres", CheckerFunc(func(context.Context) error { return nil }))
+
+	rec := httptest.NewRecorder()
+	h.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, statusOK, report.Status)
+	assert.Equal(t, statusOK, report.Checks["postgres"].Status)
+}
+
+func TestReadinessFail(t *testing.T) {
+	h := New(time.Second, time.Minute)
+	h.Register("postgres", CheckerFunc(func(context.Context) error { return nil }))
+	h.Register("storage", CheckerFunc(func(context.Context) error { return errors.New("unreachable") }))
+
+	rec := httptest.NewRecorder()
+	h.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, statusOK, report.Checks["postgres"].Status)
+	assert.Equal(t, statusUnavailable, report.Checks["storage"].Status)
+	assert.Empty(t, report.Checks["storage"].Error) // the errors are not public
+	assert.Equal(t, "unreachable", h.Check(context.Background()).Checks["storage"].Error)
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	h := New(10*time.Millisecond, time.Minute)
+	h.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	report := h.Check(context.Background())
+
+	assert.Equal(t, statusUnavailable, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestReadinessCanceledRequest(t *testing.T) {
+	h := New(time.Second, time.Minute)
+	h.Register("postgres", CheckerFunc(func(ctx context.Context) error {
+		return ctx.Err()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := h.Check(ctx)
+
+	assert.Equal(t, statusOK, report.Status)
+	assert.Equal(t, statusOK, h.Check(context.Background()).Checks["postgres"].Status)
+}
+
+func TestReadinessCache(t *testing.T) {
+	var calls atomic.Int32
+
+	h := New(time.Second, time.Minute)
+	h.Register("postgres", CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	h.Check(context.Background())
+	h.Check(context.Background())
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestReadinessShutdown(t *testing.T) {
+	h := New(time.Second, time.Minute)
+	h.Shutdown()
+
+	rec := httptest.NewRecorder()
+	h.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.Liveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
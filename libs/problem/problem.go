@@ -0,0 +1,60 @@
+// Package problem writes RFC 7807 problem details responses.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// typePrefix is prepended to the problem code to build its type URI.
+const typePrefix = "urn:hexagony:problem:"
+
+// Problem represents a problem details object. Code, RequestID and
+// InvalidParams are extension members.
+type Problem struct {
+	Type          string          `json:"type"`
+	Title         string          `json:"title"`
+	Status        int             `json:"status"`
+	Detail        string          `json:"detail,omitempty"`
+	Instance      string          `json:"instance,omitempty"`
+	Code          string          `json:"code,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	InvalidParams []*InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam describes why a single request field is invalid.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// New creates a problem for the given status, stable code and detail.
+// The type is derived from the code, or is about:blank when there is none.
+func New(status int, code, detail string) *Problem {
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+
+	if code != "" {
+		p.Type = typePrefix + code
+	}
+
+	return p
+}
+
+// Write sends p as the response.
+func Write(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		return
+	}
+}
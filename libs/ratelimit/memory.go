@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the least time between two removals
+// of every past TAT from a memory store.
+const sweepInterval = time.Minute
+
+type memoryStore struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates a store kept in memory. The limits
+// are not shared between replicas and are reset on restart.
+func NewMemoryStore() Store {
+	return &memoryStore{tats: make(map[string]time.Time), now: time.Now}
+}
+
+func (m *memoryStore) Get(_ context.Context, key string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.tats[key], nil
+}
+
+func (m *memoryStore) CompareAndSwap(_ context.Context, key string, old, next time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now := m.now(); now.Sub(m.lastSweep) >= sweepInterval {
+		for k, tat := range m.tats {
+			if tat.Before(now) {
+				delete(m.tats, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	if !m.tats[key].Equal(old) {
+		return false, nil
+	}
+
+	m.tats[key] = next
+	return true, nil
+}
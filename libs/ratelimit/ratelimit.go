@@ -0,0 +1,199 @@
+// Package ratelimit limits the rate of requests with the generic cell
+// rate algorithm (GCRA), a token bucket that only stores one time per key.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys a policy can count the requests by.
+const (
+	KeyIP   = "ip"
+	KeyUser = "user"
+)
+
+// maxAttempts bounds the retries of a limit contended by other requests.
+const maxAttempts = 5
+
+// ErrContended is returned when the state of a key kept
+// changing under the limiter until it gave up.
+var ErrContended = errors.New("ratelimit: too much contention on the key")
+
+// Policy allows Rate requests per Period, up to Burst of them at once,
+// counted by Key. The zero Policy allows every request.
+//
+// It is written as "<rate>/<period>[,burst=<n>][,key=ip|user]",
+// e.g. "100/1m,burst=20,key=user". The burst defaults to the rate and
+// the key to ip, "off" or an empty string disable the limit.
+type Policy struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+	Key    string
+}
+
+// ParsePolicy parses the text form of a policy.
+func ParsePolicy(s string) (Policy, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return Policy{}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	p := Policy{Key: KeyIP}
+
+	rate, period, ok := strings.Cut(parts[0], "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("invalid rate limit %q, want <rate>/<period>", s)
+	}
+
+	var err error
+	if p.Rate, err = strconv.Atoi(strings.TrimSpace(rate)); err != nil || p.Rate <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q, the rate must be a positive integer", s)
+	}
+	if p.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || p.Period <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q, the period must be a positive duration", s)
+	}
+	p.Burst = p.Rate
+
+	for _, option := range parts[1:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		switch name {
+		case "burst":
+			if p.Burst, err = strconv.Atoi(value); err != nil || p.Burst <= 0 {
+				return Policy{}, fmt.Errorf("invalid rate limit %q, the burst must be a positive integer", s)
+			}
+		case "key":
+			if value != KeyIP && value != KeyUser {
+				return Policy{}, fmt.Errorf("invalid rate limit %q, the key must be ip or user", s)
+			}
+			p.Key = value
+		default:
+			return Policy{}, fmt.Errorf("invalid rate limit %q, unknown option %q", s, name)
+		}
+	}
+
+	return p, nil
+}
+
+// UnmarshalText parses the text form of the policy, e.g. from the configuration.
+func (p *Policy) UnmarshalText(text []byte) error {
+	parsed, err := ParsePolicy(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = parsed
+	return nil
+}
+
+func (p Policy) String() string {
+	if !p.Enabled() {
+		return "off"
+	}
+
+	return fmt.Sprintf("%d/%s,burst=%d,key=%s", p.Rate, p.Period, p.Burst, p.Key)
+}
+
+// Enabled reports whether the policy limits the requests.
+func (p Policy) Enabled() bool {
+	return p.Rate > 0
+}
+
+// interval is the time a request takes to be replenished.
+func (p Policy) interval() time.Duration {
+	return p.Period / time.Duration(p.Rate)
+}
+
+// Store keeps the theoretical arrival time (TAT) of every key, the
+// time at which its bucket is full again. A TAT in the past is the
+// same as no TAT at all, the store may forget it.
+type Store interface {
+	// Get returns the TAT of key, the zero time if there is none.
+	Get(ctx context.Context, key string) (time.Time, error)
+
+	// CompareAndSwap sets the TAT of key to next if it is still old,
+	// the zero time meaning there is none, and reports whether it did.
+	CompareAndSwap(ctx context.Context, key string, old, next time.Time) (bool, error)
+}
+
+// Result is the decision on a request and the state of its bucket.
+type Result struct {
+	Allowed bool
+
+	// Limit is the number of requests allowed at once.
+	Limit int
+
+	// Remaining is the number of requests allowed right now.
+	Remaining int
+
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+
+	// RetryAfter is the time until a denied request would be allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter applies the policies to the keys kept in a store.
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+// New creates a limiter backed by store.
+func New(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// Allow counts a request for key against p.
+func (l *Limiter) Allow(ctx context.Context, key string, p Policy) (Result, error) {
+	interval := p.interval()
+	tolerance := interval * time.Duration(p.Burst)
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		now := l.now()
+
+		stored, err := l.store.Get(ctx, key)
+		if err != nil {
+			return Result{}, err
+		}
+
+		tat := stored
+		if tat.Before(now) {
+			tat = now
+		}
+
+		next := tat.Add(interval)
+		allowAt := next.Add(-tolerance)
+
+		if now.Before(allowAt) {
+			return Result{
+				Limit:      p.Burst,
+				Reset:      tat.Sub(now),
+				RetryAfter: allowAt.Sub(now),
+			}, nil
+		}
+
+		swapped, err := l.store.CompareAndSwap(ctx, key, stored, next)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if swapped {
+			return Result{
+				Allowed:   true,
+				Limit:     p.Burst,
+				Remaining: int(now.Sub(allowAt) / interval),
+				Reset:     next.Sub(now),
+			}, nil
+		}
+	}
+
+	return Result{}, ErrContended
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("100/1m,burst=20,key=user")
+	assert.NoError(t, err)
+	assert.Equal(t, Policy{Rate: 100, Period: time.Minute, Burst: 20, Key: KeyUser}, p)
+	assert.Equal(t, "100/1m0s,burst=20,key=user", p.String())
+
+	p, err = ParsePolicy("10/1s")
+	assert.NoError(t, err)
+	assert.Equal(t, Policy{Rate: 10, Period: time.Second, Burst: 10, Key: KeyIP}, p)
+
+	p, err = ParsePolicy("off")
+	assert.NoError(t, err)
+	assert.False(t, p.Enabled())
+
+	for _, invalid := range []string{"10", "0/1s", "10/soon", "10/1s,burst=0", "10/1s,key=email", "10/1s,key=api_key", "10/1s,size=3"} {
+		_, err := ParsePolicy(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := New(NewMemoryStore())
+	limiter.now = func() time.Time { return now }
+
+	// one request every 100ms, up to 3 at once
+	policy := Policy{Rate: 10, Period: time.Second, Burst: 3, Key: KeyIP}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := limiter.Allow(context.TODO(), "ip:1", policy)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := limiter.Allow(context.TODO(), "ip:1", policy)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 100*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, 300*time.Millisecond, result.Reset)
+
+	// the other keys have their own bucket
+	result, err = limiter.Allow(context.TODO(), "ip:2", policy)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(100 * time.Millisecond)
+
+	result, err = limiter.Allow(context.TODO(), "ip:1", policy)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	now = now.Add(time.Second)
+
+	result, err = limiter.Allow(context.TODO(), "ip:1", policy)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Remaining)
+}
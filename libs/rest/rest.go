@@ -11,16 +11,6 @@ type Message struct {
 	Status  int    `json:"status,omitempty"`
 }
 
-// DecodeError returns unsuccessful JSON error message.
-func DecodeError(w http.ResponseWriter, r *http.Request, err error, httpCode int) {
-	w.WriteHeader(httpCode)
-
-	errorMessage := &Message{err.Error(), httpCode}
-	if err := json.NewEncoder(w).Encode(errorMessage); err != nil {
-		return
-	}
-}
-
 // JSON returns a successful JSON message.
 func JSON(w http.ResponseWriter, httpCode int, dest interface{}) {
 	w.WriteHeader(httpCode)
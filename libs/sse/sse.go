@@ -0,0 +1,63 @@
+// Package sse writes the Server-Sent Events wire format, as specified
+// by the HTML standard (text/event-stream).
+package sse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of the event streams.
+const ContentType = "text/event-stream"
+
+// LastEventIDHeader is the header a reconnecting client sends
+// with the ID of the last event it received.
+const LastEventIDHeader = "Last-Event-ID"
+
+// Event is a message of a stream. The empty fields are left out,
+// the lines of Data are sent as separate data fields. An event
+// without Data only sets the ID or the retry of the client.
+type Event struct {
+	ID    string
+	Event string
+	Data  []byte
+	Retry time.Duration
+}
+
+// WriteTo writes the event, ended by a blank line.
+func (e *Event) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	if e.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", oneLine(e.ID))
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", oneLine(e.Event))
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	if e.Data != nil {
+		for _, line := range bytes.Split(bytes.ReplaceAll(e.Data, []byte("\r\n"), []byte("\n")), []byte("\n")) {
+			fmt.Fprintf(&buf, "data: %s\n", line)
+		}
+	}
+	buf.WriteByte('\n')
+
+	return buf.WriteTo(w)
+}
+
+// Comment writes a comment, ignored by the clients, which keeps
+// the idle connections from being closed by the proxies.
+func Comment(w io.Writer, text string) error {
+	_, err := fmt.Fprintf(w, ": %s\n\n", oneLine(text))
+	return err
+}
+
+// oneLine drops the line breaks of a field, which would end it.
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,42 @@
+package sse
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+
+	event := Event{ID: "42", Event: "album.created", Data: []byte("{\"a\":1}\n{\"b\":2}"), Retry: 3 * time.Second}
+
+	_, err := event.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "id: 42\nevent: album.created\nretry: 3000\ndata: {\"a\":1}\ndata: {\"b\":2}\n\n", buf.String())
+
+	// the line breaks cannot end a field early
+	buf.Reset()
+	event = Event{ID: "1\nevent: forged", Data: []byte("x")}
+
+	_, err = event.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "id: 1event: forged\ndata: x\n\n", buf.String())
+
+	// without data the client only updates its retry
+	buf.Reset()
+	event = Event{Retry: time.Second}
+
+	_, err = event.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "retry: 1000\n\n", buf.String())
+}
+
+func TestComment(t *testing.T) {
+	var buf bytes.Buffer
+
+	assert.NoError(t, Comment(&buf, "ping"))
+	assert.Equal(t, ": ping\n\n", buf.String())
+}
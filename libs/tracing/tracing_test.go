@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hexagony/libs/clog"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+// restoreGlobals puts back the tracer provider and the propagator
+// replaced by Setup once the test is over.
+func restoreGlobals(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func TestSetupNoneExporter(t *testing.T) {
+	restoreGlobals(t)
+
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = log.Output(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+
+	shutdown, err := Setup(context.Background(), Options{ServiceName: "test", Exporter: ExporterNone, SampleRatio: 1})
+	assert.NoError(t, err)
+	defer shutdown(context.Background())
+
+	ctx, span := Start(context.Background(), "use case")
+	defer span.End()
+
+	assert.True(t, span.SpanContext().IsValid())
+
+	clog.InfoContext(ctx, "traced")
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, span.SpanContext().TraceID().String(), entry["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), entry["span_id"])
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	restoreGlobals(t)
+
+	file := filepath.Join(t.TempDir(), "spans.json")
+
+	shutdown, err := Setup(context.Background(), Options{ServiceName: "test", Exporter: ExporterFile, File: file, SampleRatio: 1})
+	assert.NoError(t, err)
+
+	_, span := Start(context.Background(), "exported")
+	span.End()
+
+	assert.NoError(t, shutdown(context.Background()))
+
+	spans, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(spans), `"Name":"exported"`)
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	restoreGlobals(t)
+
+	_, err := Setup(context.Background(), Options{Exporter: "zipkin"})
+
+	assert.EqualError(t, err, `unknown tracing exporter "zipkin"`)
+}
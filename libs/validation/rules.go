@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// builtinRules are the rules every Validator knows about.
+func builtinRules() []Rule {
+	return []Rule{
+		{
+			Tag:  "barcode",
+			Func: isBarcode,
+			Messages: map[string]string{
+				"en":    "{0} must be a valid EAN-13 or UPC-A barcode",
+				"pt_BR": "{0} deve ser um código de barras EAN-13 ou UPC-A válido",
+				"pl":    "{0} musi być prawidłowym kodem kreskowym EAN-13 lub UPC-A",
+			},
+		},
+	}
+}
+
+// isBarcode checks that the field is an EAN-13 or UPC-A
+// barcode with a valid check digit.
+func isBarcode(fl validator.FieldLevel) bool {
+	code := fl.Field().String()
+	if len(code) != 12 && len(code) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		digit := int(c - '0')
+
+		// Weights alternate 3 and 1, starting from the right
+		// with the digit next to the check digit.
+		if (len(code)-1-i)%2 == 1 {
+			digit *= 3
+		}
+
+		sum += digit
+	}
+
+	return sum%10 == 0
+}
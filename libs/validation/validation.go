@@ -2,83 +2,216 @@ package validation
 
 import (
 	"context"
-	"encoding/json"
-	"net/http"
+	"errors"
+	"fmt"
+	"hexagony/libs/problem"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/pl"
+	"github.com/go-playground/locales/pt_BR"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	plTranslations "github.com/go-playground/validator/v10/translations/pl"
+	ptBRTranslations "github.com/go-playground/validator/v10/translations/pt_BR"
 )
 
 // Validator is an interface for validation purposes.
 type Validator interface {
 	BindStruct(ctx context.Context, data interface{}) error
-	BindField(ctx context.Context, data interface{}, tag string) error
-	DecodeError(w http.ResponseWriter, err error)
+	BindField(ctx context.Context, name string, data interface{}, tag string) error
+	InvalidParams(err error, acceptLanguage string) []*problem.InvalidParam
 }
 
-// message is a struct for validation error messages.
-type message struct {
-	Message string `json:"message,omitempty"`
-	Status  int    `json:"status,omitempty"`
+// Rule is a custom validation tag along with its message in every
+// supported locale. The message may use {0} for the field name.
+type Rule struct {
+	Tag      string
+	Func     validator.Func
+	Messages map[string]string
 }
 
-// errors type is a struct for multiple error messages.
-type errors struct {
-	Errors []*message `json:"errors"`
+// fallbackLocale is used when none of the requested locales is supported.
+const fallbackLocale = "en"
+
+// registerFuncs registers the default translations of each supported locale.
+var registerFuncs = map[string]func(*validator.Validate, ut.Translator) error{
+	"en":    enTranslations.RegisterDefaultTranslations,
+	"pt_BR": ptBRTranslations.RegisterDefaultTranslations,
+	"pl":    plTranslations.RegisterDefaultTranslations,
 }
 
-var (
+// message is the Validator implementation. It is built once
+// and is safe for concurrent use.
+type message struct {
 	uni      *ut.UniversalTranslator
 	validate *validator.Validate
-	trans    ut.Translator
-)
+}
 
-// New creates a new Validator.
-func New() Validator {
-	en := en.New()
-	uni = ut.New(en, en)
+// New creates a new Validator with the built-in rules
+// and the given custom rules.
+func New(rules ...Rule) (Validator, error) {
+	fallback := en.New()
+	uni := ut.New(fallback, fallback, pt_BR.New(), pl.New())
 
-	trans, _ = uni.GetTranslator("en")
+	validate := validator.New(validator.WithRequiredStructEnabled())
 
-	validate = validator.New()
-	if err := enTranslations.RegisterDefaultTranslations(validate, trans); err != nil {
-		return nil
+	// Report the JSON names of the fields instead of the Go ones.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	for locale, register := range registerFuncs {
+		trans, _ := uni.GetTranslator(locale)
+		if err := register(validate, trans); err != nil {
+			return nil, fmt.Errorf("failed to register the %s translations: %w", locale, err)
+		}
 	}
 
-	return message{}
+	for _, rule := range append(builtinRules(), rules...) {
+		if err := register(uni, validate, rule); err != nil {
+			return nil, err
+		}
+	}
+
+	return &message{uni: uni, validate: validate}, nil
+}
+
+func register(uni *ut.UniversalTranslator, validate *validator.Validate, rule Rule) error {
+	if err := validate.RegisterValidation(rule.Tag, rule.Func); err != nil {
+		return fmt.Errorf("failed to register the %s rule: %w", rule.Tag, err)
+	}
+
+	for locale := range registerFuncs {
+		text, ok := rule.Messages[locale]
+		if !ok {
+			text = rule.Messages[fallbackLocale]
+		}
+
+		trans, _ := uni.GetTranslator(locale)
+
+		err := validate.RegisterTranslation(rule.Tag, trans,
+			func(ut ut.Translator) error {
+				return ut.Add(rule.Tag, text, true)
+			},
+			func(ut ut.Translator, fe validator.FieldError) string {
+				t, _ := ut.T(rule.Tag, fe.Field())
+				return t
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register the %s rule message: %w", rule.Tag, err)
+		}
+	}
+
+	return nil
 }
 
 // BindStruct checks if the given struct is valid.
-func (v message) BindStruct(ctx context.Context, data interface{}) error {
-	if err := validate.StructCtx(ctx, data); err != nil {
+func (v *message) BindStruct(ctx context.Context, data interface{}) error {
+	if err := v.validate.StructCtx(ctx, data); err != nil {
 		return err
 	}
 	return nil
 }
 
-// BindField checks if the given field is valid.
-func (v message) BindField(ctx context.Context, data interface{}, tag string) error {
-	if err := validate.VarCtx(ctx, data, tag); err != nil {
+// BindField checks if the given field is valid. The name is
+// used to report the field, e.g. the name of a URL parameter.
+func (v *message) BindField(ctx context.Context, name string, data interface{}, tag string) error {
+	// Validating a one field struct, instead of the bare value,
+	// gives the errors a field name to be reported with.
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Field",
+		Type: reflect.TypeOf(data),
+		Tag:  reflect.StructTag(fmt.Sprintf("json:%q validate:%q", name, tag)),
+	}})
+
+	value := reflect.New(typ).Elem()
+	value.Field(0).Set(reflect.ValueOf(data))
+
+	if err := v.validate.StructCtx(ctx, value.Interface()); err != nil {
 		return err
 	}
 	return nil
 }
 
-// DecodeError returns validation error messages.
-func (v message) DecodeError(w http.ResponseWriter, err error) {
-	w.WriteHeader(http.StatusBadRequest)
-	payload := &errors{}
+// InvalidParams translates the validation errors into field-level
+// problem details, in the language preferred by the Accept-Language
+// header value.
+func (v *message) InvalidParams(err error, acceptLanguage string) []*problem.InvalidParam {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []*problem.InvalidParam{{Reason: err.Error()}}
+	}
+
+	trans, _ := v.uni.FindTranslator(locales(acceptLanguage)...)
+
+	params := make([]*problem.InvalidParam, 0, len(validationErrors))
+	for _, err := range validationErrors {
+		params = append(params, &problem.InvalidParam{
+			Name:   fieldPath(err),
+			Reason: err.Translate(trans),
+		})
+	}
+
+	return params
+}
+
+// fieldPath returns the JSON path of the field, without the
+// name of the top level struct, e.g. "tracks[0].name".
+func fieldPath(err validator.FieldError) string {
+	namespace := err.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return err.Field()
+}
 
-	for _, err := range err.(validator.ValidationErrors) {
-		payload.Errors = append(payload.Errors, &message{Message: err.Translate(trans)})
+// locales parses an Accept-Language header value and returns the
+// locales ordered by preference, e.g. "pt-BR,pt;q=0.9" gives
+// [pt_BR pt].
+func locales(acceptLanguage string) []string {
+	type weighted struct {
+		locale string
+		q      float64
 	}
 
-	if err := json.NewEncoder(w).Encode(payload); err != nil {
-		if _, err := w.Write([]byte("could not encode the payload")); err != nil {
-			return
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if fields[0] == "" || fields[0] == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
 		}
-		return
+
+		tags = append(tags, weighted{strings.ReplaceAll(fields[0], "-", "_"), q})
 	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag.locale)
+	}
+
+	return result
 }
@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type album struct {
+	Name    string `json:"name" validate:"required"`
+	Barcode string `json:"barcode,omitempty" validate:"omitempty,barcode"`
+}
+
+func TestInvalidParamsLocales(t *testing.T) {
+	v, err := New()
+	assert.NoError(t, err)
+
+	err = v.BindStruct(context.Background(), album{})
+	assert.Error(t, err)
+
+	tests := map[string]string{
+		"":                  "name is a required field",
+		"pl":                "name jest wymaganym polem",
+		"de, pt-BR;q=0.5":   "name é um campo obrigatório",
+		"pl;q=0.1, en;q=.9": "name is a required field",
+	}
+
+	for header, reason := range tests {
+		params := v.InvalidParams(err, header)
+		if assert.Len(t, params, 1, header) {
+			assert.Equal(t, "name", params[0].Name, header)
+			assert.Equal(t, reason, params[0].Reason, header)
+		}
+	}
+}
+
+func TestBarcode(t *testing.T) {
+	v, err := New()
+	assert.NoError(t, err)
+
+	valid := []string{"4006381333931", "036000291452"}
+	for _, code := range valid {
+		assert.NoError(t, v.BindStruct(context.Background(), album{Name: "x", Barcode: code}), code)
+	}
+
+	invalid := []string{"4006381333932", "03600029145", "03600029145a"}
+	for _, code := range invalid {
+		assert.Error(t, v.BindStruct(context.Background(), album{Name: "x", Barcode: code}), code)
+	}
+}
+
+func TestBindField(t *testing.T) {
+	v, err := New()
+	assert.NoError(t, err)
+
+	err = v.BindField(context.Background(), "uuid", "123", "uuid")
+	assert.Error(t, err)
+
+	params := v.InvalidParams(err, "en")
+	if assert.Len(t, params, 1) {
+		assert.Equal(t, "uuid", params[0].Name)
+	}
+
+	assert.NoError(t, v.BindField(context.Background(), "uuid", "7d31461a-6ed5-425e-96fe-fa98e56d6828", "uuid"))
+}
+
+func TestConcurrentUse(t *testing.T) {
+	v, err := New()
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := v.BindStruct(context.Background(), album{})
+			v.InvalidParams(err, "pt-BR")
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,178 @@
+// Package webhook sends the signed webhook requests. The signature is
+// the HMAC-SHA256, keyed with the secret of the webhook, of the Unix
+// timestamp and the body joined by a dot, so that a receiver can
+// reject both the forged and the replayed requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers of the webhook requests.
+const (
+	EventHeader     = "X-Hexagony-Event"
+	DeliveryHeader  = "X-Hexagony-Delivery"
+	TimestampHeader = "X-Hexagony-Timestamp"
+	SignatureHeader = "X-Hexagony-Signature"
+)
+
+// signaturePrefix names the algorithm of the signature header.
+const signaturePrefix = "sha256="
+
+var (
+	ErrSignature = errors.New("webhook: the signature does not match")
+	ErrTimestamp = errors.New("webhook: the timestamp is missing or too old")
+	ErrTarget    = errors.New("webhook: the target address is not public")
+)
+
+// NewSecret returns a random secret to sign the requests of a webhook.
+func NewSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(key), nil
+}
+
+// Sign returns the signature header of body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and the timestamp headers of a request
+// with body, the timestamp must be at most tolerance away from now.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return ErrTimestamp
+	}
+
+	timestamp := time.Unix(unix, 0)
+	if diff := now.Sub(timestamp); diff > tolerance || diff < -tolerance {
+		return ErrTimestamp
+	}
+
+	signature := header.Get(SignatureHeader)
+	if !strings.HasPrefix(signature, signaturePrefix) ||
+		!hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrSignature
+	}
+
+	return nil
+}
+
+// Request is a webhook request to send.
+type Request struct {
+	URL      string
+	Secret   string
+	Event    string
+	Delivery string
+	Body     []byte
+}
+
+// Client sends the webhook requests. The redirects are not followed,
+// a receiver must answer with a 2xx status for a request to succeed.
+type Client struct {
+	http      *http.Client
+	userAgent string
+	now       func() time.Time
+}
+
+// NewClient creates a client waiting timeout for each response. Unless
+// allowPrivate is set, the client refuses to connect to the loopback,
+// private and link-local addresses, checked once the host is resolved
+// so that a receiver cannot rebind its name to them.
+func NewClient(timeout time.Duration, allowPrivate bool) *Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = publicOnly
+	}
+
+	// the requests are sent directly, a proxy would be
+	// the only address checked
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Client{
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		userAgent: "Hexagony-Webhook/1.0",
+		now:       time.Now,
+	}
+}
+
+// publicOnly refuses the connections to an address that is not public.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrTarget, ip)
+	}
+
+	return nil
+}
+
+// Send posts the signed request and returns the status of the
+// response. The error is set when the request failed or the status
+// is not a 2xx, in which case the status may still be set.
+func (c *Client) Send(ctx context.Context, request Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	now := c.now()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set(EventHeader, request.Event)
+	req.Header.Set(DeliveryHeader, request.Delivery)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(request.Secret, now, request.Body))
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// the body is drained, up to a limit, for the connection to be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook: unexpected status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
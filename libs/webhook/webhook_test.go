@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1655657589, 0)
+	body := []byte(`{"type":"album.created"}`)
+
+	header := http.Header{}
+	header.Set(TimestampHeader, "1655657589")
+	header.Set(SignatureHeader, Sign("whsec_test", now, body))
+
+	assert.True(t, strings.HasPrefix(header.Get(SignatureHeader), "sha256="))
+	assert.NoError(t, Verify("whsec_test", header, body, 5*time.Minute, now.Add(time.Minute)))
+
+	assert.ErrorIs(t, Verify("whsec_other", header, body, 5*time.Minute, now), ErrSignature)
+	assert.ErrorIs(t, Verify("whsec_test", header, []byte(`{}`), 5*time.Minute, now), ErrSignature)
+	assert.ErrorIs(t, Verify("whsec_test", header, body, 5*time.Minute, now.Add(time.Hour)), ErrTimestamp)
+
+	header.Del(TimestampHeader)
+	assert.ErrorIs(t, Verify("whsec_test", header, body, 5*time.Minute, now), ErrTimestamp)
+}
+
+func TestClientSend(t *testing.T) {
+	secret, err := NewSecret()
+	assert.NoError(t, err)
+
+	status := http.StatusNoContent
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		assert.Equal(t, "album.created", r.Header.Get(EventHeader))
+		assert.Equal(t, "d1", r.Header.Get(DeliveryHeader))
+		assert.NoError(t, Verify(secret, r.Header, body, time.Minute, time.Now()))
+
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	client := NewClient(time.Second, true)
+	request := Request{URL: receiver.URL, Secret: secret, Event: "album.created", Delivery: "d1", Body: []byte(`{}`)}
+
+	code, err := client.Send(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	// the redirects and the other statuses fail
+	for _, status = range []int{http.StatusFound, http.StatusInternalServerError} {
+		code, err = client.Send(context.Background(), request)
+		assert.Error(t, err)
+		assert.Equal(t, status, code)
+	}
+
+	receiver.Close()
+
+	_, err = client.Send(context.Background(), request)
+	assert.Error(t, err)
+}
+
+func TestClientRefusesPrivateTargets(t *testing.T) {
+	var received bool
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer receiver.Close()
+
+	client := NewClient(time.Second, false)
+
+	for _, url := range []string{receiver.URL, "http://localhost:" + receiver.URL[strings.LastIndex(receiver.URL, ":")+1:]} {
+		code, err := client.Send(context.Background(), Request{URL: url, Secret: "whsec_test", Body: []byte(`{}`)})
+		assert.ErrorIs(t, err, ErrTarget, url)
+		assert.Equal(t, 0, code)
+	}
+
+	assert.False(t, received)
+
+	for _, address := range []string{"10.0.0.1:80", "192.168.1.1:443", "169.254.169.254:80", "[::1]:80", "[fe80::1]:80", "[::ffff:127.0.0.1]:80"} {
+		assert.ErrorIs(t, publicOnly("tcp", address, nil), ErrTarget, address)
+	}
+
+	assert.NoError(t, publicOnly("tcp", "93.184.216.34:443", nil))
+}
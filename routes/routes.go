@@ -2,54 +2,186 @@ package routes
 
 import (
 	"hexagony/app/domain"
+	loader "hexagony/app/graphql/loaders"
+	resolver "hexagony/app/graphql/resolvers"
+	graphqlserver "hexagony/app/graphql/server"
+	"hexagony/app/grpc/pb"
+	service "hexagony/app/grpc/services"
 	controller "hexagony/app/http/controllers"
 	"hexagony/app/http/middleware"
+	"hexagony/config"
+	"hexagony/libs/ratelimit"
+	"hexagony/libs/validation"
 
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
 )
 
 type RoutesUseCases struct {
 	domain.AuthUseCase
 	domain.UsersUseCase
 	domain.AlbumsUseCase
+	domain.AuditUseCase
+	domain.WebhooksUseCase
+	domain.JobsUseCase
+
+	// Idempotency stores the responses of the POST requests made with an Idempotency-Key.
+	Idempotency domain.IdempotencyStore
+
+	// RateLimiter applies the rate limits of the route groups.
+	RateLimiter *ratelimit.Limiter
+
+	// AlbumsFeed streams the album events to the clients.
+	AlbumsFeed domain.EventFeed
+
+	// AlbumsPresence tracks the editors of the albums.
+	AlbumsPresence domain.PresenceHub
 }
 
-func authRoutes(c *chi.Mux, auc domain.AuthUseCase) {
-	handler := controller.AuthController{AuthUseCase: auc}
+func authRoutes(c *chi.Mux, auc domain.AuthUseCase, rl *ratelimit.Limiter, v validation.Validator, cfg *config.Config) {
+	handler := controller.AuthController{AuthUseCase: auc, Validator: v}
 
-	c.Post("/auth", handler.Authenticate)
+	c.With(middleware.RateLimitMiddleware(rl, "auth", cfg.RateLimit.Auth)).Post("/auth", handler.Authenticate)
 }
 
-func usersRoutes(c *chi.Mux, as domain.UsersUseCase) {
-	handler := controller.UsersController{UsersUseCase: as}
+func usersRoutes(c *chi.Mux, as domain.UsersUseCase, is domain.IdempotencyStore, rl *ratelimit.Limiter, v validation.Validator, cfg *config.Config) {
+	handler := controller.UsersController{UsersUseCase: as, Validator: v}
 
 	c.Route("/user", func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware)
+		r.Use(middleware.AuthMiddleware(cfg.JWT))
+		r.Use(middleware.RateLimitMiddleware(rl, "users", cfg.RateLimit.Users))
+		r.Use(middleware.PreconditionMiddleware(cfg.Concurrency))
+		r.Use(middleware.IdempotencyMiddleware(is, cfg.Idempotency))
 
 		r.Get("/", handler.FindAll)
+		r.Get("/trash", handler.Trash)
 		r.Get("/{uuid}", handler.FindByID)
 		r.Post("/", handler.Add)
 		r.Put("/{uuid}", handler.Update)
+		r.Patch("/{uuid}", handler.Patch)
 		r.Delete("/{uuid}", handler.Delete)
+		r.Post("/{uuid}/restore", handler.Restore)
 	})
 }
 
-func albumsRoutes(c *chi.Mux, as domain.AlbumsUseCase) {
-	handler := controller.AlbumsController{AlbumsUseCase: as}
+func albumsRoutes(c *chi.Mux, as domain.AlbumsUseCase, af domain.EventFeed, ah domain.PresenceHub, is domain.IdempotencyStore, rl *ratelimit.Limiter, v validation.Validator, cfg *config.Config) {
+	handler := controller.AlbumsController{
+		AlbumsUseCase:  as,
+		Validator:      v,
+		Feed:           af,
+		Stream:         cfg.Stream,
+		Hub:            ah,
+		Presence:       cfg.Presence,
+		AllowedOrigins: cfg.Server.AllowedOrigins,
+		Concurrency:    cfg.Concurrency,
+	}
 
 	c.Route("/album", func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware)
+		r.Use(middleware.AuthMiddleware(cfg.JWT))
+		r.Use(middleware.RateLimitMiddleware(rl, "albums", cfg.RateLimit.Albums))
+		r.Use(middleware.PreconditionMiddleware(cfg.Concurrency))
+		r.Use(middleware.IdempotencyMiddleware(is, cfg.Idempotency))
+
+		r.Get("/", handler.FindAll)
+		r.Get("/trash", handler.Trash)
+		r.Get("/export", handler.Export)
+		r.Get("/events", handler.Events)
+		r.Get("/{uuid}", handler.FindByID)
+		r.Post("/", handler.Add)
+		r.Post("/bulk", handler.Bulk)
+		r.Post("/import", handler.Import)
+		r.Put("/{uuid}", handler.Update)
+		r.Patch("/{uuid}", handler.Patch)
+		r.Delete("/{uuid}", handler.Delete)
+		r.Post("/{uuid}/restore", handler.Restore)
+		r.Get("/{uuid}/revisions", handler.Revisions)
+		r.Post("/{uuid}/revisions/{revision}/restore", handler.RestoreRevision)
+
+		// served by the only instance with the presence enabled
+		if cfg.Presence.Enabled {
+			r.Get("/{uuid}/presence", handler.Join)
+		}
+	})
+}
+
+func graphqlRoutes(c *chi.Mux, as domain.AlbumsUseCase, us domain.UsersUseCase, rl *ratelimit.Limiter, v validation.Validator, cfg *config.Config) {
+	handler := graphqlserver.New(&resolver.Resolver{
+		AlbumsUseCase: as,
+		UsersUseCase:  us,
+		Validator:     v,
+		Concurrency:   cfg.Concurrency,
+	}, cfg.GraphQL)
+
+	c.With(
+		middleware.AuthMiddleware(cfg.JWT),
+		middleware.RateLimitMiddleware(rl, "graphql", cfg.RateLimit.GraphQL),
+		loader.Middleware(as, us),
+	).Handle("/graphql", handler)
+}
+
+func webhooksRoutes(c *chi.Mux, ws domain.WebhooksUseCase, is domain.IdempotencyStore, v validation.Validator, cfg *config.Config) {
+	handler := controller.WebhooksController{WebhooksUseCase: ws, Validator: v}
+
+	c.Route("/webhook", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(cfg.JWT))
+		r.Use(middleware.AdminMiddleware(cfg.Admin))
+		r.Use(middleware.IdempotencyMiddleware(is, cfg.Idempotency))
 
 		r.Get("/", handler.FindAll)
 		r.Get("/{uuid}", handler.FindByID)
 		r.Post("/", handler.Add)
 		r.Put("/{uuid}", handler.Update)
 		r.Delete("/{uuid}", handler.Delete)
+		r.Get("/{uuid}/deliveries", handler.Deliveries)
+		r.Get("/{uuid}/deliveries/{delivery}", handler.Delivery)
+		r.Post("/{uuid}/deliveries/{delivery}/redeliver", handler.Redeliver)
 	})
 }
 
-func Api(c *chi.Mux, r *RoutesUseCases) {
-	authRoutes(c, r.AuthUseCase)
-	usersRoutes(c, r.UsersUseCase)
-	albumsRoutes(c, r.AlbumsUseCase)
+func auditRoutes(c *chi.Mux, as domain.AuditUseCase, v validation.Validator, cfg *config.Config) {
+	handler := controller.AuditController{AuditUseCase: as, Validator: v}
+
+	c.Route("/audit", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(cfg.JWT))
+		r.Use(middleware.AdminMiddleware(cfg.Admin))
+
+		r.Get("/", handler.Find)
+		r.Get("/verify", handler.Verify)
+	})
+}
+
+func jobsRoutes(c *chi.Mux, js domain.JobsUseCase, v validation.Validator, cfg *config.Config) {
+	handler := controller.JobsController{JobsUseCase: js, Validator: v}
+
+	c.Route("/jobs", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(cfg.JWT))
+		r.Use(middleware.AdminMiddleware(cfg.Admin))
+
+		r.Get("/", handler.FindAll)
+		r.Get("/{uuid}", handler.FindByID)
+		r.Post("/{uuid}/retry", handler.Retry)
+		r.Post("/{uuid}/cancel", handler.Cancel)
+	})
+}
+
+func Api(c *chi.Mux, r *RoutesUseCases, v validation.Validator, cfg *config.Config) {
+	authRoutes(c, r.AuthUseCase, r.RateLimiter, v, cfg)
+	usersRoutes(c, r.UsersUseCase, r.Idempotency, r.RateLimiter, v, cfg)
+	albumsRoutes(c, r.AlbumsUseCase, r.AlbumsFeed, r.AlbumsPresence, r.Idempotency, r.RateLimiter, v, cfg)
+	graphqlRoutes(c, r.AlbumsUseCase, r.UsersUseCase, r.RateLimiter, v, cfg)
+	webhooksRoutes(c, r.WebhooksUseCase, r.Idempotency, v, cfg)
+}
+
+// Admin mounts the routes of the admin listener.
+func Admin(c *chi.Mux, r *RoutesUseCases, v validation.Validator, cfg *config.Config) {
+	auditRoutes(c, r.AuditUseCase, v, cfg)
+	jobsRoutes(c, r.JobsUseCase, v, cfg)
+}
+
+// Grpc registers the services of the gRPC API, the counterpart of
+// the auth, users and albums routes.
+func Grpc(s grpc.ServiceRegistrar, r *RoutesUseCases, v validation.Validator, cfg *config.Config) {
+	pb.RegisterAuthServiceServer(s, &service.AuthService{AuthUseCase: r.AuthUseCase, Validator: v})
+	pb.RegisterUsersServiceServer(s, &service.UsersService{UsersUseCase: r.UsersUseCase, Validator: v, Concurrency: cfg.Concurrency})
+	pb.RegisterAlbumsServiceServer(s, &service.AlbumsService{AlbumsUseCase: r.AlbumsUseCase, Validator: v, Concurrency: cfg.Concurrency})
 }